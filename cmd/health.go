@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// healthCheckTimeout is how long the health check waits for the health endpoint to respond
+const healthCheckTimeout = 10 * time.Second
+
+// runHealthCheck queries the health endpoint of the watchtower process running in the container, returning the exit
+// code for the HEALTHCHECK. Without the health endpoint, only the absence of a second watchtower process is checked.
+func runHealthCheck(c *cobra.Command) int {
+	if enabled, _ := c.PersistentFlags().GetBool("http-api-health"); !enabled {
+		return 0
+	}
+
+	client, baseURL, err := getAPIServerConfig(c).LocalClient(healthCheckTimeout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Health check failed:", err)
+		return 1
+	}
+	res, err := client.Get(baseURL + "/v1/health")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Health check failed:", err)
+		return 1
+	}
+	defer res.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 64*1024))
+	fmt.Print(string(body))
+	if res.StatusCode != http.StatusOK {
+		return 1
+	}
+	return 0
+}
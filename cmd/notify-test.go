@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/containrrr/watchtower/internal/flags"
+	"github.com/containrrr/watchtower/pkg/notifications"
+	"github.com/containrrr/watchtower/pkg/notifications/preview/data"
+	t "github.com/containrrr/watchtower/pkg/types"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var notifyTestCommand = NewNotifyTestCommand()
+
+// NewNotifyTestCommand creates the notify test command for watchtower
+func NewNotifyTestCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "notify-test",
+		Short: "Send a test notification to the configured notification services",
+		Run:   runNotifyTest,
+	}
+}
+
+func runNotifyTest(cmd *cobra.Command, args []string) {
+	if err := runNotifyTestE(cmd, args); err != nil {
+		logf("Notification test failed: %v", err)
+		os.Exit(1)
+	}
+}
+
+func runNotifyTestE(cmd *cobra.Command, _ []string) error {
+	f := cmd.Flags()
+	flags.ProcessFlagAliases(f)
+	if err := flags.SetupLogging(f); err != nil {
+		return fmt.Errorf("failed to initialize logging: %v", err)
+	}
+	flags.GetSecretsFromFiles(cmd.Root())
+
+	notifier = notifications.NewNotifier(cmd)
+	if len(notifier.GetURLs()) == 0 {
+		return errors.New("no notification services are configured")
+	}
+
+	entries, report := getTestNotificationData()
+	results := notifications.SendTestNotification(notifier, entries, report)
+
+	failed := 0
+	for _, result := range results {
+		if result.Error != nil {
+			failed++
+			fmt.Printf("FAILED  %v: %v\n", redactURL(result.URL), result.Error)
+		} else {
+			fmt.Printf("OK      %v\n", redactURL(result.URL))
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d notification services failed", failed, len(results))
+	}
+	return nil
+}
+
+// getTestNotificationData returns sample log entries and a sample session report to render the test notification from
+func getTestNotificationData() ([]*log.Entry, t.Report) {
+	preview := data.New()
+	for _, state := range data.StatesFromString("ucef") {
+		preview.AddFromState(state)
+	}
+	for _, level := range data.LevelsFromString("iw") {
+		preview.AddLogEntry(level)
+	}
+
+	entries := make([]*log.Entry, 0, len(preview.Entries)+1)
+	entries = append(entries, &log.Entry{
+		Level:   log.InfoLevel,
+		Message: "This is a test notification from watchtower",
+		Data:    log.Fields{},
+		Time:    time.Now(),
+	})
+	for _, entry := range preview.Entries {
+		level, err := log.ParseLevel(string(entry.Level))
+		if err != nil {
+			level = log.InfoLevel
+		}
+		entries = append(entries, &log.Entry{
+			Level:   level,
+			Message: entry.Message,
+			Data:    entry.Data,
+			Time:    entry.Time,
+		})
+	}
+
+	return entries, preview.Report()
+}
+
+// redacted replaces the parts of the service URLs that can contain secrets
+const redacted = "xxxxx"
+
+// redactURL masks the user info, path segments, query values and fragment of the URL, as notification services keep
+// their secrets in any of them, leaving the scheme, host and query keys to identify the service
+func redactURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return notifications.GetScheme(rawURL) + "://" + redacted
+	}
+	if parsed.User == nil && parsed.Opaque == "" && strings.Trim(parsed.Path, "/") == "" && parsed.RawQuery == "" &&
+		parsed.Fragment == "" {
+		return rawURL
+	}
+	if parsed.Opaque != "" {
+		parsed.Opaque = redacted
+	}
+	if parsed.User != nil {
+		if _, hasPassword := parsed.User.Password(); hasPassword {
+			parsed.User = url.UserPassword(redacted, redacted)
+		} else {
+			parsed.User = url.User(redacted)
+		}
+	}
+
+	segments := strings.Split(parsed.Path, "/")
+	for i, segment := range segments {
+		if segment != "" {
+			segments[i] = redacted
+		}
+	}
+	parsed.Path = strings.Join(segments, "/")
+	parsed.RawPath = ""
+
+	query := parsed.Query()
+	for _, values := range query {
+		for i := range values {
+			values[i] = redacted
+		}
+	}
+	parsed.RawQuery = query.Encode()
+	if parsed.Fragment != "" {
+		parsed.Fragment = redacted
+		parsed.RawFragment = ""
+	}
+	return parsed.String()
+}
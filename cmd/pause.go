@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/containrrr/watchtower/internal/flags"
+	"github.com/containrrr/watchtower/pkg/pause"
+	"github.com/spf13/cobra"
+)
+
+var pauseCommand = NewPauseCommand()
+var resumeCommand = NewResumeCommand()
+
+// NewPauseCommand creates the command for pausing the scheduled updates
+func NewPauseCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pause",
+		Short: "Pause the scheduled updates, until resumed or for a limited time",
+		Run:   runPause,
+	}
+	cmd.Flags().Duration("for", 0, "Resume the scheduled updates automatically after this duration")
+	cmd.Flags().String("reason", "", "The reason for pausing the scheduled updates, included in the logs and status")
+	cmd.Flags().Bool("status", false, "Only show whether the scheduled updates are paused")
+	return cmd
+}
+
+// NewResumeCommand creates the command for resuming the scheduled updates
+func NewResumeCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "resume",
+		Short: "Resume the paused scheduled updates",
+		Run:   runResume,
+	}
+}
+
+func runPause(cmd *cobra.Command, _ []string) {
+	f := cmd.Flags()
+	duration, _ := f.GetDuration("for")
+	reason, _ := f.GetString("reason")
+	statusOnly, _ := f.GetBool("status")
+
+	if duration < 0 {
+		exitWithError(errors.New("the pause duration must be positive"))
+	}
+	var until time.Time
+	if duration > 0 {
+		until = time.Now().Add(duration)
+	}
+
+	store := getCommandPauseStore(cmd)
+	var state pause.State
+	var err error
+	if statusOnly {
+		state, err = store.Status()
+	} else {
+		state, err = store.Pause(reason, until)
+	}
+	if err != nil {
+		exitWithError(err)
+	}
+	printPauseState(state)
+}
+
+func runResume(cmd *cobra.Command, _ []string) {
+	state, err := getCommandPauseStore(cmd).Resume()
+	if err != nil {
+		exitWithError(err)
+	}
+	printPauseState(state)
+}
+
+func getCommandPauseStore(cmd *cobra.Command) *pause.Store {
+	flags.ProcessFlagAliases(cmd.Flags())
+	if err := flags.SetupLogging(cmd.Flags()); err != nil {
+		exitWithError(fmt.Errorf("failed to initialize logging: %v", err))
+	}
+	// The running watchtower only sees the pause state through the state file
+	if stateFile, _ := cmd.Flags().GetString("scheduler-state-file"); stateFile == "" {
+		exitWithError(errors.New("the scheduler state file must be set to pause or resume the scheduled updates"))
+	}
+	return getPauseStore(cmd)
+}
+
+func printPauseState(state pause.State) {
+	if !state.Paused {
+		fmt.Println("Scheduled updates are running")
+		return
+	}
+
+	fmt.Printf("Scheduled updates are paused since %v", state.Since.Format(time.RFC3339))
+	if !state.Until.IsZero() {
+		fmt.Printf(" until %v", state.Until.Format(time.RFC3339))
+	}
+	if state.Reason != "" {
+		fmt.Printf(": %v", state.Reason)
+	}
+	fmt.Println()
+}
+
+func exitWithError(err error) {
+	logf("%v", err)
+	os.Exit(1)
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"crypto"
 	"errors"
 	"math"
 	"net/http"
@@ -15,12 +16,25 @@ import (
 	"github.com/containrrr/watchtower/internal/flags"
 	"github.com/containrrr/watchtower/internal/meta"
 	"github.com/containrrr/watchtower/pkg/api"
+	"github.com/containrrr/watchtower/pkg/api/containers"
+	"github.com/containrrr/watchtower/pkg/api/dashboard"
+	apiEvents "github.com/containrrr/watchtower/pkg/api/events"
+	apiHealth "github.com/containrrr/watchtower/pkg/api/health"
 	apiMetrics "github.com/containrrr/watchtower/pkg/api/metrics"
+	"github.com/containrrr/watchtower/pkg/api/openapi"
+	apiScheduler "github.com/containrrr/watchtower/pkg/api/scheduler"
+	"github.com/containrrr/watchtower/pkg/api/sessions"
 	"github.com/containrrr/watchtower/pkg/api/update"
+	apiWebhook "github.com/containrrr/watchtower/pkg/api/webhook"
 	"github.com/containrrr/watchtower/pkg/container"
 	"github.com/containrrr/watchtower/pkg/filters"
+	"github.com/containrrr/watchtower/pkg/health"
 	"github.com/containrrr/watchtower/pkg/metrics"
 	"github.com/containrrr/watchtower/pkg/notifications"
+	"github.com/containrrr/watchtower/pkg/pause"
+	"github.com/containrrr/watchtower/pkg/registry/helpers"
+	"github.com/containrrr/watchtower/pkg/registry/signature"
+	"github.com/containrrr/watchtower/pkg/session"
 	t "github.com/containrrr/watchtower/pkg/types"
 	"github.com/robfig/cron"
 	log "github.com/sirupsen/logrus"
@@ -35,14 +49,24 @@ var (
 	noRestart         bool
 	noPull            bool
 	monitorOnly       bool
+	checkOnly         bool
 	enableLabel       bool
 	disableContainers []string
 	notifier          t.Notifier
+	containersHandler *containers.Handler
+	sessionsHandler   *sessions.Handler
+	healthMonitor     = health.NewMonitor(0)
+	pauseStore        *pause.Store
 	timeout           time.Duration
 	lifecycleHooks    bool
 	rollingRestart    bool
 	scope             string
 	labelPrecedence   bool
+	verifySignatures  bool
+	signatureKeys     []crypto.PublicKey
+	failureLogLines   int
+	exitCheckDelay    time.Duration
+	reviveStopped     bool
 )
 
 var rootCmd = NewRootCommand()
@@ -72,6 +96,9 @@ func init() {
 // Execute the root func and exit in case of errors
 func Execute() {
 	rootCmd.AddCommand(notifyUpgradeCommand)
+	rootCmd.AddCommand(notifyTestCommand)
+	rootCmd.AddCommand(pauseCommand)
+	rootCmd.AddCommand(resumeCommand)
 	if err := rootCmd.Execute(); err != nil {
 		log.Fatal(err)
 	}
@@ -100,6 +127,11 @@ func PreRun(cmd *cobra.Command, _ []string) {
 	rollingRestart, _ = f.GetBool("rolling-restart")
 	scope, _ = f.GetString("scope")
 	labelPrecedence, _ = f.GetBool("label-take-precedence")
+	checkOnly, _ = f.GetBool("check-only")
+	verifySignatures, _ = f.GetBool("verify-signatures")
+	signatureKeyFiles, _ := f.GetStringSlice("signature-public-key")
+	failureLogLines, _ = f.GetInt("failure-log-lines")
+	exitCheckDelay, _ = f.GetDuration("exit-check-delay")
 
 	if scope != "" {
 		log.Debugf(`Using scope %q`, scope)
@@ -111,12 +143,24 @@ func PreRun(cmd *cobra.Command, _ []string) {
 		log.Fatal(err)
 	}
 
+	// The keys are read once, rather than for every verified image
+	if signatureKeys, err = signature.LoadPublicKeys(signatureKeyFiles); err != nil {
+		log.Fatalf("Failed to load the signature public keys: %v", err)
+	}
+
 	noPull, _ = f.GetBool("no-pull")
 	includeStopped, _ := f.GetBool("include-stopped")
 	includeRestarting, _ := f.GetBool("include-restarting")
-	reviveStopped, _ := f.GetBool("revive-stopped")
+	reviveStopped, _ = f.GetBool("revive-stopped")
 	removeVolumes, _ := f.GetBool("remove-volumes")
 	warnOnHeadPullFailed, _ := f.GetString("warn-on-head-failure")
+	mirrorFallback, _ := f.GetBool("registry-mirror-fallback")
+
+	mirrorPairs, _ := f.GetStringSlice("registry-mirror")
+	registryMirrors, err := helpers.ParseRegistryMirrors(mirrorPairs)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	if monitorOnly && noPull {
 		log.Warn("Using `WATCHTOWER_NO_PULL` and `WATCHTOWER_MONITOR_ONLY` simultaneously might lead to no action being taken at all. If this is intentional, you may safely ignore this message.")
@@ -128,6 +172,8 @@ func PreRun(cmd *cobra.Command, _ []string) {
 		RemoveVolumes:     removeVolumes,
 		IncludeRestarting: includeRestarting,
 		WarnOnHeadFailed:  container.WarningStrategy(warnOnHeadPullFailed),
+		RegistryMirrors:   registryMirrors,
+		MirrorFallback:    mirrorFallback,
 	})
 
 	notifier = notifications.NewNotifier(cmd)
@@ -140,6 +186,12 @@ func Run(c *cobra.Command, names []string) {
 	runOnce, _ := c.PersistentFlags().GetBool("run-once")
 	enableUpdateAPI, _ := c.PersistentFlags().GetBool("http-api-update")
 	enableMetricsAPI, _ := c.PersistentFlags().GetBool("http-api-metrics")
+	enableContainersAPI, _ := c.PersistentFlags().GetBool("http-api-containers")
+	enableEventsAPI, _ := c.PersistentFlags().GetBool("http-api-events")
+	enableSchedulerAPI, _ := c.PersistentFlags().GetBool("http-api-scheduler")
+	enableWebhookAPI, _ := c.PersistentFlags().GetBool("http-api-webhook")
+	enableDashboard, _ := c.PersistentFlags().GetBool("http-api-dashboard")
+	enableHealthAPI, _ := c.PersistentFlags().GetBool("http-api-health")
 	unblockHTTPAPI, _ := c.PersistentFlags().GetBool("http-api-periodic-polls")
 	apiToken, _ := c.PersistentFlags().GetString("http-api-token")
 	healthCheck, _ := c.PersistentFlags().GetBool("health-check")
@@ -150,9 +202,12 @@ func Run(c *cobra.Command, names []string) {
 			time.Sleep(1 * time.Second)
 			log.Fatal("The health check flag should never be passed to the main watchtower container process")
 		}
-		os.Exit(0)
+		os.Exit(runHealthCheck(c))
 	}
 
+	maxSessionDuration, _ := c.PersistentFlags().GetDuration("health-max-session-duration")
+	healthMonitor = health.NewMonitor(maxSessionDuration)
+
 	if rollingRestart && monitorOnly {
 		log.Fatal("Rolling restarts is not compatible with the global monitor only flag")
 	}
@@ -175,18 +230,42 @@ func Run(c *cobra.Command, names []string) {
 		logNotifyExit(err)
 	}
 
+	pauseStore = getPauseStore(c)
+	if paused, state := scheduleIsPaused(); paused {
+		log.WithFields(log.Fields{
+			"reason": state.Reason,
+			"until":  state.Until,
+		}).Warn("Scheduled updates are paused.")
+	}
+
 	// The lock is shared between the scheduler and the HTTP API. It only allows one update to run at a time.
 	updateLock := make(chan bool, 1)
 	updateLock <- true
 
 	httpAPI := api.New(apiToken)
+	httpAPI.Server = getAPIServerConfig(c)
+	if err := configureAPIAccess(c, httpAPI); err != nil {
+		log.Fatal(err)
+	}
 
-	if enableUpdateAPI {
-		updateHandler := update.New(func(images []string) {
-			metric := runUpdatesWithNotifications(filters.FilterByImage(images, filter))
-			metrics.RegisterScan(metric)
+	// The update API and the webhook share the queue, so that their jobs are run one at a time and coalesced
+	var updateQueue *update.Queue
+	if enableUpdateAPI || enableWebhookAPI || enableDashboard {
+		updateQueue = update.NewQueue(func(target update.Target) (t.Report, error) {
+			result, err := runUpdateSession(target.Filter(filter))
+			metrics.RegisterScan(metrics.NewMetric(result))
+			return result, err
 		}, updateLock)
-		httpAPI.RegisterFunc(updateHandler.Path, updateHandler.Handle)
+	}
+
+	// The dashboard uses the update, container status and event endpoints, but does not stop the periodic updates
+	if enableUpdateAPI || enableDashboard {
+		updateHandler := update.NewWithQueue(updateQueue)
+		httpAPI.RegisterFunc(updateHandler.Path, api.ScopeUpdateTrigger, updateHandler.Handle)
+		httpAPI.RegisterFunc(updateHandler.JobsPath, api.ScopeUpdateTrigger, updateHandler.HandleJob)
+	}
+
+	if enableUpdateAPI {
 		// If polling isn't enabled the scheduler is never started, and
 		// we need to trigger the startup messages manually.
 		if !unblockHTTPAPI {
@@ -196,20 +275,110 @@ func Run(c *cobra.Command, names []string) {
 
 	if enableMetricsAPI {
 		metricsHandler := apiMetrics.New()
-		httpAPI.RegisterHandler(metricsHandler.Path, metricsHandler.Handle)
+		httpAPI.RegisterHandler(metricsHandler.Path, api.ScopeMetricsRead, metricsHandler.Handle)
 	}
 
-	if err := httpAPI.Start(enableUpdateAPI && !unblockHTTPAPI); err != nil && !errors.Is(err, http.ErrServerClosed) {
-		log.Error("failed to start API", err)
+	if enableContainersAPI || enableDashboard {
+		containersHandler = containers.New(client, filter, getUpdateParams(filter))
+		httpAPI.RegisterFunc(containersHandler.Path, api.ScopeStatusRead, containersHandler.Handle)
+		httpAPI.RegisterFunc(containersHandler.Path+"/", api.ScopeStatusRead, containersHandler.Handle)
 	}
 
-	if err := runUpgradesOnSchedule(c, filter, filterDesc, updateLock); err != nil {
-		log.Error(err)
+	if enableEventsAPI || enableDashboard {
+		eventsHandler := apiEvents.New(session.Events())
+		httpAPI.RegisterFunc(eventsHandler.Path, api.ScopeStatusRead, eventsHandler.Handle)
+		httpAPI.OnShutdown(eventsHandler.Close)
+	}
+
+	if enableSchedulerAPI {
+		schedulerHandler := apiScheduler.New(pauseStore)
+		// The status is served at the path itself, while pausing and resuming are below it
+		httpAPI.RegisterFunc(schedulerHandler.Path, api.ScopeStatusRead, schedulerHandler.Handle)
+		httpAPI.RegisterFunc(schedulerHandler.Path+"/", api.ScopeSchedulerWrite, schedulerHandler.Handle)
+	}
+
+	if enableDashboard {
+		sessionsHandler = sessions.New()
+		httpAPI.RegisterFunc(sessionsHandler.Path, api.ScopeStatusRead, sessionsHandler.Handle)
+		dashboardHandler := dashboard.New()
+		httpAPI.RegisterPublicHandler(dashboardHandler.Path, dashboardHandler)
+	}
+
+	if enableHealthAPI {
+		healthHandler := apiHealth.New(healthMonitor)
+		httpAPI.RegisterPublicHandler(healthHandler.Path, healthHandler)
+		httpAPI.RegisterPublicHandler(healthHandler.Path+"/", healthHandler)
+		go healthMonitor.WatchDaemon(client.Ping, nil)
+	}
+
+	if enableWebhookAPI {
+		webhookSecret, _ := c.PersistentFlags().GetString("http-api-webhook-secret")
+		if webhookSecret == "" {
+			log.Fatal("The registry webhook requires a secret to be set using --http-api-webhook-secret")
+		}
+		webhookHandler := apiWebhook.New(updateQueue, webhookSecret)
+		httpAPI.RegisterExternalFunc(webhookHandler.Path, "webhook", webhookHandler.Handle)
+	}
+
+	// The OpenAPI document is served along with any of the other endpoints
+	if httpAPI.HasHandlers() {
+		openapiHandler := openapi.New()
+		httpAPI.RegisterPublicHandler(openapiHandler.Path, openapiHandler)
+	}
+
+	blockHTTPAPI := enableUpdateAPI && !unblockHTTPAPI
+	if err := httpAPI.Start(blockHTTPAPI); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("Failed to start the HTTP API: %v", err)
+	}
+
+	// When blocking, the API has already been shut down, and periodic updates are not enabled
+	if !blockHTTPAPI {
+		if err := runUpgradesOnSchedule(c, filter, filterDesc, updateLock); err != nil {
+			log.Error(err)
+		}
+		if err := httpAPI.Stop(); err != nil {
+			log.WithError(err).Warn("Failed to shut down the HTTP API gracefully")
+		}
 	}
 
 	os.Exit(1)
 }
 
+// getAPIServerConfig reads the settings for where the HTTP API listens from the flags
+func getAPIServerConfig(c *cobra.Command) api.ServerConfig {
+	f := c.PersistentFlags()
+	config := api.ServerConfig{}
+	config.Host, _ = f.GetString("http-api-host")
+	config.Port, _ = f.GetInt("http-api-port")
+	config.Socket, _ = f.GetString("http-api-socket")
+	config.TLSCert, _ = f.GetString("http-api-tls-cert")
+	config.TLSKey, _ = f.GetString("http-api-tls-key")
+	config.TLSClientCA, _ = f.GetString("http-api-tls-client-ca")
+	config.TLSClientCert, _ = f.GetString("http-api-tls-client-cert")
+	config.TLSClientKey, _ = f.GetString("http-api-tls-client-key")
+	return config
+}
+
+// configureAPIAccess sets the named API tokens and the audit log of the API
+func configureAPIAccess(c *cobra.Command, httpAPI *api.API) error {
+	f := c.PersistentFlags()
+	if tokensFile, _ := f.GetString("http-api-tokens-file"); tokensFile != "" {
+		tokens, err := api.ReadTokensFile(tokensFile)
+		if err != nil {
+			return err
+		}
+		httpAPI.Tokens = tokens
+	}
+	if auditLog, _ := f.GetString("http-api-audit-log"); auditLog != "" {
+		audit, err := api.NewAuditLog(auditLog)
+		if err != nil {
+			return err
+		}
+		httpAPI.Audit = audit
+	}
+	return nil
+}
+
 func logNotifyExit(err error) {
 	log.Error(err)
 	notifier.Close()
@@ -295,8 +464,12 @@ func writeStartupMessage(c *cobra.Command, sched time.Time, filtering string) {
 	}
 
 	if enableUpdateAPI {
-		// TODO: make listen port configurable
-		startupLog.Info("The HTTP API is enabled at :8080.")
+		serverConfig := getAPIServerConfig(c)
+		if serverConfig.UsesTLS() {
+			startupLog.Infof("The HTTP API is enabled at %s, using TLS.", serverConfig.Address())
+		} else {
+			startupLog.Infof("The HTTP API is enabled at %s.", serverConfig.Address())
+		}
 	}
 
 	if !noStartupMessage {
@@ -316,9 +489,19 @@ func runUpgradesOnSchedule(c *cobra.Command, filter t.Filter, filtering string,
 	}
 
 	scheduler := cron.New()
+	var schedule cron.Schedule
 	err := scheduler.AddFunc(
 		scheduleSpec,
 		func() {
+			now := time.Now()
+			healthMonitor.RecordTick(now, schedule.Next(now))
+
+			if paused, state := scheduleIsPaused(); paused {
+				metrics.RegisterScan(nil)
+				log.WithField("reason", state.Reason).Info("Skipped the scheduled update, as scheduled updates are paused.")
+				return
+			}
+
 			select {
 			case v := <-lock:
 				defer func() { lock <- v }()
@@ -340,7 +523,10 @@ func runUpgradesOnSchedule(c *cobra.Command, filter t.Filter, filtering string,
 		return err
 	}
 
-	writeStartupMessage(c, scheduler.Entries()[0].Schedule.Next(time.Now()), filtering)
+	schedule = scheduler.Entries()[0].Schedule
+	nextRun := schedule.Next(time.Now())
+	healthMonitor.RecordTick(time.Time{}, nextRun)
+	writeStartupMessage(c, nextRun, filtering)
 
 	scheduler.Start()
 
@@ -356,23 +542,45 @@ func runUpgradesOnSchedule(c *cobra.Command, filter t.Filter, filtering string,
 	return nil
 }
 
+// getPauseStore returns the store holding whether the scheduled updates are paused
+func getPauseStore(c *cobra.Command) *pause.Store {
+	stateFile, _ := c.Flags().GetString("scheduler-state-file")
+	return pause.NewStore(stateFile)
+}
+
+// scheduleIsPaused returns whether the scheduled updates are paused. If the pause state cannot be read, the updates
+// are skipped, as it is safer to not touch the containers during a change freeze.
+func scheduleIsPaused() (bool, pause.State) {
+	state, err := pauseStore.Status()
+	healthMonitor.RecordPauseState(err)
+	if err != nil {
+		log.WithError(err).Error("Failed to check whether scheduled updates are paused")
+		return true, state
+	}
+	return state.Paused, state
+}
+
 func runUpdatesWithNotifications(filter t.Filter) *metrics.Metric {
+	result, _ := runUpdateSession(filter)
+	return metrics.NewMetric(result)
+}
+
+// runUpdateSession runs an update session and sends the notifications for it, returning the session report
+func runUpdateSession(filter t.Filter) (t.Report, error) {
 	notifier.StartNotification()
-	updateParams := t.UpdateParams{
-		Filter:          filter,
-		Cleanup:         cleanup,
-		NoRestart:       noRestart,
-		Timeout:         timeout,
-		MonitorOnly:     monitorOnly,
-		LifecycleHooks:  lifecycleHooks,
-		RollingRestart:  rollingRestart,
-		LabelPrecedence: labelPrecedence,
-		NoPull:          noPull,
-	}
-	result, err := actions.Update(client, updateParams)
+	started := time.Now()
+	healthMonitor.SessionStarted(started)
+	defer healthMonitor.SessionFinished()
+	result, err := actions.Update(client, getUpdateParams(filter))
 	if err != nil {
 		log.Error(err)
 	}
+	if containersHandler != nil {
+		containersHandler.RecordSession(result, time.Now())
+	}
+	if sessionsHandler != nil {
+		sessionsHandler.RecordSession(result, started, time.Now(), err)
+	}
 	notifier.SendNotification(result)
 	metricResults := metrics.NewMetric(result)
 	notifications.LocalLog.WithFields(log.Fields{
@@ -380,5 +588,25 @@ func runUpdatesWithNotifications(filter t.Filter) *metrics.Metric {
 		"Updated": metricResults.Updated,
 		"Failed":  metricResults.Failed,
 	}).Info("Session done")
-	return metricResults
+	return result, err
+}
+
+func getUpdateParams(filter t.Filter) t.UpdateParams {
+	return t.UpdateParams{
+		Filter:           filter,
+		Cleanup:          cleanup,
+		NoRestart:        noRestart,
+		Timeout:          timeout,
+		MonitorOnly:      monitorOnly,
+		CheckOnly:        checkOnly,
+		LifecycleHooks:   lifecycleHooks,
+		RollingRestart:   rollingRestart,
+		LabelPrecedence:  labelPrecedence,
+		NoPull:           noPull,
+		VerifySignatures: verifySignatures,
+		SignatureKeys:    signatureKeys,
+		FailureLogLines:  failureLogLines,
+		ExitCheckDelay:   exitCheckDelay,
+		ReviveStopped:    reviveStopped,
+	}
 }
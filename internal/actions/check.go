@@ -65,7 +65,7 @@ func cleanupExcessWatchtowers(containers []types.Container, client container.Cli
 	allContainersExceptLast := containers[0 : len(containers)-1]
 
 	for _, c := range allContainersExceptLast {
-		if err := client.StopContainer(c, 10*time.Minute); err != nil {
+		if err := stopContainer(client, c, 10*time.Minute); err != nil {
 			// logging the original here as we're just returning a count
 			log.WithError(err).Error("Could not stop a previous watchtower instance.")
 			stopErrors++
@@ -1,6 +1,7 @@
 package mocks
 
 import (
+	"crypto"
 	"errors"
 	"fmt"
 	"time"
@@ -21,6 +22,15 @@ type TestData struct {
 	NameOfContainerToKeep   string
 	Containers              []t.Container
 	Staleness               map[string]bool
+	StalenessErrors         map[string]error
+	SignatureErrors         map[string]error
+	ImageMetadata           map[string]t.ImageMetadata
+	RemoteDigests           map[string]string
+	PullStats               map[string]t.PullStats
+	CommandOutputs          map[t.ContainerID]t.CommandOutput
+	StartErrors             map[string]error
+	ExitErrors              map[string]error
+	ContainerLogs           map[string]string
 }
 
 // TriedToRemoveImage is a test helper function to check whether RemoveImageByID has been called
@@ -39,7 +49,8 @@ func CreateMockClient(data *TestData, pullImages bool, removeVolumes bool) MockC
 
 // ListContainers is a mock method returning the provided container testdata
 func (client MockClient) ListContainers(_ t.Filter) ([]t.Container, error) {
-	return client.TestData.Containers, nil
+	// The containers are copied, as sorting them by their dependencies reorders the returned slice
+	return append([]t.Container{}, client.TestData.Containers...), nil
 }
 
 // StopContainer is a mock method
@@ -51,8 +62,8 @@ func (client MockClient) StopContainer(c t.Container, _ time.Duration) error {
 }
 
 // StartContainer is a mock method
-func (client MockClient) StartContainer(_ t.Container) (t.ContainerID, error) {
-	return "", nil
+func (client MockClient) StartContainer(c t.Container) (t.ContainerID, error) {
+	return c.ID(), client.TestData.StartErrors[c.Name()]
 }
 
 // RenameContainer is a mock method
@@ -71,30 +82,75 @@ func (client MockClient) GetContainer(_ t.ContainerID) (t.Container, error) {
 	return client.TestData.Containers[0], nil
 }
 
-// ExecuteCommand is a mock method
-func (client MockClient) ExecuteCommand(_ t.ContainerID, command string, _ int) (SkipUpdate bool, err error) {
+// ExecuteCommand is a mock method, returning the command output set for the container ID in TestData
+func (client MockClient) ExecuteCommand(containerID t.ContainerID, command string, _ int) (SkipUpdate bool, output *t.CommandOutput, err error) {
+	if commandOutput, found := client.TestData.CommandOutputs[containerID]; found {
+		output = &commandOutput
+	}
 	switch command {
 	case "/PreUpdateReturn0.sh":
-		return false, nil
+		return false, output, nil
 	case "/PreUpdateReturn1.sh":
-		return false, fmt.Errorf("command exited with code 1")
+		return false, output, fmt.Errorf("command exited with code 1")
 	case "/PreUpdateReturn75.sh":
-		return true, nil
+		return true, output, nil
 	default:
-		return false, nil
+		return false, output, nil
 	}
 }
 
 // IsContainerStale is true if not explicitly stated in TestData for the mock client
-func (client MockClient) IsContainerStale(cont t.Container, params t.UpdateParams) (bool, t.ImageID, error) {
+func (client MockClient) IsContainerStale(cont t.Container, params t.UpdateParams) (t.ImageCheck, error) {
+	if err, found := client.TestData.StalenessErrors[cont.Name()]; found {
+		return t.ImageCheck{LatestImage: cont.SafeImageID()}, err
+	}
 	stale, found := client.TestData.Staleness[cont.Name()]
 	if !found {
 		stale = true
 	}
-	return stale, "", nil
+	return t.ImageCheck{
+		Stale:        stale,
+		LatestDigest: client.TestData.RemoteDigests[cont.Name()],
+		Pull:         client.TestData.PullStats[cont.Name()],
+	}, nil
+}
+
+// VerifyImageSignature returns an error if one is set for the container name in TestData
+func (client MockClient) VerifyImageSignature(cont t.Container, _ t.ImageID, _ []crypto.PublicKey) error {
+	return client.TestData.SignatureErrors[cont.Name()]
+}
+
+// GetImageMetadata returns the metadata set for the container name in TestData
+func (client MockClient) GetImageMetadata(cont t.Container, _ t.ImageID) (t.ImageMetadata, error) {
+	return client.TestData.ImageMetadata[cont.Name()], nil
 }
 
 // WarnOnHeadPullFailed is always true for the mock client
 func (client MockClient) WarnOnHeadPullFailed(_ t.Container) bool {
 	return true
 }
+
+// CheckContainerRunning returns the exit error of the container with the given ID, if any
+func (client MockClient) CheckContainerRunning(containerID t.ContainerID) error {
+	for _, c := range client.TestData.Containers {
+		if c.ID() == containerID {
+			return client.TestData.ExitErrors[c.Name()]
+		}
+	}
+	return nil
+}
+
+// Ping always succeeds for the mock client
+func (client MockClient) Ping() error {
+	return nil
+}
+
+// GetContainerLogs returns the logs of the container with the given ID from the test data
+func (client MockClient) GetContainerLogs(containerID t.ContainerID, _ int) (string, error) {
+	for _, c := range client.TestData.Containers {
+		if c.ID() == containerID {
+			return client.TestData.ContainerLogs[c.Name()], nil
+		}
+	}
+	return "", fmt.Errorf("no such container: %s", containerID)
+}
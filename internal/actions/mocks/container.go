@@ -21,6 +21,7 @@ func CreateMockContainer(id string, name string, image string, created time.Time
 			Image:   image,
 			Name:    name,
 			Created: created.String(),
+			State:   &types.ContainerState{Running: true},
 			HostConfig: &dockerContainer.HostConfig{
 				PortBindings: map[nat.Port][]nat.PortBinding{},
 			},
@@ -60,6 +61,7 @@ func CreateMockContainerWithImageInfoP(id string, name string, image string, cre
 			Image:   image,
 			Name:    name,
 			Created: created.String(),
+			State:   &types.ContainerState{Running: true},
 		},
 		Config: &dockerContainer.Config{
 			Image:  image,
@@ -127,6 +129,7 @@ func CreateMockContainerWithLinks(id string, name string, image string, created
 			Image:   image,
 			Name:    name,
 			Created: created.String(),
+			State:   &types.ContainerState{Running: true},
 			HostConfig: &dockerContainer.HostConfig{
 				Links: links,
 			},
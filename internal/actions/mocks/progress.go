@@ -33,6 +33,9 @@ func CreateMockProgressReport(states ...session.State) wt.Report {
 			c, newImage := CreateContainerForProgress(index, 21, "fail%d")
 			progress.AddScanned(c, newImage)
 			failed[c.ID()] = errors.New("accidentally the whole container")
+		case session.StaleState:
+			c, newImage := CreateContainerForProgress(index, 61, "stal%d")
+			progress.AddScanned(c, newImage)
 		}
 
 		stateNums[state] = index + 1
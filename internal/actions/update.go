@@ -2,10 +2,13 @@ package actions
 
 import (
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/containrrr/watchtower/internal/util"
 	"github.com/containrrr/watchtower/pkg/container"
 	"github.com/containrrr/watchtower/pkg/lifecycle"
+	"github.com/containrrr/watchtower/pkg/registry/signature"
 	"github.com/containrrr/watchtower/pkg/session"
 	"github.com/containrrr/watchtower/pkg/sorter"
 	"github.com/containrrr/watchtower/pkg/types"
@@ -18,22 +21,32 @@ import (
 // the new image.
 func Update(client container.Client, params types.UpdateParams) (types.Report, error) {
 	log.Debug("Checking containers for updated images")
+	session.PublishEvent(session.Event{Type: session.SessionStartedEvent})
 	progress := &session.Progress{}
 	staleCount := 0
 
 	if params.LifecycleHooks {
-		lifecycle.ExecutePreChecks(client, params)
+		executeChecks(client, params, lifecycle.ExecutePreCheckCommand)
 	}
 
 	containers, err := client.ListContainers(params.Filter)
 	if err != nil {
+		publishSessionFinished(nil, err)
 		return nil, err
 	}
 
 	staleCheckFailed := 0
 
 	for i, targetContainer := range containers {
-		stale, newestImage, err := client.IsContainerStale(targetContainer, params)
+		pulling := !params.CheckOnly && !targetContainer.IsNoPull(params)
+		if pulling {
+			session.PublishEvent(session.ContainerEvent(session.PullStartedEvent, targetContainer))
+		}
+		check, err := client.IsContainerStale(targetContainer, params)
+		if pulling {
+			publishPullFinished(targetContainer, check.Pull, err)
+		}
+		stale, newestImage := check.Stale, check.LatestImage
 		shouldUpdate := stale && !params.NoRestart && !targetContainer.IsMonitorOnly(params)
 		if err == nil && shouldUpdate {
 			// Check to make sure we have all the necessary information for recreating the container
@@ -49,15 +62,34 @@ func Update(client container.Client, params types.UpdateParams) (types.Report, e
 			}
 		}
 
-		if err != nil {
+		if errors.Is(err, container.ErrUnknownStaleness) {
+			log.Infof("Unable to check container %q for updates: %v. Proceeding to next.", targetContainer.Name(), err)
+			stale = false
+			staleCheckFailed++
+			progress.AddUnknown(targetContainer, err)
+		} else if err != nil {
 			log.Infof("Unable to update container %q: %v. Proceeding to next.", targetContainer.Name(), err)
 			stale = false
 			staleCheckFailed++
 			progress.AddSkipped(targetContainer, err)
+		} else if err = verifySignature(client, targetContainer, newestImage, params, shouldUpdate); err != nil {
+			log.Warnf("Rejected new image for container %q: %v", targetContainer.Name(), err)
+			stale = false
+			progress.AddRejected(targetContainer, newestImage, err)
 		} else {
 			progress.AddScanned(targetContainer, newestImage)
 		}
+		if err == nil && (newestImage != targetContainer.SafeImageID() || check.LatestDigest != "") {
+			addLatestMetadata(client, targetContainer, newestImage, progress)
+		}
+		if check.LatestDigest != "" {
+			progress.SetLatestDigest(targetContainer.ID(), check.LatestDigest)
+		}
+		if !check.Pull.IsEmpty() {
+			progress.SetPullStats(targetContainer.ID(), check.Pull)
+		}
 		containers[i].SetStale(stale)
+		publishChecked(targetContainer, (*progress)[targetContainer.ID()], stale)
 
 		if stale {
 			staleCount++
@@ -66,6 +98,7 @@ func Update(client container.Client, params types.UpdateParams) (types.Report, e
 
 	containers, err = sorter.SortByDependencies(containers)
 	if err != nil {
+		publishSessionFinished(nil, err)
 		return nil, err
 	}
 
@@ -80,23 +113,145 @@ func Update(client container.Client, params types.UpdateParams) (types.Report, e
 	}
 
 	if params.RollingRestart {
-		progress.UpdateFailed(performRollingRestart(containersToUpdate, client, params))
+		updateFailed(progress, performRollingRestart(containersToUpdate, client, params))
 	} else {
 		failedStop, stoppedImages := stopContainersInReversedOrder(containersToUpdate, client, params)
-		progress.UpdateFailed(failedStop)
+		updateFailed(progress, failedStop)
 		failedStart := restartContainersInSortedOrder(containersToUpdate, client, params, stoppedImages)
-		progress.UpdateFailed(failedStart)
+		updateFailed(progress, failedStart)
 	}
 
 	if params.LifecycleHooks {
-		lifecycle.ExecutePostChecks(client, params)
+		executeChecks(client, params, lifecycle.ExecutePostCheckCommand)
+	}
+	report := progress.Report()
+	publishSessionFinished(report, nil)
+	return report, nil
+}
+
+// publishChecked publishes the result of checking the container for a newer image
+func publishChecked(c types.Container, status *session.ContainerStatus, stale bool) {
+	event := session.ContainerEvent(session.ContainerCheckedEvent, c)
+	state := status.State()
+	if state == session.ScannedState.String() {
+		state = session.FreshState.String()
+		if stale {
+			state = session.StaleState.String()
+		}
+	}
+	event.Error = status.Error()
+	event.Data = map[string]interface{}{
+		"state":          state,
+		"currentImageId": status.CurrentImageID(),
+		"latestImageId":  status.LatestImageID(),
+	}
+	session.PublishEvent(event)
+}
+
+// publishPullFinished publishes the end of the image pull for the container, including the statistics of the pull if
+// an image was downloaded
+func publishPullFinished(c types.Container, stats types.PullStats, err error) {
+	event := session.ContainerEvent(session.PullFinishedEvent, c).WithError(err)
+	if !stats.IsEmpty() {
+		event.Data = map[string]interface{}{
+			"bytes":    stats.Bytes,
+			"duration": stats.Duration.Seconds(),
+		}
+	}
+	session.PublishEvent(event)
+}
+
+// publishStarted publishes the creation of the replacement for the container, and its start if it was started
+func publishStarted(c types.Container, newContainerID types.ContainerID, started bool) {
+	created := session.ContainerEvent(session.ContainerCreatedEvent, c)
+	created.ContainerID = newContainerID
+	session.PublishEvent(created)
+
+	if !started {
+		return
+	}
+	event := session.ContainerEvent(session.ContainerStartedEvent, c)
+	event.ContainerID = newContainerID
+	session.PublishEvent(event)
+}
+
+// publishHookOutput publishes the output of the lifecycle hook command that was executed in the container with the
+// supplied ID, if a command was executed
+func publishHookOutput(c types.Container, containerID types.ContainerID, output *types.CommandOutput) {
+	if output == nil {
+		return
+	}
+	event := session.ContainerEvent(session.HookOutputEvent, c)
+	event.ContainerID = containerID
+	event.Data = map[string]interface{}{
+		"output":   output.Output,
+		"exitCode": output.ExitCode,
+	}
+	session.PublishEvent(event)
+}
+
+// executeChecks runs the pre-check or post-check lifecycle hook for all containers included by the current filter
+func executeChecks(client container.Client, params types.UpdateParams, check func(container.Client, types.Container) *types.CommandOutput) {
+	containers, err := client.ListContainers(params.Filter)
+	if err != nil {
+		return
+	}
+	for _, c := range containers {
+		publishHookOutput(c, c.ID(), check(client, c))
+	}
+}
+
+// stopContainer publishes that the container is stopping, and stops it
+func stopContainer(client container.Client, c types.Container, timeout time.Duration) error {
+	session.PublishEvent(session.ContainerEvent(session.ContainerStoppingEvent, c))
+	return client.StopContainer(c, timeout)
+}
+
+// publishSessionFinished publishes the end of the session, including the number of containers in each state
+func publishSessionFinished(report types.Report, err error) {
+	event := session.Event{Type: session.SessionFinishedEvent}.WithError(err)
+	if report != nil {
+		event.Report = report
+		event.Data = session.ReportCounts(report, nil)
+	}
+	session.PublishEvent(event)
+}
+
+// verifySignature checks the signature of the new image if the container is about to be updated and
+// signature verification is enabled for it
+func verifySignature(client container.Client, c types.Container, newImage types.ImageID, params types.UpdateParams, shouldUpdate bool) error {
+	if !shouldUpdate || !c.IsVerifySignature(params) {
+		return nil
+	}
+
+	keys := params.SignatureKeys
+	if keyFiles := c.GetSignatureKeys(); len(keyFiles) > 0 {
+		// The keys set using labels can differ for each container, so they are only read once they are needed
+		labelKeys, err := signature.LoadPublicKeys(keyFiles)
+		if err != nil {
+			return fmt.Errorf("image signature verification failed: %w", err)
+		}
+		keys = append(labelKeys, keys...)
+	}
+	if err := client.VerifyImageSignature(c, newImage, keys); err != nil {
+		return fmt.Errorf("image signature verification failed: %w", err)
+	}
+	return nil
+}
+
+// addLatestMetadata adds the metadata of the newest image for the container to the session progress
+func addLatestMetadata(client container.Client, c types.Container, newImage types.ImageID, progress *session.Progress) {
+	metadata, err := client.GetImageMetadata(c, newImage)
+	if err != nil {
+		log.Debugf("Failed to get image metadata for container %q: %v", c.Name(), err)
+		return
 	}
-	return progress.Report(), nil
+	progress.SetLatestMetadata(c.ID(), metadata)
 }
 
 func performRollingRestart(containers []types.Container, client container.Client, params types.UpdateParams) map[types.ContainerID]error {
-	cleanupImageIDs := make(map[types.ImageID]bool, len(containers))
 	failed := make(map[types.ContainerID]error, len(containers))
+	restarted := make(map[types.ContainerID]types.ContainerID, len(containers))
 
 	for i := len(containers) - 1; i >= 0; i-- {
 		if containers[i].ToRestart() {
@@ -104,18 +259,19 @@ func performRollingRestart(containers []types.Container, client container.Client
 			if err != nil {
 				failed[containers[i].ID()] = err
 			} else {
-				if err := restartStaleContainer(containers[i], client, params); err != nil {
+				if newContainerID, err := restartStaleContainer(containers[i], client, params); err != nil {
 					failed[containers[i].ID()] = err
-				} else if containers[i].IsStale() {
-					// Only add (previously) stale containers' images to cleanup
-					cleanupImageIDs[containers[i].ImageID()] = true
+				} else {
+					restarted[containers[i].ID()] = newContainerID
 				}
 			}
 		}
 	}
 
+	checkRestartedContainers(containers, restarted, client, params, failed)
+
 	if params.Cleanup {
-		cleanupImages(client, cleanupImageIDs)
+		cleanupImages(client, restartedImages(containers, restarted, failed))
 	}
 	return failed
 }
@@ -153,7 +309,8 @@ func stopStaleContainer(container types.Container, client container.Client, para
 	}
 
 	if params.LifecycleHooks {
-		skipUpdate, err := lifecycle.ExecutePreUpdateCommand(client, container)
+		skipUpdate, output, err := lifecycle.ExecutePreUpdateCommand(client, container)
+		publishHookOutput(container, container.ID(), output)
 		if err != nil {
 			log.Error(err)
 			log.Info("Skipping container as the pre-update command failed")
@@ -165,7 +322,7 @@ func stopStaleContainer(container types.Container, client container.Client, para
 		}
 	}
 
-	if err := client.StopContainer(container, params.Timeout); err != nil {
+	if err := stopContainer(client, container, params.Timeout); err != nil {
 		log.Error(err)
 		return err
 	}
@@ -173,30 +330,73 @@ func stopStaleContainer(container types.Container, client container.Client, para
 }
 
 func restartContainersInSortedOrder(containers []types.Container, client container.Client, params types.UpdateParams, stoppedImages map[types.ImageID]bool) map[types.ContainerID]error {
-	cleanupImageIDs := make(map[types.ImageID]bool, len(containers))
 	failed := make(map[types.ContainerID]error, len(containers))
+	restarted := make(map[types.ContainerID]types.ContainerID, len(containers))
 
 	for _, c := range containers {
 		if !c.ToRestart() {
 			continue
 		}
 		if stoppedImages[c.SafeImageID()] {
-			if err := restartStaleContainer(c, client, params); err != nil {
+			if newContainerID, err := restartStaleContainer(c, client, params); err != nil {
 				failed[c.ID()] = err
-			} else if c.IsStale() {
-				// Only add (previously) stale containers' images to cleanup
-				cleanupImageIDs[c.ImageID()] = true
+			} else {
+				restarted[c.ID()] = newContainerID
 			}
 		}
 	}
 
+	checkRestartedContainers(containers, restarted, client, params, failed)
+
 	if params.Cleanup {
-		cleanupImages(client, cleanupImageIDs)
+		cleanupImages(client, restartedImages(containers, restarted, failed))
 	}
 
 	return failed
 }
 
+// checkRestartedContainers waits for the exit check delay once, and then checks that each of the restarted containers
+// is still running, adding the ones that are not to failed
+func checkRestartedContainers(containers []types.Container, restarted map[types.ContainerID]types.ContainerID, client container.Client, params types.UpdateParams, failed map[types.ContainerID]error) {
+	if params.ExitCheckDelay <= 0 {
+		return
+	}
+
+	var checked []types.Container
+	for _, c := range containers {
+		if restarted[c.ID()] != "" && c.IsRunning() && !c.IsWatchtower() {
+			checked = append(checked, c)
+		}
+	}
+	if len(checked) == 0 {
+		return
+	}
+
+	time.Sleep(params.ExitCheckDelay)
+	for _, c := range checked {
+		newContainerID := restarted[c.ID()]
+		if err := client.CheckContainerRunning(newContainerID); err != nil {
+			log.Error(err)
+			failed[c.ID()] = withLogTail(client, newContainerID, err, params.FailureLogLines)
+		}
+	}
+}
+
+// restartedImages returns the images of the stale containers that were restarted successfully, to be cleaned up
+func restartedImages(containers []types.Container, restarted map[types.ContainerID]types.ContainerID, failed map[types.ContainerID]error) map[types.ImageID]bool {
+	imageIDs := make(map[types.ImageID]bool, len(restarted))
+	for _, c := range containers {
+		if _, found := restarted[c.ID()]; !found || failed[c.ID()] != nil {
+			continue
+		}
+		// Only add (previously) stale containers' images to cleanup
+		if c.IsStale() {
+			imageIDs[c.ImageID()] = true
+		}
+	}
+	return imageIDs
+}
+
 func cleanupImages(client container.Client, imageIDs map[types.ImageID]bool) {
 	for imageID := range imageIDs {
 		if imageID == "" {
@@ -208,7 +408,9 @@ func cleanupImages(client container.Client, imageIDs map[types.ImageID]bool) {
 	}
 }
 
-func restartStaleContainer(container types.Container, client container.Client, params types.UpdateParams) error {
+// restartStaleContainer starts the replacement of the container, returning the ID of the new container if one was
+// started
+func restartStaleContainer(container types.Container, client container.Client, params types.UpdateParams) (types.ContainerID, error) {
 	// Since we can't shutdown a watchtower container immediately, we need to
 	// start the new one while the old one is still running. This prevents us
 	// from re-using the same container name so we first rename the current
@@ -216,19 +418,70 @@ func restartStaleContainer(container types.Container, client container.Client, p
 	if container.IsWatchtower() {
 		if err := client.RenameContainer(container, util.RandName()); err != nil {
 			log.Error(err)
-			return nil
+			return "", nil
 		}
 	}
 
-	if !params.NoRestart {
-		if newContainerID, err := client.StartContainer(container); err != nil {
-			log.Error(err)
-			return err
-		} else if container.ToRestart() && params.LifecycleHooks {
-			lifecycle.ExecutePostUpdateCommand(client, newContainerID)
+	if params.NoRestart {
+		return "", nil
+	}
+
+	newContainerID, err := client.StartContainer(container)
+	if newContainerID != "" {
+		// Stopped containers are only started when they are revived
+		publishStarted(container, newContainerID, err == nil && (container.IsRunning() || params.ReviveStopped))
+	}
+	if err != nil {
+		log.Error(err)
+		return newContainerID, withLogTail(client, newContainerID, err, params.FailureLogLines)
+	}
+	if container.ToRestart() && params.LifecycleHooks {
+		output := lifecycle.ExecutePostUpdateCommand(client, newContainerID)
+		publishHookOutput(container, newContainerID, output)
+	}
+	return newContainerID, nil
+}
+
+// failureWithLogs is an update failure including the tail of the logs of the container that failed
+type failureWithLogs struct {
+	error
+	logs string
+}
+
+func (f failureWithLogs) Unwrap() error {
+	return f.error
+}
+
+// withLogTail adds the last lines of the logs of the container to the error, if the container was created
+func withLogTail(client container.Client, containerID types.ContainerID, err error, lines int) error {
+	if lines <= 0 || containerID == "" {
+		return err
+	}
+	logs, logErr := client.GetContainerLogs(containerID, lines)
+	if logErr != nil {
+		log.WithError(logErr).Debug("Could not get the logs of the failed container")
+		return err
+	}
+	return failureWithLogs{err, logs}
+}
+
+// updateFailed marks the containers as failed in the progress, including the logs of the failures that have them
+func updateFailed(progress *session.Progress, failures map[types.ContainerID]error) {
+	progress.UpdateFailed(failures)
+	for id, err := range failures {
+		var failure failureWithLogs
+		if errors.As(err, &failure) {
+			progress.SetLogs(id, failure.logs)
+		}
+		if status, found := (*progress)[id]; found {
+			session.PublishEvent(session.Event{
+				Type:        session.ContainerFailedEvent,
+				Container:   status.Name(),
+				ContainerID: id,
+				Image:       status.ImageName(),
+			}.WithError(err))
 		}
 	}
-	return nil
 }
 
 // UpdateImplicitRestart iterates through the passed containers, setting the
@@ -1,9 +1,13 @@
 package actions_test
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/containrrr/watchtower/internal/actions"
+	"github.com/containrrr/watchtower/pkg/container"
+	"github.com/containrrr/watchtower/pkg/session"
 	"github.com/containrrr/watchtower/pkg/types"
 	dockerTypes "github.com/docker/docker/api/types"
 	dockerContainer "github.com/docker/docker/api/types/container"
@@ -258,6 +262,366 @@ var _ = Describe("the update action", func() {
 		})
 	})
 
+	When("watchtower has been instructed to verify image signatures", func() {
+		When("the signature of a new image is invalid", func() {
+			It("should reject the image and not update the container", func() {
+				client := CreateMockClient(
+					&TestData{
+						Containers: []types.Container{
+							CreateMockContainer(
+								"test-container-01",
+								"test-container-01",
+								"fake-image1:latest",
+								time.Now()),
+							CreateMockContainer(
+								"test-container-02",
+								"test-container-02",
+								"fake-image2:latest",
+								time.Now()),
+						},
+						SignatureErrors: map[string]error{
+							"test-container-01": errors.New("no valid signature found for image"),
+						},
+					},
+					false,
+					false,
+				)
+				report, err := actions.Update(client, types.UpdateParams{Cleanup: true, VerifySignatures: true})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(report.Rejected()).To(HaveLen(1))
+				Expect(report.Rejected()[0].Name()).To(Equal("test-container-01"))
+				Expect(report.Rejected()[0].State()).To(Equal("Rejected"))
+				Expect(report.Updated()).To(HaveLen(1))
+				Expect(client.TestData.TriedToRemoveImageCount).To(Equal(1))
+			})
+		})
+		When("signature verification is enabled using a label", func() {
+			It("should only verify the signatures for those containers", func() {
+				client := CreateMockClient(
+					&TestData{
+						Containers: []types.Container{
+							CreateMockContainerWithConfig(
+								"test-container-01",
+								"test-container-01",
+								"fake-image1:latest",
+								true,
+								false,
+								time.Now(),
+								&dockerContainer.Config{
+									Labels: map[string]string{
+										"com.centurylinklabs.watchtower.verify-signature": "true",
+									},
+								}),
+							CreateMockContainer(
+								"test-container-02",
+								"test-container-02",
+								"fake-image2:latest",
+								time.Now()),
+						},
+						SignatureErrors: map[string]error{
+							"test-container-01": errors.New("no signature found for image"),
+							"test-container-02": errors.New("no signature found for image"),
+						},
+					},
+					false,
+					false,
+				)
+				report, err := actions.Update(client, types.UpdateParams{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(report.Rejected()).To(HaveLen(1))
+				Expect(report.Rejected()[0].Name()).To(Equal("test-container-01"))
+				Expect(report.Updated()).To(HaveLen(1))
+			})
+		})
+	})
+
+	When("watchtower has been instructed to only check the registry digests", func() {
+		It("should report containers that could not be checked as unknown and not update any containers", func() {
+			client := CreateMockClient(
+				&TestData{
+					Containers: []types.Container{
+						CreateMockContainer(
+							"test-container-01",
+							"test-container-01",
+							"fake-image1:latest",
+							time.Now()),
+						CreateMockContainer(
+							"test-container-02",
+							"test-container-02",
+							"fake-image2:latest",
+							time.Now()),
+					},
+					StalenessErrors: map[string]error{
+						"test-container-01": fmt.Errorf("%w: the local image has no repo digests", container.ErrUnknownStaleness),
+					},
+				},
+				false,
+				false,
+			)
+			report, err := actions.Update(client, types.UpdateParams{CheckOnly: true, Cleanup: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.Unknown()).To(HaveLen(1))
+			Expect(report.Unknown()[0].Name()).To(Equal("test-container-01"))
+			Expect(report.Unknown()[0].State()).To(Equal("Unknown"))
+			Expect(report.Stale()).To(HaveLen(1))
+			Expect(report.Updated()).To(BeEmpty())
+			Expect(client.TestData.TriedToRemoveImageCount).To(Equal(0))
+		})
+	})
+
+	When("a newer image is found for a container", func() {
+		It("should add the latest image metadata to the report", func() {
+			client := CreateMockClient(
+				&TestData{
+					Containers: []types.Container{
+						CreateMockContainer(
+							"test-container-01",
+							"test-container-01",
+							"fake-image1:latest",
+							time.Now()),
+					},
+					ImageMetadata: map[string]types.ImageMetadata{
+						"test-container-01": types.NewImageMetadata(map[string]string{
+							types.ImageVersionLabel: "1.25.4",
+						}),
+					},
+				},
+				false,
+				false,
+			)
+			report, err := actions.Update(client, types.UpdateParams{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.Updated()).To(HaveLen(1))
+			Expect(report.Updated()[0].LatestMetadata().Version).To(Equal("1.25.4"))
+		})
+		It("should add the image pull statistics to the report", func() {
+			client := CreateMockClient(
+				&TestData{
+					Containers: []types.Container{
+						CreateMockContainer(
+							"test-container-01",
+							"test-container-01",
+							"fake-image1:latest",
+							time.Now()),
+					},
+					PullStats: map[string]types.PullStats{
+						"test-container-01": {Bytes: 1024, Duration: time.Second},
+					},
+				},
+				false,
+				false,
+			)
+			report, err := actions.Update(client, types.UpdateParams{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.Updated()).To(HaveLen(1))
+			Expect(report.Updated()[0].PullStats()).To(Equal(types.PullStats{Bytes: 1024, Duration: time.Second}))
+		})
+		It("should add the digest reported by the registry to the report", func() {
+			client := CreateMockClient(
+				&TestData{
+					Containers: []types.Container{
+						CreateMockContainer(
+							"test-container-01",
+							"test-container-01",
+							"fake-image1:latest",
+							time.Now()),
+					},
+					RemoteDigests: map[string]string{
+						"test-container-01": "sha256:0123456789abcdef",
+					},
+					ImageMetadata: map[string]types.ImageMetadata{
+						"test-container-01": {Version: "1.25.4"},
+					},
+				},
+				false,
+				false,
+			)
+			report, err := actions.Update(client, types.UpdateParams{CheckOnly: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.Stale()).To(HaveLen(1))
+			Expect(report.Stale()[0].LatestDigest()).To(Equal("sha256:0123456789abcdef"))
+			Expect(report.Stale()[0].LatestMetadata().Version).To(Equal("1.25.4"))
+		})
+	})
+
+	When("an update session runs", func() {
+		It("should publish the session events", func() {
+			client := CreateMockClient(
+				&TestData{
+					Containers: []types.Container{
+						CreateMockContainer(
+							"test-container-01",
+							"test-container-01",
+							"fake-image1:latest",
+							time.Now()),
+					},
+					StartErrors: map[string]error{
+						"test-container-01": errors.New("failed to start"),
+					},
+				},
+				false,
+				false,
+			)
+			events, unsubscribe := session.Events().Subscribe()
+			defer unsubscribe()
+
+			_, err := actions.Update(client, types.UpdateParams{})
+			Expect(err).NotTo(HaveOccurred())
+
+			var published []session.Event
+			for len(events) > 0 {
+				published = append(published, <-events)
+			}
+			Expect(published).To(HaveLen(8))
+			Expect(published[0].Type).To(Equal(session.SessionStartedEvent))
+			Expect(published[1].Type).To(Equal(session.PullStartedEvent))
+			Expect(published[2].Type).To(Equal(session.PullFinishedEvent))
+			Expect(published[3].Type).To(Equal(session.ContainerCheckedEvent))
+			Expect(published[3].Container).To(Equal("test-container-01"))
+			Expect(published[3].Data).To(HaveKeyWithValue("state", "Stale"))
+			Expect(published[4].Type).To(Equal(session.ContainerStoppingEvent))
+			Expect(published[5].Type).To(Equal(session.ContainerCreatedEvent))
+			Expect(published[6].Type).To(Equal(session.ContainerFailedEvent))
+			Expect(published[6].Error).To(Equal("failed to start"))
+			Expect(published[7].Type).To(Equal(session.SessionFinishedEvent))
+			Expect(published[7].Data).To(HaveKeyWithValue("failed", 1))
+		})
+		It("should publish the output of the lifecycle hooks for the container", func() {
+			client := CreateMockClient(
+				&TestData{
+					Containers: []types.Container{
+						CreateMockContainerWithConfig(
+							"test-container-01",
+							"test-container-01",
+							"fake-image1:latest",
+							true,
+							false,
+							time.Now(),
+							&dockerContainer.Config{
+								Image: "fake-image1:latest",
+								Labels: map[string]string{
+									"com.centurylinklabs.watchtower.lifecycle.pre-update": "/PreUpdateReturn0.sh",
+								},
+								ExposedPorts: map[nat.Port]struct{}{},
+							}),
+					},
+					CommandOutputs: map[types.ContainerID]types.CommandOutput{
+						"test-container-01": {Output: "done", ExitCode: 0},
+					},
+				},
+				false,
+				false,
+			)
+			events, unsubscribe := session.Events().Subscribe()
+			defer unsubscribe()
+
+			_, err := actions.Update(client, types.UpdateParams{LifecycleHooks: true})
+			Expect(err).NotTo(HaveOccurred())
+
+			var hooks []session.Event
+			for len(events) > 0 {
+				if event := <-events; event.Type == session.HookOutputEvent {
+					hooks = append(hooks, event)
+				}
+			}
+			Expect(hooks).To(HaveLen(1))
+			Expect(hooks[0].Container).To(Equal("test-container-01"))
+			Expect(hooks[0].Image).To(Equal("fake-image1:latest"))
+			Expect(hooks[0].Data).To(HaveKeyWithValue("output", "done"))
+		})
+	})
+
+	When("an updated container fails to start", func() {
+		var testData *TestData
+		BeforeEach(func() {
+			testData = &TestData{
+				Containers: []types.Container{
+					CreateMockContainerWithConfig(
+						"test-container-01",
+						"test-container-01",
+						"fake-image1:latest",
+						true,
+						false,
+						time.Now(),
+						&dockerContainer.Config{
+							Labels:       map[string]string{},
+							ExposedPorts: map[nat.Port]struct{}{},
+						}),
+				},
+				ContainerLogs: map[string]string{
+					"test-container-01": "Error: configuration key \"port\" is required\n",
+				},
+			}
+		})
+		It("should add the tail of the container logs to the failed report", func() {
+			testData.StartErrors = map[string]error{"test-container-01": errors.New("failed to start")}
+			client := CreateMockClient(testData, false, false)
+			report, err := actions.Update(client, types.UpdateParams{FailureLogLines: 20})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.Failed()).To(HaveLen(1))
+			Expect(report.Failed()[0].Error()).To(Equal("failed to start"))
+			Expect(report.Failed()[0].Logs()).To(Equal("Error: configuration key \"port\" is required\n"))
+		})
+		It("should not fetch the logs when no log lines are requested", func() {
+			testData.StartErrors = map[string]error{"test-container-01": errors.New("failed to start")}
+			client := CreateMockClient(testData, false, false)
+			report, err := actions.Update(client, types.UpdateParams{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.Failed()).To(HaveLen(1))
+			Expect(report.Failed()[0].Logs()).To(BeEmpty())
+		})
+		It("should report containers that exited after starting as failed", func() {
+			testData.ExitErrors = map[string]error{
+				"test-container-01": fmt.Errorf("%w with exit code 1", container.ErrContainerExited),
+			}
+			client := CreateMockClient(testData, false, false)
+			report, err := actions.Update(client, types.UpdateParams{
+				FailureLogLines: 20,
+				ExitCheckDelay:  time.Millisecond,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.Failed()).To(HaveLen(1))
+			Expect(report.Failed()[0].Error()).To(Equal("container exited after starting with exit code 1"))
+			Expect(report.Failed()[0].Logs()).NotTo(BeEmpty())
+		})
+		It("should wait for the exit check delay once for all of the restarted containers", func() {
+			testData.Containers = append(testData.Containers, CreateMockContainerWithConfig(
+				"test-container-02",
+				"test-container-02",
+				"fake-image2:latest",
+				true,
+				false,
+				time.Now(),
+				&dockerContainer.Config{
+					Labels:       map[string]string{},
+					ExposedPorts: map[nat.Port]struct{}{},
+				}))
+			testData.ExitErrors = map[string]error{
+				"test-container-01": fmt.Errorf("%w with exit code 1", container.ErrContainerExited),
+				"test-container-02": fmt.Errorf("%w with exit code 1", container.ErrContainerExited),
+			}
+			client := CreateMockClient(testData, false, false)
+			start := time.Now()
+			report, err := actions.Update(client, types.UpdateParams{
+				ExitCheckDelay: 200 * time.Millisecond,
+				Cleanup:        true,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(time.Since(start)).To(BeNumerically("<", 400*time.Millisecond))
+			Expect(report.Failed()).To(HaveLen(2))
+			Expect(testData.TriedToRemoveImage()).To(BeFalse())
+		})
+		It("should not check whether the containers are running without an exit check delay", func() {
+			testData.ExitErrors = map[string]error{
+				"test-container-01": fmt.Errorf("%w with exit code 1", container.ErrContainerExited),
+			}
+			client := CreateMockClient(testData, false, false)
+			report, err := actions.Update(client, types.UpdateParams{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.Updated()).To(HaveLen(1))
+		})
+	})
+
 	When("watchtower has been instructed to run lifecycle hooks", func() {
 
 		When("pre-update script returns 1", func() {
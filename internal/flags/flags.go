@@ -44,6 +44,12 @@ func RegisterSystemFlags(rootCmd *cobra.Command) {
 		envString("WATCHTOWER_SCHEDULE"),
 		"The cron expression which defines when to update")
 
+	flags.StringP(
+		"scheduler-state-file",
+		"",
+		envString("WATCHTOWER_SCHEDULER_STATE_FILE"),
+		"The file used for keeping the scheduled updates paused across restarts")
+
 	flags.DurationP(
 		"stop-timeout",
 		"t",
@@ -117,6 +123,12 @@ func RegisterSystemFlags(rootCmd *cobra.Command) {
 		envBool("WATCHTOWER_MONITOR_ONLY"),
 		"Will only monitor for new images, not update the containers")
 
+	flags.BoolP(
+		"check-only",
+		"",
+		envBool("WATCHTOWER_CHECK_ONLY"),
+		"Will only check the registry digests for new images, without pulling images or updating the containers")
+
 	flags.BoolP(
 		"run-once",
 		"R",
@@ -153,6 +165,18 @@ func RegisterSystemFlags(rootCmd *cobra.Command) {
 		envBool("WATCHTOWER_ROLLING_RESTART"),
 		"Restart containers one at a time")
 
+	flags.DurationP(
+		"exit-check-delay",
+		"",
+		envDuration("WATCHTOWER_EXIT_CHECK_DELAY"),
+		"Time to wait after starting a container before checking that it is still running")
+
+	flags.IntP(
+		"failure-log-lines",
+		"",
+		envInt("WATCHTOWER_FAILURE_LOG_LINES"),
+		"Number of log lines of a container that failed to start to include in the notifications")
+
 	flags.BoolP(
 		"http-api-update",
 		"",
@@ -164,12 +188,114 @@ func RegisterSystemFlags(rootCmd *cobra.Command) {
 		envBool("WATCHTOWER_HTTP_API_METRICS"),
 		"Runs Watchtower with the Prometheus metrics API enabled")
 
+	flags.BoolP(
+		"http-api-containers",
+		"",
+		envBool("WATCHTOWER_HTTP_API_CONTAINERS"),
+		"Runs Watchtower with the read-only container status API enabled")
+
+	flags.BoolP(
+		"http-api-events",
+		"",
+		envBool("WATCHTOWER_HTTP_API_EVENTS"),
+		"Runs Watchtower with the session event stream API enabled")
+
+	flags.BoolP(
+		"http-api-scheduler",
+		"",
+		envBool("WATCHTOWER_HTTP_API_SCHEDULER"),
+		"Runs Watchtower with the API for pausing and resuming the scheduled updates enabled")
+
+	flags.BoolP(
+		"http-api-dashboard",
+		"",
+		envBool("WATCHTOWER_HTTP_API_DASHBOARD"),
+		"Runs Watchtower with the web dashboard enabled")
+
+	flags.BoolP(
+		"http-api-health",
+		"",
+		envBool("WATCHTOWER_HTTP_API_HEALTH"),
+		"Runs Watchtower with the health endpoint enabled, which is also used by --health-check")
+
+	flags.BoolP(
+		"http-api-webhook",
+		"",
+		envBool("WATCHTOWER_HTTP_API_WEBHOOK"),
+		"Runs Watchtower with the endpoint receiving registry push events enabled")
+
+	flags.StringP(
+		"http-api-webhook-secret",
+		"",
+		envString("WATCHTOWER_HTTP_API_WEBHOOK_SECRET"),
+		"The shared secret that registry push events must be signed or authenticated with")
+
 	flags.StringP(
 		"http-api-token",
 		"",
 		envString("WATCHTOWER_HTTP_API_TOKEN"),
 		"Sets an authentication token to HTTP API requests.")
 
+	flags.StringP(
+		"http-api-tokens-file",
+		"",
+		envString("WATCHTOWER_HTTP_API_TOKENS_FILE"),
+		"Path to a YAML, JSON or TOML file containing named API tokens and their scopes")
+
+	flags.StringP(
+		"http-api-audit-log",
+		"",
+		envString("WATCHTOWER_HTTP_API_AUDIT_LOG"),
+		"Path to a file that every request to the HTTP API is recorded in")
+
+	flags.StringP(
+		"http-api-host",
+		"",
+		envString("WATCHTOWER_HTTP_API_HOST"),
+		"The address that the HTTP API listens on, or empty to listen on all interfaces")
+
+	flags.IntP(
+		"http-api-port",
+		"",
+		envInt("WATCHTOWER_HTTP_API_PORT"),
+		"The port that the HTTP API listens on")
+
+	flags.StringP(
+		"http-api-socket",
+		"",
+		envString("WATCHTOWER_HTTP_API_SOCKET"),
+		"Path to a Unix socket that the HTTP API listens on, instead of a TCP port")
+
+	flags.StringP(
+		"http-api-tls-cert",
+		"",
+		envString("WATCHTOWER_HTTP_API_TLS_CERT"),
+		"Path to the PEM encoded certificate used for serving the HTTP API over HTTPS")
+
+	flags.StringP(
+		"http-api-tls-key",
+		"",
+		envString("WATCHTOWER_HTTP_API_TLS_KEY"),
+		"Path to the PEM encoded private key used for serving the HTTP API over HTTPS")
+
+	flags.StringP(
+		"http-api-tls-client-ca",
+		"",
+		envString("WATCHTOWER_HTTP_API_TLS_CLIENT_CA"),
+		"Path to a PEM encoded CA bundle, requiring HTTP API clients to present a certificate signed by one of the CAs")
+
+	flags.StringP(
+		"http-api-tls-client-cert",
+		"",
+		envString("WATCHTOWER_HTTP_API_TLS_CLIENT_CERT"),
+		"Path to the PEM encoded client certificate presented by the health check when client certificates are required")
+
+	flags.StringP(
+		"http-api-tls-client-key",
+		"",
+		envString("WATCHTOWER_HTTP_API_TLS_CLIENT_KEY"),
+		"Path to the PEM encoded private key of the client certificate presented by the health check")
+
 	flags.BoolP(
 		"http-api-periodic-polls",
 		"",
@@ -206,11 +332,41 @@ func RegisterSystemFlags(rootCmd *cobra.Command) {
 		false,
 		"Do health check and exit")
 
+	flags.DurationP(
+		"health-max-session-duration",
+		"",
+		envDuration("WATCHTOWER_HEALTH_MAX_SESSION_DURATION"),
+		"Duration after which a running update session is considered stuck, making watchtower unhealthy. 0 disables the check")
+
 	flags.BoolP(
 		"label-take-precedence",
 		"",
 		envBool("WATCHTOWER_LABEL_TAKE_PRECEDENCE"),
 		"Label applied to containers take precedence over arguments")
+
+	flags.StringSliceP(
+		"registry-mirror",
+		"",
+		envStringSlice("WATCHTOWER_REGISTRY_MIRROR"),
+		"Registry mirrors to use for digest checks and pulls, in the format registry=mirror. Example: docker.io=mirror.local:5000")
+
+	flags.BoolP(
+		"registry-mirror-fallback",
+		"",
+		envBool("WATCHTOWER_REGISTRY_MIRROR_FALLBACK"),
+		"Fall back to the upstream registry if the registry mirror cannot be used")
+
+	flags.BoolP(
+		"verify-signatures",
+		"",
+		envBool("WATCHTOWER_VERIFY_SIGNATURES"),
+		"Verify the signature of new images before updating containers, rejecting unsigned images")
+
+	flags.StringSliceP(
+		"signature-public-key",
+		"",
+		envStringSlice("WATCHTOWER_SIGNATURE_PUBLIC_KEY"),
+		"Paths to the PEM encoded public keys used for verifying image signatures")
 }
 
 // RegisterNotificationFlags that are used by watchtower to send notifications
@@ -361,11 +517,81 @@ Should only be used for testing.`)
 		envString("WATCHTOWER_NOTIFICATION_TEMPLATE"),
 		"The shoutrrr text/template for the messages")
 
+	flags.String(
+		"notification-template-file",
+		envString("WATCHTOWER_NOTIFICATION_TEMPLATE_FILE"),
+		"Path to a file containing the shoutrrr text/template for the messages, reloaded when the file changes")
+
+	flags.String(
+		"notification-html-template",
+		envString("WATCHTOWER_NOTIFICATION_HTML_TEMPLATE"),
+		"The html/template for the HTML part of email notifications, or \"default\" to use the built-in template")
+
 	flags.StringArray(
 		"notification-url",
 		envStringSlice("WATCHTOWER_NOTIFICATION_URL"),
 		"The shoutrrr URL to send notifications to")
 
+	flags.StringArray(
+		"notification-url-template",
+		envStringSlice("WATCHTOWER_NOTIFICATION_URL_TEMPLATE"),
+		"A template for the notifications sent to a shoutrrr URL or scheme, in the format \"url=template\"")
+
+	flags.String(
+		"notification-routes-file",
+		envString("WATCHTOWER_NOTIFICATION_ROUTES_FILE"),
+		"Path to a YAML, JSON or TOML file containing notification routing rules")
+
+	flags.StringArray(
+		"notification-route",
+		envStringSlice("WATCHTOWER_NOTIFICATION_ROUTE"),
+		"A notification route for containers labeled with notify-to, in the format \"name=url\"")
+
+	flags.String(
+		"notification-queue-file",
+		envString("WATCHTOWER_NOTIFICATION_QUEUE_FILE"),
+		"Path to a file keeping the notifications that failed to be delivered, to retry them")
+
+	flags.Int(
+		"notification-queue-size",
+		envInt("WATCHTOWER_NOTIFICATION_QUEUE_SIZE"),
+		"The maximum number of notifications to keep in the notification queue")
+
+	flags.String(
+		"notification-digest-schedule",
+		envString("WATCHTOWER_NOTIFICATION_DIGEST_SCHEDULE"),
+		"The cron expression which defines when to send a digest of the sessions, instead of notifying every session")
+
+	flags.String(
+		"notification-state-file",
+		envString("WATCHTOWER_NOTIFICATION_STATE_FILE"),
+		"Path to a file keeping track of notified containers, to suppress repeated notifications")
+
+	flags.Duration(
+		"notification-reminder-interval",
+		envDuration("WATCHTOWER_NOTIFICATION_REMINDER_INTERVAL"),
+		"Interval after which containers that are still stale are notified again, when using a notification state file")
+
+	flags.String(
+		"notification-webhook-url",
+		envString("WATCHTOWER_NOTIFICATION_WEBHOOK_URL"),
+		"The URL to post signed JSON webhook notifications to")
+
+	flags.String(
+		"notification-webhook-secret",
+		envString("WATCHTOWER_NOTIFICATION_WEBHOOK_SECRET"),
+		"The secret used for signing the webhook notifications using HMAC-SHA256")
+
+	flags.StringArray(
+		"notification-webhook-header",
+		envStringSlice("WATCHTOWER_NOTIFICATION_WEBHOOK_HEADER"),
+		"Custom headers to add to the webhook notification requests, in the format \"Name: Value\"")
+
+	flags.Int(
+		"notification-webhook-retries",
+		envInt("WATCHTOWER_NOTIFICATION_WEBHOOK_RETRIES"),
+		"The number of times to retry sending a webhook notification that failed")
+
 	flags.Bool("notification-report",
 		envBool("WATCHTOWER_NOTIFICATION_REPORT"),
 		"Use the session report as the notification template data")
@@ -428,6 +654,11 @@ func SetDefaults() {
 	viper.SetDefault("WATCHTOWER_NOTIFICATION_EMAIL_SERVER_PORT", 25)
 	viper.SetDefault("WATCHTOWER_NOTIFICATION_EMAIL_SUBJECTTAG", "")
 	viper.SetDefault("WATCHTOWER_NOTIFICATION_SLACK_IDENTIFIER", "watchtower")
+	viper.SetDefault("WATCHTOWER_NOTIFICATION_WEBHOOK_RETRIES", 3)
+	viper.SetDefault("WATCHTOWER_NOTIFICATION_QUEUE_SIZE", 100)
+	viper.SetDefault("WATCHTOWER_FAILURE_LOG_LINES", 20)
+	viper.SetDefault("WATCHTOWER_HTTP_API_PORT", 8080)
+	viper.SetDefault("WATCHTOWER_HEALTH_MAX_SESSION_DURATION", time.Hour)
 	viper.SetDefault("WATCHTOWER_LOG_LEVEL", "info")
 	viper.SetDefault("WATCHTOWER_LOG_FORMAT", "auto")
 }
@@ -518,7 +749,9 @@ func GetSecretsFromFiles(rootCmd *cobra.Command) {
 		"notification-msteams-hook",
 		"notification-gotify-token",
 		"notification-url",
+		"notification-webhook-secret",
 		"http-api-token",
+		"http-api-webhook-secret",
 	}
 	for _, secret := range secrets {
 		if err := getSecretFromFile(flags, secret); err != nil {
@@ -1,8 +1,16 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -11,45 +19,155 @@ const tokenMissingMsg = "api token is empty or has not been set. exiting"
 
 // API is the http server responsible for serving the HTTP API endpoints
 type API struct {
-	Token       string
+	// Token is granted all the scopes, under the name "default"
+	Token string
+	// Tokens are the named tokens, granting only their own scopes
+	Tokens      []Token
+	Audit       *AuditLog
+	Server      ServerConfig
 	hasHandlers bool
+	// requireToken is set once an endpoint requiring a token has been registered
+	requireToken bool
+	mux          *http.ServeMux
+	mutex        sync.Mutex
+	server       *http.Server
+	stopOnce     sync.Once
+	stopped      chan struct{}
+	onShutdown   []func()
 }
 
 // New is a factory function creating a new API instance
 func New(token string) *API {
 	return &API{
 		Token:       token,
+		Server:      ServerConfig{Port: DefaultPort},
 		hasHandlers: false,
+		mux:         http.NewServeMux(),
+		stopped:     make(chan struct{}),
 	}
 }
 
 // RequireToken is wrapper around http.HandleFunc that checks token validity
 func (api *API) RequireToken(fn http.HandlerFunc) http.HandlerFunc {
+	return api.RequireScope("", fn)
+}
+
+// RequireScope is wrapper around http.HandleFunc that checks that the token is valid and grants the scope. The token
+// is added to the request context, see TokenFromRequest, and the request is recorded in the audit log.
+func (api *API) RequireScope(scope Scope, fn http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		auth := r.Header.Get("Authorization")
-		want := fmt.Sprintf("Bearer %s", api.Token)
-		if auth != want {
+		token, found := api.authenticate(r)
+		if !found {
+			api.Audit.Record(newAuditEntry(r, "", "unauthorized"))
 			w.WriteHeader(http.StatusUnauthorized)
 			return
 		}
+		if !token.HasScope(scope) {
+			api.Audit.Record(newAuditEntry(r, token.Name, "forbidden"))
+			http.Error(w, fmt.Sprintf("the token does not grant the %q scope", scope), http.StatusForbidden)
+			return
+		}
+		api.Audit.Record(newAuditEntry(r, token.Name, "allowed"))
 		log.Debug("Valid token found.")
-		fn(w, r)
+		fn(w, withToken(r, token))
+	}
+}
+
+// authenticate returns the token matching the bearer token of the request
+func (api *API) authenticate(r *http.Request) (Token, bool) {
+	value, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found {
+		return Token{}, false
+	}
+	for _, token := range api.tokens() {
+		if token.matches(value) {
+			return token, true
+		}
+	}
+	return Token{}, false
+}
+
+// tokens returns all the tokens that grant access to the API
+func (api *API) tokens() []Token {
+	if api.Token == "" {
+		return api.Tokens
 	}
+	return append([]Token{{Name: defaultTokenName, Token: api.Token, Scopes: []Scope{ScopeAll}}}, api.Tokens...)
 }
 
-// RegisterFunc is a wrapper around http.HandleFunc that also sets the flag used to determine whether to launch the API
-func (api *API) RegisterFunc(path string, fn http.HandlerFunc) {
+// RegisterFunc is a wrapper around http.HandleFunc that also sets the flag used to determine whether to launch the API.
+// Requests must use a token granting the scope.
+func (api *API) RegisterFunc(path string, scope Scope, fn http.HandlerFunc) {
 	api.hasHandlers = true
-	http.HandleFunc(path, api.RequireToken(fn))
+	api.requireToken = true
+	api.mux.HandleFunc(path, api.RequireScope(scope, fn))
 }
 
-// RegisterHandler is a wrapper around http.Handler that also sets the flag used to determine whether to launch the API
-func (api *API) RegisterHandler(path string, handler http.Handler) {
+// RegisterHandler is a wrapper around http.Handler that also sets the flag used to determine whether to launch the API.
+// Requests must use a token granting the scope.
+func (api *API) RegisterHandler(path string, scope Scope, handler http.Handler) {
 	api.hasHandlers = true
-	http.Handle(path, api.RequireToken(handler.ServeHTTP))
+	api.requireToken = true
+	api.mux.Handle(path, api.RequireScope(scope, handler.ServeHTTP))
+}
+
+// RegisterExternalFunc is a wrapper around http.HandleFunc for handlers that authenticate the requests themselves, such
+// as webhooks called by external services using a shared secret. The requests are recorded in the audit log under the
+// name, with the result derived from the response status.
+func (api *API) RegisterExternalFunc(path string, name string, fn http.HandlerFunc) {
+	api.hasHandlers = true
+	api.mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		fn(recorder, r)
+		switch recorder.status {
+		case http.StatusUnauthorized:
+			api.Audit.Record(newAuditEntry(r, name, "unauthorized"))
+		case http.StatusForbidden:
+			api.Audit.Record(newAuditEntry(r, name, "forbidden"))
+		default:
+			api.Audit.Record(newAuditEntry(r, name, "allowed"))
+		}
+	})
+}
+
+// RegisterPublicHandler is a wrapper around http.Handler for content that is served without a token, as it does not
+// expose any sensitive data, such as the static files of the dashboard or the health status
+func (api *API) RegisterPublicHandler(path string, handler http.Handler) {
+	api.hasHandlers = true
+	api.mux.Handle(path, handler)
+}
+
+// HasHandlers returns whether any endpoints have been registered, in which case the API is started
+func (api *API) HasHandlers() bool {
+	return api.hasHandlers
+}
+
+// statusRecorder is a http.ResponseWriter keeping track of the response status
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader implements http.ResponseWriter
+func (recorder *statusRecorder) WriteHeader(status int) {
+	recorder.status = status
+	recorder.ResponseWriter.WriteHeader(status)
 }
 
-// Start the API and serve over HTTP. Requires an API Token to be set.
+// OnShutdown registers a function that is called when the API starts shutting down, for ending long-running requests
+// that would otherwise hold up the shutdown
+func (api *API) OnShutdown(fn func()) {
+	api.onShutdown = append(api.onShutdown, fn)
+}
+
+// Handler returns the handler serving all the registered API endpoints
+func (api *API) Handler() http.Handler {
+	return api.mux
+}
+
+// Start the API and serve over HTTP. Requires an API Token to be set, unless only public endpoints are registered.
+// The API is shut down gracefully on SIGINT and SIGTERM. When blocking, Start returns http.ErrServerClosed once the API
+// has been shut down.
 func (api *API) Start(block bool) error {
 
 	if !api.hasHandlers {
@@ -57,20 +175,89 @@ func (api *API) Start(block bool) error {
 		return nil
 	}
 
-	if api.Token == "" {
+	if api.requireToken && len(api.tokens()) == 0 {
 		log.Fatal(tokenMissingMsg)
 	}
 
+	tlsConfig, err := api.Server.tlsConfig()
+	if err != nil {
+		return err
+	}
+	server := &http.Server{
+		Handler:           api.mux,
+		TLSConfig:         tlsConfig,
+		ReadHeaderTimeout: readHeaderTimeout,
+		IdleTimeout:       idleTimeout,
+		// No write timeout is used, as update requests are only answered once the update has finished
+	}
+	for _, fn := range api.onShutdown {
+		server.RegisterOnShutdown(fn)
+	}
+	api.mutex.Lock()
+	api.server = server
+	api.mutex.Unlock()
+
+	listener, err := api.Server.listen()
+	if err != nil {
+		return err
+	}
+	go api.stopOnSignal()
+
 	if block {
-		runHTTPServer()
-	} else {
-		go func() {
-			runHTTPServer()
-		}()
+		err := api.serve(server, listener)
+		if errors.Is(err, http.ErrServerClosed) {
+			<-api.stopped
+		}
+		return err
 	}
+
+	go func() {
+		if err := api.serve(server, listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
 	return nil
 }
 
-func runHTTPServer() {
-	log.Fatal(http.ListenAndServe(":8080", nil))
+func (api *API) serve(server *http.Server, listener net.Listener) error {
+	if server.TLSConfig != nil {
+		// The certificates are already part of the TLS config
+		return server.ServeTLS(listener, "", "")
+	}
+	return server.Serve(listener)
+}
+
+func (api *API) stopOnSignal() {
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+
+	select {
+	case <-interrupt:
+		if err := api.Stop(); err != nil {
+			log.WithError(err).Warn("Failed to shut down the HTTP API gracefully")
+		}
+	case <-api.stopped:
+	}
+}
+
+// Stop shuts down the API gracefully, waiting for the active requests to finish
+func (api *API) Stop() error {
+	api.mutex.Lock()
+	server := api.server
+	api.mutex.Unlock()
+	if server == nil {
+		return nil
+	}
+
+	var err error
+	api.stopOnce.Do(func() {
+		log.Debug("Shutting down the HTTP API.")
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		err = server.Shutdown(ctx)
+		close(api.stopped)
+	})
+	<-api.stopped
+	return err
 }
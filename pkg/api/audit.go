@@ -0,0 +1,107 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// AuditEntry is the audit log record of a request to the API
+type AuditEntry struct {
+	Time   time.Time `json:"time"`
+	Token  string    `json:"token,omitempty"`
+	Method string    `json:"method"`
+	URI    string    `json:"uri"`
+	Remote string    `json:"remote"`
+	// Result is either "allowed", "unauthorized" for requests without a valid token, or "forbidden" for requests
+	// using a token that lacks the required scope
+	Result string `json:"result"`
+}
+
+// AuditLog records the requests to the API, one JSON object per line
+type AuditLog struct {
+	mutex  sync.Mutex
+	writer io.Writer
+}
+
+// NewAuditLog creates an AuditLog appending to the file at path
+func NewAuditLog(path string) (*AuditLog, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the API audit log: %w", err)
+	}
+	return &AuditLog{writer: file}, nil
+}
+
+// NewAuditLogWriter creates an AuditLog writing to the writer
+func NewAuditLogWriter(writer io.Writer) *AuditLog {
+	return &AuditLog{writer: writer}
+}
+
+// Record writes the entry to the audit log. Without an audit log, the entry is only logged at debug level.
+func (audit *AuditLog) Record(entry AuditEntry) {
+	if audit == nil {
+		log.WithFields(log.Fields{
+			"token":  entry.Token,
+			"method": entry.Method,
+			"uri":    entry.URI,
+			"remote": entry.Remote,
+			"result": entry.Result,
+		}).Debug("HTTP API request")
+		return
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	audit.mutex.Lock()
+	defer audit.mutex.Unlock()
+	if _, err := audit.writer.Write(append(line, '\n')); err != nil {
+		log.WithError(err).Warn("Failed to write to the HTTP API audit log")
+	}
+}
+
+// sensitiveParams are the query parameters that are masked in the audit log, as they may carry secrets sent by
+// clients that cannot set headers
+var sensitiveParams = []string{"secret", "token", "access_token"}
+
+func newAuditEntry(r *http.Request, token string, result string) AuditEntry {
+	return AuditEntry{
+		Time:   time.Now(),
+		Token:  token,
+		Method: r.Method,
+		URI:    redactedURI(r.URL),
+		Remote: r.RemoteAddr,
+		Result: result,
+	}
+}
+
+// redactedURI returns the request URI of the URL, with the values of the sensitive query parameters masked
+func redactedURI(u *url.URL) string {
+	query := u.Query()
+	redacted := false
+	for _, param := range sensitiveParams {
+		if values, found := query[param]; found {
+			for i := range values {
+				values[i] = "REDACTED"
+			}
+			redacted = true
+		}
+	}
+	if !redacted {
+		return u.RequestURI()
+	}
+
+	masked := *u
+	masked.RawQuery = query.Encode()
+	return masked.RequestURI()
+}
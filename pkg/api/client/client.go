@@ -0,0 +1,269 @@
+// Package client is a Go client for the watchtower HTTP API, as described by the OpenAPI document served at
+// /v1/openapi.json
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxErrorLength is the maximum number of bytes of an error response that are included in the error
+const maxErrorLength = 4 * 1024
+
+// Error is returned when the API responds with a status other than the ones expected for the request
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+// Error implements error
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("watchtower API request failed: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("watchtower API request failed: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Client sends requests to the watchtower HTTP API
+type Client struct {
+	// BaseURL is the URL that the API is served at, without the /v1 path
+	BaseURL string
+	// Token is sent as the bearer token, unless it is empty
+	Token string
+	// HTTPClient is used for sending the requests. Requests that wait for update jobs can take as long as the update
+	// session, so its timeout should allow for that.
+	HTTPClient *http.Client
+}
+
+// New is a factory function creating a new Client instance, sending the requests to the API at the base URL
+func New(baseURL string, token string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		Token:      token,
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+// UpdateOptions select the containers that are updated, as the containers matching both the images and the
+// container names. Empty lists do not restrict the containers.
+type UpdateOptions struct {
+	// Images are the images to update, given without the tag
+	Images     []string
+	Containers []string
+	// Wait delays the response until the job is done
+	Wait bool
+}
+
+// Update queues an update of the containers selected by the options, and returns the job performing it
+func (c *Client) Update(ctx context.Context, options UpdateOptions) (Job, error) {
+	query := url.Values{}
+	for _, image := range options.Images {
+		query.Add("image", image)
+	}
+	for _, container := range options.Containers {
+		query.Add("container", container)
+	}
+	if options.Wait {
+		query.Set("wait", "true")
+	}
+
+	var job Job
+	err := c.do(ctx, http.MethodPost, "/v1/update", query, &job, http.StatusOK, http.StatusAccepted)
+	return job, err
+}
+
+// Job returns the update job with the ID, waiting for it to be done if wait is set
+func (c *Client) Job(ctx context.Context, id string, wait bool) (Job, error) {
+	query := url.Values{}
+	if wait {
+		query.Set("wait", "true")
+	}
+
+	var job Job
+	err := c.do(ctx, http.MethodGet, "/v1/jobs/"+url.PathEscape(id), query, &job, http.StatusOK)
+	return job, err
+}
+
+// Containers returns the status of the monitored containers, sorted by name
+func (c *Client) Containers(ctx context.Context) ([]Container, error) {
+	var list struct {
+		Containers []Container `json:"containers"`
+	}
+	err := c.do(ctx, http.MethodGet, "/v1/containers", nil, &list, http.StatusOK)
+	return list.Containers, err
+}
+
+// Container returns the status of the monitored container with the name
+func (c *Client) Container(ctx context.Context, name string) (Container, error) {
+	var container Container
+	err := c.do(ctx, http.MethodGet, "/v1/containers/"+url.PathEscape(name), nil, &container, http.StatusOK)
+	return container, err
+}
+
+// Sessions returns the recent update sessions, most recent first
+func (c *Client) Sessions(ctx context.Context) ([]Session, error) {
+	var list struct {
+		Sessions []Session `json:"sessions"`
+	}
+	err := c.do(ctx, http.MethodGet, "/v1/sessions", nil, &list, http.StatusOK)
+	return list.Sessions, err
+}
+
+// Scheduler returns whether the scheduled updates are paused
+func (c *Client) Scheduler(ctx context.Context) (SchedulerState, error) {
+	var state SchedulerState
+	err := c.do(ctx, http.MethodGet, "/v1/scheduler", nil, &state, http.StatusOK)
+	return state, err
+}
+
+// PauseOptions set how long the scheduled updates are paused. At most one of Duration and Until can be given, and the
+// pause lasts until it is resumed if neither is.
+type PauseOptions struct {
+	Reason   string
+	Duration time.Duration
+	Until    time.Time
+}
+
+// Pause pauses the scheduled updates
+func (c *Client) Pause(ctx context.Context, options PauseOptions) (SchedulerState, error) {
+	query := url.Values{}
+	if options.Reason != "" {
+		query.Set("reason", options.Reason)
+	}
+	if options.Duration != 0 {
+		query.Set("duration", options.Duration.String())
+	}
+	if !options.Until.IsZero() {
+		query.Set("until", options.Until.Format(time.RFC3339))
+	}
+
+	var state SchedulerState
+	err := c.do(ctx, http.MethodPost, "/v1/scheduler/pause", query, &state, http.StatusOK)
+	return state, err
+}
+
+// Resume resumes the scheduled updates
+func (c *Client) Resume(ctx context.Context) (SchedulerState, error) {
+	var state SchedulerState
+	err := c.do(ctx, http.MethodPost, "/v1/scheduler/resume", nil, &state, http.StatusOK)
+	return state, err
+}
+
+// Health returns the health of watchtower. An unhealthy instance is not reported as an error, see Health.Healthy.
+func (c *Client) Health(ctx context.Context) (Health, error) {
+	var health Health
+	err := c.do(ctx, http.MethodGet, "/v1/health", nil, &health, http.StatusOK, http.StatusServiceUnavailable)
+	return health, err
+}
+
+// Metrics returns the metrics in the Prometheus text format
+func (c *Client) Metrics(ctx context.Context) (string, error) {
+	res, err := c.send(ctx, http.MethodGet, "/v1/metrics", nil)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if err := checkStatus(res, http.StatusOK); err != nil {
+		return "", err
+	}
+	body, err := io.ReadAll(res.Body)
+	return string(body), err
+}
+
+// Events streams the session events to fn, until the context is cancelled or the stream is closed by the API. The
+// events are read using the HTTP client, so its timeout must not be set for long-running streams.
+func (c *Client) Events(ctx context.Context, fn func(Event)) error {
+	res, err := c.send(ctx, http.MethodGet, "/v1/events", nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if err := checkStatus(res, http.StatusOK); err != nil {
+		return err
+	}
+
+	var data strings.Builder
+	scanner := bufio.NewScanner(res.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			// An empty line ends the event, while comments used to keep the connection alive carry no data
+			if data.Len() > 0 {
+				var event Event
+				if err := json.Unmarshal([]byte(data.String()), &event); err != nil {
+					return fmt.Errorf("failed to decode the event: %w", err)
+				}
+				fn(event)
+				data.Reset()
+			}
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// do sends the request and decodes the JSON response into v, if the response has one of the expected statuses
+func (c *Client) do(ctx context.Context, method string, path string, query url.Values, v interface{}, expected ...int) error {
+	res, err := c.send(ctx, method, path, query)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if err := checkStatus(res, expected...); err != nil {
+		return err
+	}
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode the response: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) send(ctx context.Context, method string, path string, query url.Values) (*http.Response, error) {
+	target := c.BaseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return client.Do(req)
+}
+
+// checkStatus returns an Error containing the response body if the response status is not one of the expected ones
+func checkStatus(res *http.Response, expected ...int) error {
+	for _, status := range expected {
+		if res.StatusCode == status {
+			return nil
+		}
+	}
+	body, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorLength))
+	return &Error{StatusCode: res.StatusCode, Message: strings.TrimSpace(string(body))}
+}
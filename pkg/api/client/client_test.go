@@ -0,0 +1,246 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	dockerContainer "github.com/docker/docker/api/types/container"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/containrrr/watchtower/internal/actions/mocks"
+	"github.com/containrrr/watchtower/pkg/api"
+	"github.com/containrrr/watchtower/pkg/api/client"
+	"github.com/containrrr/watchtower/pkg/api/containers"
+	"github.com/containrrr/watchtower/pkg/api/events"
+	apiHealth "github.com/containrrr/watchtower/pkg/api/health"
+	apiMetrics "github.com/containrrr/watchtower/pkg/api/metrics"
+	"github.com/containrrr/watchtower/pkg/api/scheduler"
+	"github.com/containrrr/watchtower/pkg/api/sessions"
+	"github.com/containrrr/watchtower/pkg/api/update"
+	"github.com/containrrr/watchtower/pkg/filters"
+	"github.com/containrrr/watchtower/pkg/health"
+	"github.com/containrrr/watchtower/pkg/pause"
+	"github.com/containrrr/watchtower/pkg/session"
+	"github.com/containrrr/watchtower/pkg/types"
+)
+
+const token = "123123123"
+
+func TestClient(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "API Client Suite")
+}
+
+var _ = Describe("the API client", func() {
+	var server *httptest.Server
+	var eventsHandler *events.Handler
+	var sessionsHandler *sessions.Handler
+	var monitor *health.Monitor
+	var targets chan update.Target
+	var c *client.Client
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		targets = make(chan update.Target, 10)
+		queue := update.NewQueue(func(target update.Target) (types.Report, error) {
+			targets <- target
+			return mocks.CreateMockProgressReport(session.UpdatedState, session.FailedState), nil
+		}, nil)
+
+		running := mocks.CreateMockContainerWithConfig(
+			"test-container-01",
+			"/test-container-01",
+			"fake-image1:latest",
+			true,
+			false,
+			time.Now(),
+			&dockerContainer.Config{Image: "fake-image1:latest", Labels: map[string]string{}})
+		dockerClient := mocks.CreateMockClient(&mocks.TestData{Containers: []types.Container{running}}, false, false)
+
+		httpAPI := api.New(token)
+		updateHandler := update.NewWithQueue(queue)
+		httpAPI.RegisterFunc(updateHandler.Path, api.ScopeUpdateTrigger, updateHandler.Handle)
+		httpAPI.RegisterFunc(updateHandler.JobsPath, api.ScopeUpdateTrigger, updateHandler.HandleJob)
+		containersHandler := containers.New(dockerClient, filters.NoFilter, types.UpdateParams{})
+		httpAPI.RegisterFunc(containersHandler.Path, api.ScopeStatusRead, containersHandler.Handle)
+		httpAPI.RegisterFunc(containersHandler.Path+"/", api.ScopeStatusRead, containersHandler.Handle)
+		eventsHandler = events.New(session.Events())
+		httpAPI.RegisterFunc(eventsHandler.Path, api.ScopeStatusRead, eventsHandler.Handle)
+		sessionsHandler = sessions.New()
+		httpAPI.RegisterFunc(sessionsHandler.Path, api.ScopeStatusRead, sessionsHandler.Handle)
+		schedulerHandler := scheduler.New(pause.NewStore(""))
+		httpAPI.RegisterFunc(schedulerHandler.Path, api.ScopeStatusRead, schedulerHandler.Handle)
+		httpAPI.RegisterFunc(schedulerHandler.Path+"/", api.ScopeSchedulerWrite, schedulerHandler.Handle)
+		metricsHandler := apiMetrics.New()
+		httpAPI.RegisterHandler(metricsHandler.Path, api.ScopeMetricsRead, metricsHandler.Handle)
+		monitor = health.NewMonitor(time.Hour)
+		healthHandler := apiHealth.New(monitor)
+		httpAPI.RegisterPublicHandler(healthHandler.Path, healthHandler)
+
+		server = httptest.NewServer(httpAPI.Handler())
+		c = client.New(server.URL+"/", token)
+	})
+
+	AfterEach(func() {
+		eventsHandler.Close()
+		server.Close()
+	})
+
+	Describe("triggering updates", func() {
+		It("should queue an update of the selected containers", func() {
+			job, err := c.Update(ctx, client.UpdateOptions{Images: []string{"mock/updt1"}, Containers: []string{"updt1"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(job.ID).NotTo(BeEmpty())
+			Expect(job.Images).To(Equal([]string{"mock/updt1"}))
+			Expect(job.Containers).To(Equal([]string{"updt1"}))
+			Expect(<-targets).To(Equal(update.Target{Images: []string{"mock/updt1"}, Containers: []string{"updt1"}}))
+
+			job, err = c.Job(ctx, job.ID, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(job.Done()).To(BeTrue())
+			Expect(job.Succeeded).To(BeFalse())
+			Expect(job.Report.Updated).To(HaveLen(1))
+			Expect(job.Report.Failed).To(HaveLen(1))
+			Expect(job.Report.Failed[0].Name).To(Equal("fail1"))
+			Expect(job.Report.Failed[0].Error).NotTo(BeEmpty())
+		})
+
+		It("should wait for the update of all containers", func() {
+			job, err := c.Update(ctx, client.UpdateOptions{Wait: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(job.Done()).To(BeTrue())
+			Expect(job.Images).To(BeNil())
+			Expect(job.Containers).To(BeNil())
+			Expect(job.Finished).NotTo(BeZero())
+		})
+
+		It("should return an error for unknown jobs", func() {
+			_, err := c.Job(ctx, "missing", false)
+			var apiErr *client.Error
+			Expect(errors.As(err, &apiErr)).To(BeTrue())
+			Expect(apiErr.StatusCode).To(Equal(http.StatusNotFound))
+			Expect(apiErr.Message).To(Equal(update.ErrJobNotFound.Error()))
+		})
+	})
+
+	It("should return an error for invalid tokens", func() {
+		c.Token = "invalid"
+		_, err := c.Containers(ctx)
+		var apiErr *client.Error
+		Expect(errors.As(err, &apiErr)).To(BeTrue())
+		Expect(apiErr.StatusCode).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should read the container status", func() {
+		list, err := c.Containers(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list).To(HaveLen(1))
+		Expect(list[0].Name).To(Equal("test-container-01"))
+		Expect(list[0].Running).To(BeTrue())
+
+		container, err := c.Container(ctx, "test-container-01")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(container.Image).To(Equal("fake-image1:latest"))
+		Expect(container.LastCheck).To(BeNil())
+	})
+
+	It("should read the recent sessions", func() {
+		sessionsHandler.RecordSession(mocks.CreateMockProgressReport(session.UpdatedState), time.Now(), time.Now(), nil)
+		list, err := c.Sessions(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list).To(HaveLen(1))
+		Expect(list[0].Containers).To(ConsistOf(client.SessionContainer{
+			Name:  "updt1",
+			Image: "mock/updt1:latest",
+			State: "Updated",
+		}))
+	})
+
+	It("should pause and resume the scheduled updates", func() {
+		state, err := c.Pause(ctx, client.PauseOptions{Reason: "maintenance", Duration: time.Hour})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state.Paused).To(BeTrue())
+		Expect(state.Reason).To(Equal("maintenance"))
+		Expect(state.Until).To(BeTemporally("~", time.Now().Add(time.Hour), time.Minute))
+
+		state, err = c.Scheduler(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state.Paused).To(BeTrue())
+
+		state, err = c.Resume(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state.Paused).To(BeFalse())
+
+		_, err = c.Pause(ctx, client.PauseOptions{Duration: time.Hour, Until: time.Now().Add(time.Hour)})
+		var apiErr *client.Error
+		Expect(errors.As(err, &apiErr)).To(BeTrue())
+		Expect(apiErr.StatusCode).To(Equal(http.StatusBadRequest))
+	})
+
+	It("should read the health without a token", func() {
+		c.Token = ""
+		monitor.RecordPing(errors.New("connection refused"), time.Now())
+		status, err := c.Health(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Healthy).To(BeFalse())
+		Expect(status.Status).To(Equal("unhealthy"))
+		Expect(status.Checks[health.DaemonCheck].Message).To(ContainSubstring("connection refused"))
+
+		monitor.RecordPing(nil, time.Now())
+		status, err = c.Health(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Healthy).To(BeTrue())
+		Expect(status.Status).To(Equal("healthy"))
+	})
+
+	It("should read the metrics", func() {
+		metrics, err := c.Metrics(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(metrics).To(ContainSubstring("watchtower_containers_updated"))
+	})
+
+	It("should stream the session events", func() {
+		received := make(chan client.Event, 10)
+		done := make(chan error, 1)
+		go func() {
+			done <- c.Events(ctx, func(event client.Event) {
+				received <- event
+			})
+		}()
+
+		// The stream only includes the events published once it has been opened
+		Eventually(func() int {
+			session.PublishEvent(session.Event{Type: session.PullStartedEvent, Container: "test-container-01"})
+			return len(received)
+		}).ShouldNot(BeZero())
+		event := <-received
+		Expect(event.Type).To(Equal(string(session.PullStartedEvent)))
+		Expect(event.Container).To(Equal("test-container-01"))
+
+		eventsHandler.Close()
+		Eventually(done).Should(Receive(BeNil()))
+	})
+
+	It("should end the event stream when the context is cancelled", func() {
+		ctx, cancel := context.WithCancel(ctx)
+		done := make(chan error, 1)
+		go func() {
+			done <- c.Events(ctx, func(client.Event) {})
+		}()
+		cancel()
+		Eventually(done).Should(Receive(MatchError(context.Canceled)))
+	})
+
+	It("should describe the API errors", func() {
+		err := &client.Error{StatusCode: http.StatusForbidden, Message: "the token does not grant the scope"}
+		Expect(err.Error()).To(Equal("watchtower API request failed: 403 Forbidden: the token does not grant the scope"))
+		Expect(strings.HasSuffix((&client.Error{StatusCode: http.StatusUnauthorized}).Error(), "401 Unauthorized")).To(BeTrue())
+	})
+})
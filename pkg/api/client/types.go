@@ -0,0 +1,153 @@
+package client
+
+import "time"
+
+// The states that an update job goes through
+const (
+	JobQueued  = "queued"
+	JobRunning = "running"
+	JobDone    = "done"
+)
+
+// Job is an update session triggered using the API
+type Job struct {
+	ID    string `json:"id"`
+	State string `json:"state"`
+	// Images are the images that are updated, or nil for all images
+	Images []string `json:"images"`
+	// Containers are the names of the containers that are updated, or nil for all containers
+	Containers []string  `json:"containers"`
+	Created    time.Time `json:"created"`
+	Started    time.Time `json:"started,omitempty"`
+	Finished   time.Time `json:"finished,omitempty"`
+	// Succeeded is whether the job completed without any of the containers failing to update
+	Succeeded bool    `json:"succeeded,omitempty"`
+	Error     string  `json:"error,omitempty"`
+	Report    *Report `json:"report,omitempty"`
+}
+
+// Done returns whether the job has finished
+func (job Job) Done() bool {
+	return job.State == JobDone
+}
+
+// Report contains the containers of an update session, grouped by their outcome
+type Report struct {
+	Scanned  []ContainerReport `json:"scanned"`
+	Updated  []ContainerReport `json:"updated"`
+	Failed   []ContainerReport `json:"failed"`
+	Skipped  []ContainerReport `json:"skipped"`
+	Stale    []ContainerReport `json:"stale"`
+	Fresh    []ContainerReport `json:"fresh"`
+	Rejected []ContainerReport `json:"rejected"`
+	Unknown  []ContainerReport `json:"unknown"`
+}
+
+// ContainerReport is the outcome of an update session for a single container
+type ContainerReport struct {
+	ID             string         `json:"id"`
+	Name           string         `json:"name"`
+	CurrentImageID string         `json:"currentImageId"`
+	LatestImageID  string         `json:"latestImageId"`
+	ImageName      string         `json:"imageName"`
+	State          string         `json:"state"`
+	Error          string         `json:"error,omitempty"`
+	CurrentImage   *ImageMetadata `json:"currentImage,omitempty"`
+	LatestImage    *ImageMetadata `json:"latestImage,omitempty"`
+	Pull           *PullStats     `json:"pull,omitempty"`
+	Logs           string         `json:"logs,omitempty"`
+	// LatestDigest is the digest of the newer image reported by the registry, for containers checked without pulling
+	LatestDigest string `json:"latestDigest,omitempty"`
+}
+
+// ImageMetadata is the version information read from the labels of an image
+type ImageMetadata struct {
+	Version  string `json:"version"`
+	Revision string `json:"revision"`
+	Source   string `json:"source"`
+}
+
+// PullStats describes the pull of the latest image of a container
+type PullStats struct {
+	Bytes int64 `json:"bytes"`
+	// Duration is the pull duration in seconds
+	Duration float64 `json:"duration"`
+}
+
+// Container is the current status of a monitored container
+type Container struct {
+	Name        string `json:"name"`
+	ID          string `json:"id"`
+	Running     bool   `json:"running"`
+	Image       string `json:"image"`
+	ImageID     string `json:"imageId"`
+	ImageDigest string `json:"imageDigest,omitempty"`
+	// Stale is whether a newer image was found, that the container has not been recreated from yet
+	Stale       bool             `json:"stale"`
+	MonitorOnly bool             `json:"monitorOnly"`
+	NoPull      bool             `json:"noPull"`
+	Scope       string           `json:"scope,omitempty"`
+	LastCheck   *ContainerResult `json:"lastCheck"`
+	LastUpdate  *ContainerResult `json:"lastUpdate"`
+}
+
+// ContainerResult is the outcome of a check or an update of a container
+type ContainerResult struct {
+	State         string    `json:"state"`
+	Time          time.Time `json:"time"`
+	Error         string    `json:"error,omitempty"`
+	LatestImageID string    `json:"latestImageId,omitempty"`
+	// LatestDigest is the digest of the newer image reported by the registry, for containers checked without pulling
+	LatestDigest string `json:"latestDigest,omitempty"`
+}
+
+// Session is a finished update session
+type Session struct {
+	Started    time.Time          `json:"started"`
+	Finished   time.Time          `json:"finished"`
+	Error      string             `json:"error,omitempty"`
+	Containers []SessionContainer `json:"containers"`
+}
+
+// SessionContainer is the outcome of an update session for a single container
+type SessionContainer struct {
+	Name  string `json:"name"`
+	Image string `json:"image"`
+	State string `json:"state"`
+	Error string `json:"error,omitempty"`
+}
+
+// SchedulerState is whether the scheduled updates are paused
+type SchedulerState struct {
+	Paused bool      `json:"paused"`
+	Reason string    `json:"reason,omitempty"`
+	Since  time.Time `json:"since,omitempty"`
+	// Until is when the pause expires, or the zero time if it lasts until it is resumed
+	Until time.Time `json:"until,omitempty"`
+}
+
+// Event is a step of an update session, as streamed by the events endpoint
+type Event struct {
+	Type        string                 `json:"type"`
+	Time        time.Time              `json:"time"`
+	Container   string                 `json:"container,omitempty"`
+	ContainerID string                 `json:"containerId,omitempty"`
+	Image       string                 `json:"image,omitempty"`
+	Error       string                 `json:"error,omitempty"`
+	Data        map[string]interface{} `json:"data,omitempty"`
+}
+
+// Health is the health of watchtower, along with the checks it is derived from
+type Health struct {
+	Status  string                 `json:"status"`
+	Healthy bool                   `json:"healthy"`
+	Live    bool                   `json:"live"`
+	Ready   bool                   `json:"ready"`
+	Checks  map[string]HealthCheck `json:"checks"`
+}
+
+// HealthCheck is the outcome of a single health check
+type HealthCheck struct {
+	Healthy bool   `json:"healthy"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,201 @@
+package containers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containrrr/watchtower/pkg/api"
+	"github.com/containrrr/watchtower/pkg/container"
+	"github.com/containrrr/watchtower/pkg/types"
+	ref "github.com/distribution/reference"
+	log "github.com/sirupsen/logrus"
+)
+
+// Handler is an API handler serving the status of the containers matched by the filter
+type Handler struct {
+	Path    string
+	client  container.Client
+	filter  types.Filter
+	params  types.UpdateParams
+	mutex   sync.RWMutex
+	results map[string]*containerResults
+}
+
+// Result is the outcome of a check or an update of a container
+type Result struct {
+	State         string        `json:"state"`
+	Time          time.Time     `json:"time"`
+	Error         string        `json:"error,omitempty"`
+	LatestImageID types.ImageID `json:"latestImageId,omitempty"`
+	LatestDigest  string        `json:"latestDigest,omitempty"`
+}
+
+type containerResults struct {
+	lastCheck  *Result
+	lastUpdate *Result
+}
+
+// Status is the current status of a container, including the results of the last sessions it was included in
+type Status struct {
+	Name        string        `json:"name"`
+	ID          string        `json:"id"`
+	Running     bool          `json:"running"`
+	Image       string        `json:"image"`
+	ImageID     types.ImageID `json:"imageId"`
+	ImageDigest string        `json:"imageDigest,omitempty"`
+	Stale       bool          `json:"stale"`
+	MonitorOnly bool          `json:"monitorOnly"`
+	NoPull      bool          `json:"noPull"`
+	Scope       string        `json:"scope,omitempty"`
+	LastCheck   *Result       `json:"lastCheck"`
+	LastUpdate  *Result       `json:"lastUpdate"`
+}
+
+// New is a factory function creating a new Handler instance
+func New(client container.Client, filter types.Filter, params types.UpdateParams) *Handler {
+	return &Handler{
+		Path:    "/v1/containers",
+		client:  client,
+		filter:  filter,
+		params:  params,
+		results: make(map[string]*containerResults),
+	}
+}
+
+// RecordSession stores the results of the containers in the session report, to include them in the container status
+func (handle *Handler) RecordSession(report types.Report, at time.Time) {
+	if report == nil {
+		return
+	}
+
+	handle.mutex.Lock()
+	defer handle.mutex.Unlock()
+
+	for _, c := range report.All() {
+		name := normalizeName(c.Name())
+		results, found := handle.results[name]
+		if !found {
+			results = &containerResults{}
+			handle.results[name] = results
+		}
+
+		result := &Result{
+			State:         c.State(),
+			Time:          at,
+			Error:         c.Error(),
+			LatestImageID: c.LatestImageID(),
+			LatestDigest:  c.LatestDigest(),
+		}
+		results.lastCheck = result
+		if result.State == "Updated" || result.State == "Failed" {
+			results.lastUpdate = result
+		}
+	}
+}
+
+// Handle serves the status of all the containers, or of a single container if the path includes its name
+func (handle *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	containers, err := handle.client.ListContainers(handle.filter)
+	if err != nil {
+		log.WithError(err).Error("Failed to list the containers for the HTTP API")
+		http.Error(w, "failed to list containers", http.StatusInternalServerError)
+		return
+	}
+
+	token, authenticated := api.TokenFromRequest(r)
+	statuses := make([]Status, 0, len(containers))
+	for _, c := range containers {
+		if authenticated && !token.AllowsContainer(c.Name(), c.ImageName()) {
+			continue
+		}
+		statuses = append(statuses, handle.status(c))
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+
+	name := strings.Trim(strings.TrimPrefix(r.URL.Path, handle.Path), "/")
+	if name == "" {
+		writeJSON(w, map[string][]Status{"containers": statuses})
+		return
+	}
+
+	for _, status := range statuses {
+		if status.Name == name {
+			writeJSON(w, status)
+			return
+		}
+	}
+	http.Error(w, "container not found", http.StatusNotFound)
+}
+
+func (handle *Handler) status(c types.Container) Status {
+	handle.mutex.RLock()
+	defer handle.mutex.RUnlock()
+
+	scope, _ := c.Scope()
+	status := Status{
+		Name:        normalizeName(c.Name()),
+		ID:          string(c.ID()),
+		Running:     c.IsRunning(),
+		Image:       c.ImageName(),
+		ImageID:     c.SafeImageID(),
+		ImageDigest: imageDigest(c),
+		MonitorOnly: c.IsMonitorOnly(handle.params),
+		NoPull:      c.IsNoPull(handle.params),
+		Scope:       scope,
+	}
+
+	if results, found := handle.results[status.Name]; found {
+		status.LastCheck = results.lastCheck
+		status.LastUpdate = results.lastUpdate
+		// The container is stale if a newer image was found, that it has not been recreated from yet
+		if latest := results.lastCheck.LatestImageID; latest != "" && latest != status.ImageID {
+			status.Stale = true
+		}
+	}
+	return status
+}
+
+// normalizeName removes the leading slash that Docker adds to the container names
+func normalizeName(name string) string {
+	return strings.TrimPrefix(name, "/")
+}
+
+// imageDigest returns the repository digest of the image that the container was created from, if it is known
+func imageDigest(c types.Container) string {
+	if !c.HasImageInfo() {
+		return ""
+	}
+	imageName, err := ref.ParseNormalizedNamed(c.ImageName())
+	if err != nil {
+		return ""
+	}
+	for _, repoDigest := range c.ImageInfo().RepoDigests {
+		repo, digest, found := strings.Cut(repoDigest, "@")
+		if !found {
+			continue
+		}
+		if normalizedRepo, err := ref.ParseNormalizedNamed(repo); err == nil && normalizedRepo.Name() == imageName.Name() {
+			return digest
+		}
+	}
+	return ""
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.WithError(err).Debug("Failed to write the HTTP API response")
+	}
+}
@@ -0,0 +1,149 @@
+package containers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	dockerContainer "github.com/docker/docker/api/types/container"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/containrrr/watchtower/internal/actions/mocks"
+	"github.com/containrrr/watchtower/pkg/api/containers"
+	"github.com/containrrr/watchtower/pkg/filters"
+	"github.com/containrrr/watchtower/pkg/session"
+	"github.com/containrrr/watchtower/pkg/types"
+)
+
+func TestContainers(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Containers API Suite")
+}
+
+var _ = Describe("the containers API", func() {
+	var handler *containers.Handler
+	var testData *mocks.TestData
+	var sessionTime = time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		handler.Handle(recorder, httptest.NewRequest("GET", "http://localhost:8080"+path, nil))
+		return recorder
+	}
+
+	getStatus := func(name string) containers.Status {
+		res := get("/v1/containers/" + name)
+		Expect(res.Code).To(Equal(http.StatusOK))
+		var status containers.Status
+		Expect(json.Unmarshal(res.Body.Bytes(), &status)).To(Succeed())
+		return status
+	}
+
+	BeforeEach(func() {
+		running := mocks.CreateMockContainerWithConfig(
+			"test-container-02",
+			"/test-container-02",
+			"fake-image2:latest",
+			true,
+			false,
+			time.Now(),
+			&dockerContainer.Config{Image: "fake-image2:latest", Labels: map[string]string{}})
+		running.ImageInfo().RepoDigests = []string{"fake-image2@sha256:0123456789abcdef"}
+		stopped := mocks.CreateMockContainerWithConfig(
+			"test-container-01",
+			"/test-container-01",
+			"fake-image1:latest",
+			false,
+			false,
+			time.Now(),
+			&dockerContainer.Config{Image: "fake-image1:latest", Labels: map[string]string{}})
+		testData = &mocks.TestData{
+			Containers: []types.Container{running, stopped},
+		}
+		client := mocks.CreateMockClient(testData, false, false)
+		handler = containers.New(client, filters.NoFilter, types.UpdateParams{MonitorOnly: true})
+	})
+
+	It("should list all the containers sorted by name", func() {
+		res := get("/v1/containers")
+		Expect(res.Code).To(Equal(http.StatusOK))
+		Expect(res.Header().Get("Content-Type")).To(Equal("application/json"))
+
+		var body struct {
+			Containers []containers.Status `json:"containers"`
+		}
+		Expect(json.Unmarshal(res.Body.Bytes(), &body)).To(Succeed())
+		Expect(body.Containers).To(HaveLen(2))
+		Expect(body.Containers[0].Name).To(Equal("test-container-01"))
+		Expect(body.Containers[0].Running).To(BeFalse())
+		Expect(body.Containers[1].Name).To(Equal("test-container-02"))
+		Expect(body.Containers[1].Running).To(BeTrue())
+	})
+
+	It("should return the status of a single container", func() {
+		status := getStatus("test-container-02")
+		Expect(status.ID).To(Equal("test-container-02"))
+		Expect(status.Image).To(Equal("fake-image2:latest"))
+		Expect(status.ImageID).To(Equal(types.ImageID("fake-image2:latest")))
+		Expect(status.ImageDigest).To(Equal("sha256:0123456789abcdef"))
+		Expect(status.MonitorOnly).To(BeTrue())
+		Expect(status.NoPull).To(BeFalse())
+		Expect(status.LastCheck).To(BeNil())
+		Expect(status.LastUpdate).To(BeNil())
+	})
+
+	It("should return not found for unknown containers", func() {
+		Expect(get("/v1/containers/missing").Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("should only allow GET requests", func() {
+		recorder := httptest.NewRecorder()
+		handler.Handle(recorder, httptest.NewRequest("POST", "http://localhost:8080/v1/containers", nil))
+		Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+	})
+
+	When("a session has been recorded", func() {
+		BeforeEach(func() {
+			progress := session.Progress{}
+			progress.AddScanned(testData.Containers[0], "new-image")
+			progress.AddScanned(testData.Containers[1], testData.Containers[1].SafeImageID())
+			handler.RecordSession(progress.Report(), sessionTime)
+		})
+
+		It("should include the result of the last check", func() {
+			status := getStatus("test-container-02")
+			Expect(status.Stale).To(BeTrue())
+			Expect(status.LastCheck).NotTo(BeNil())
+			Expect(status.LastCheck.State).To(Equal("Stale"))
+			Expect(status.LastCheck.Time).To(BeTemporally("==", sessionTime))
+			Expect(status.LastCheck.LatestImageID).To(Equal(types.ImageID("new-image")))
+			Expect(status.LastUpdate).To(BeNil())
+
+			Expect(getStatus("test-container-01").Stale).To(BeFalse())
+		})
+
+		It("should keep the last update outcome after later checks", func() {
+			progress := session.Progress{}
+			progress.AddScanned(testData.Containers[0], "new-image")
+			progress.MarkForUpdate(testData.Containers[0].ID())
+			progress.UpdateFailed(map[types.ContainerID]error{testData.Containers[0].ID(): errors.New("failed to start")})
+			handler.RecordSession(progress.Report(), sessionTime.Add(time.Hour))
+
+			progress = session.Progress{}
+			progress.AddSkipped(testData.Containers[0], errors.New("registry unavailable"))
+			handler.RecordSession(progress.Report(), sessionTime.Add(2*time.Hour))
+
+			status := getStatus("test-container-02")
+			Expect(status.LastCheck.State).To(Equal("Skipped"))
+			Expect(status.LastCheck.Error).To(Equal("registry unavailable"))
+			Expect(status.LastUpdate).NotTo(BeNil())
+			Expect(status.LastUpdate.State).To(Equal("Failed"))
+			Expect(status.LastUpdate.Error).To(Equal("failed to start"))
+			Expect(status.LastUpdate.Time).To(BeTemporally("==", sessionTime.Add(time.Hour)))
+		})
+	})
+})
@@ -0,0 +1,46 @@
+package dashboard
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed static
+var static embed.FS
+
+// Handler is an API handler serving the static files of the web dashboard. The dashboard uses the other API endpoints
+// for its data, using the token entered by the user.
+type Handler struct {
+	Path  string
+	files http.Handler
+}
+
+// New is a factory function creating a new Handler instance
+func New() *Handler {
+	files, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err)
+	}
+
+	path := "/dashboard/"
+	return &Handler{
+		Path:  path,
+		files: http.StripPrefix(path, http.FileServer(http.FS(files))),
+	}
+}
+
+// ServeHTTP implements http.Handler
+func (handle *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	header := w.Header()
+	header.Set("Content-Security-Policy", "default-src 'self'; frame-ancestors 'none'")
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("Referrer-Policy", "no-referrer")
+	handle.files.ServeHTTP(w, r)
+}
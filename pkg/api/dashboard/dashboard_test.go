@@ -0,0 +1,49 @@
+package dashboard_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/containrrr/watchtower/pkg/api/dashboard"
+)
+
+func TestDashboard(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Dashboard Suite")
+}
+
+var _ = Describe("the dashboard", func() {
+	var handler *dashboard.Handler
+
+	request := func(method string, path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(method, "http://localhost:8080"+path, nil))
+		return recorder
+	}
+
+	BeforeEach(func() {
+		handler = dashboard.New()
+	})
+
+	It("should serve the embedded page", func() {
+		res := request("GET", "/dashboard/")
+		Expect(res.Code).To(Equal(http.StatusOK))
+		Expect(res.Header().Get("Content-Type")).To(HavePrefix("text/html"))
+		Expect(res.Header().Get("Content-Security-Policy")).To(ContainSubstring("default-src 'self'"))
+		Expect(res.Body.String()).To(ContainSubstring(`<script src="app.js"`))
+	})
+
+	It("should serve the scripts and styles", func() {
+		Expect(request("GET", "/dashboard/app.js").Code).To(Equal(http.StatusOK))
+		Expect(request("GET", "/dashboard/style.css").Code).To(Equal(http.StatusOK))
+		Expect(request("GET", "/dashboard/missing.js").Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("should only allow reading", func() {
+		Expect(request("POST", "/dashboard/").Code).To(Equal(http.StatusMethodNotAllowed))
+	})
+})
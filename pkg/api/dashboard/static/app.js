@@ -0,0 +1,285 @@
+'use strict';
+
+// The API endpoints are resolved relative to the dashboard, so that it keeps working behind a path prefix
+const apiURL = (path) => new URL('../v1/' + path, window.location.href).toString();
+
+const tokenKey = 'watchtower-token';
+const maxLogLines = 500;
+
+let eventStream = null;
+
+const $ = (id) => document.getElementById(id);
+
+function token() {
+  return window.sessionStorage.getItem(tokenKey);
+}
+
+class APIError extends Error {
+  constructor(response, message) {
+    super(message || response.statusText);
+    this.status = response.status;
+  }
+}
+
+async function request(method, path) {
+  const response = await fetch(apiURL(path), {
+    method,
+    headers: { Authorization: 'Bearer ' + token() },
+    cache: 'no-store',
+  });
+  if (response.status === 401) {
+    signOut('The token is not valid.');
+    throw new APIError(response);
+  }
+  if (!response.ok) {
+    throw new APIError(response, (await response.text()).trim());
+  }
+  return response.status === 204 ? null : response.json();
+}
+
+function element(tag, text, className) {
+  const el = document.createElement(tag);
+  if (text !== undefined && text !== null) {
+    el.textContent = text;
+  }
+  if (className) {
+    el.className = className;
+  }
+  return el;
+}
+
+function stateBadge(state) {
+  return element('span', state, 'state state-' + state.toLowerCase());
+}
+
+function formatTime(time) {
+  if (!time) {
+    return '-';
+  }
+  return new Date(time).toLocaleString();
+}
+
+function resultText(result) {
+  if (!result) {
+    return '-';
+  }
+  return result.state + ', ' + formatTime(result.time) + (result.error ? ': ' + result.error : '');
+}
+
+function setStatus(text, isError) {
+  $('status').textContent = text;
+  $('status').classList.toggle('error', !!isError);
+}
+
+function appendLog(line, isError) {
+  const log = $('log');
+  const entry = element('div', line, isError ? 'error' : null);
+  log.appendChild(entry);
+  while (log.childNodes.length > maxLogLines) {
+    log.removeChild(log.firstChild);
+  }
+  log.scrollTop = log.scrollHeight;
+}
+
+async function loadContainers() {
+  const body = $('containers');
+  try {
+    const { containers } = await request('GET', 'containers');
+    body.replaceChildren(...containers.map((c) => {
+      const row = element('tr');
+      row.appendChild(element('td', c.name));
+      row.appendChild(element('td', c.image));
+
+      const status = element('td');
+      status.appendChild(stateBadge(c.running ? 'Running' : 'Stopped'));
+      if (c.stale) {
+        status.appendChild(document.createTextNode(' '));
+        status.appendChild(stateBadge('Stale'));
+      }
+      row.appendChild(status);
+
+      row.appendChild(element('td', resultText(c.lastCheck)));
+      row.appendChild(element('td', resultText(c.lastUpdate)));
+
+      const actions = element('td');
+      const button = element('button', 'Update');
+      button.disabled = c.monitorOnly;
+      button.addEventListener('click', () => triggerUpdate(c.name, button));
+      actions.appendChild(button);
+      row.appendChild(actions);
+      return row;
+    }));
+  } catch (err) {
+    body.replaceChildren(errorRow(6, 'Failed to load the containers: ' + err.message));
+  }
+}
+
+async function loadSessions() {
+  const body = $('sessions');
+  try {
+    const { sessions } = await request('GET', 'sessions');
+    if (sessions.length === 0) {
+      body.replaceChildren(errorRow(6, 'No sessions have run yet.', true));
+      return;
+    }
+    body.replaceChildren(...sessions.map((s) => {
+      const count = (state) => s.containers.filter((c) => c.state === state).length;
+      const row = element('tr');
+      row.appendChild(element('td', formatTime(s.finished)));
+      row.appendChild(element('td', ((new Date(s.finished) - new Date(s.started)) / 1000).toFixed(1) + 's'));
+      row.appendChild(element('td', s.containers.filter((c) => c.state !== 'Skipped').length));
+      row.appendChild(element('td', count('Updated')));
+      row.appendChild(element('td', count('Failed')));
+      const changed = s.containers
+        .filter((c) => c.state === 'Updated' || c.state === 'Failed')
+        .map((c) => c.name + ': ' + c.state + (c.error ? ' (' + c.error + ')' : ''));
+      if (s.error) {
+        changed.unshift(s.error);
+      }
+      row.appendChild(element('td', changed.join(', ') || '-', s.error ? 'error' : null));
+      return row;
+    }));
+  } catch (err) {
+    body.replaceChildren(errorRow(6, 'Failed to load the sessions: ' + err.message));
+  }
+}
+
+function errorRow(columns, message, isInfo) {
+  const row = element('tr');
+  const cell = element('td', message, isInfo ? null : 'error');
+  cell.colSpan = columns;
+  row.appendChild(cell);
+  return row;
+}
+
+async function triggerUpdate(container, button) {
+  button.disabled = true;
+  const target = container ? 'container ' + container : 'all containers';
+  appendLog('Update of ' + target + ' requested.');
+  try {
+    const path = container ? 'update?container=' + encodeURIComponent(container) : 'update';
+    const job = await request('POST', path);
+    appendLog('Update job ' + job.id + ' is ' + job.state + '.');
+    const finished = await request('GET', 'jobs/' + encodeURIComponent(job.id) + '?wait=true');
+    appendLog('Update job ' + finished.id + (finished.succeeded ? ' succeeded.' : ' failed.'), !finished.succeeded);
+  } catch (err) {
+    appendLog('Failed to update ' + target + ': ' + err.message, true);
+  } finally {
+    button.disabled = false;
+    loadContainers();
+    loadSessions();
+  }
+}
+
+function describeEvent(event) {
+  const container = event.container ? event.container.replace(/^\//, '') : '';
+  switch (event.type) {
+    case 'session-started': return 'Session started.';
+    case 'session-finished': return 'Session finished.';
+    case 'container-checked': return 'Checked ' + container + ': ' + (event.data ? event.data.state : 'Unknown') + '.';
+    case 'pull-started': return 'Pulling ' + event.image + '.';
+    case 'pull-finished': return 'Pulled ' + event.image + '.';
+    case 'container-stopping': return 'Stopping ' + container + '.';
+    case 'container-created': return 'Created ' + container + '.';
+    case 'container-started': return 'Started ' + container + '.';
+    case 'container-failed': return 'Failed to update ' + container + '.';
+    case 'hook-output': return 'Lifecycle hook exited with code ' + event.data.exitCode + ':\n' + event.data.output;
+    default: return event.type + (container ? ' ' + container : '');
+  }
+}
+
+// streamEvents reads the Server-Sent Events using fetch, as EventSource cannot send the authorization header
+async function streamEvents() {
+  const controller = new AbortController();
+  eventStream = controller;
+  try {
+    const response = await fetch(apiURL('events'), {
+      headers: { Authorization: 'Bearer ' + token() },
+      signal: controller.signal,
+    });
+    if (!response.ok) {
+      throw new APIError(response);
+    }
+    setStatus('Connected');
+    const reader = response.body.pipeThrough(new TextDecoderStream()).getReader();
+    let buffer = '';
+    for (;;) {
+      const { value, done } = await reader.read();
+      if (done) {
+        break;
+      }
+      buffer += value;
+      let end;
+      while ((end = buffer.indexOf('\n\n')) >= 0) {
+        handleMessage(buffer.slice(0, end));
+        buffer = buffer.slice(end + 2);
+      }
+    }
+  } catch (err) {
+    if (controller.signal.aborted) {
+      return;
+    }
+    setStatus('Live log unavailable: ' + err.message, true);
+  }
+  if (eventStream === controller) {
+    setStatus('Reconnecting...', true);
+    window.setTimeout(streamEvents, 5000);
+  }
+}
+
+function handleMessage(message) {
+  const data = message.split('\n')
+    .filter((line) => line.startsWith('data: '))
+    .map((line) => line.slice(6))
+    .join('\n');
+  if (!data) {
+    return;
+  }
+  const event = JSON.parse(data);
+  const line = formatTime(event.time) + '  ' + describeEvent(event) + (event.error ? ' ' + event.error : '');
+  appendLog(line, !!event.error || event.type === 'container-failed');
+  if (event.type === 'session-finished') {
+    loadContainers();
+    loadSessions();
+  }
+}
+
+function showDashboard() {
+  $('login').hidden = true;
+  $('dashboard').hidden = false;
+  $('logout').hidden = false;
+  loadContainers();
+  loadSessions();
+  streamEvents();
+}
+
+function signOut(message) {
+  window.sessionStorage.removeItem(tokenKey);
+  if (eventStream) {
+    const stream = eventStream;
+    eventStream = null;
+    stream.abort();
+  }
+  $('dashboard').hidden = true;
+  $('logout').hidden = true;
+  $('login').hidden = false;
+  $('login-error').textContent = message || '';
+  setStatus('');
+}
+
+document.addEventListener('DOMContentLoaded', () => {
+  $('login').addEventListener('submit', (e) => {
+    e.preventDefault();
+    window.sessionStorage.setItem(tokenKey, $('token').value);
+    $('token').value = '';
+    showDashboard();
+  });
+  $('logout').addEventListener('click', () => signOut());
+  $('update-all').addEventListener('click', (e) => triggerUpdate(null, e.target));
+
+  if (token()) {
+    showDashboard();
+  } else {
+    signOut();
+  }
+});
@@ -0,0 +1,102 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/containrrr/watchtower/pkg/api"
+	"github.com/containrrr/watchtower/pkg/session"
+	"github.com/containrrr/watchtower/pkg/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// keepAliveInterval is how often a comment is sent on idle streams, to keep proxies from closing the connection
+const keepAliveInterval = 15 * time.Second
+
+// Handler is an API handler streaming the session events to the clients using Server-Sent Events
+type Handler struct {
+	Path      string
+	bus       *session.EventBus
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// New is a factory function creating a new Handler instance, streaming the events published on the bus
+func New(bus *session.EventBus) *Handler {
+	return &Handler{
+		Path:   "/v1/events",
+		bus:    bus,
+		closed: make(chan struct{}),
+	}
+}
+
+// Close ends all the active streams, allowing the API to shut down
+func (handle *Handler) Close() {
+	handle.closeOnce.Do(func() {
+		close(handle.closed)
+	})
+}
+
+// Handle streams the events published after the request was received, until the client disconnects
+func (handle *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	events, unsubscribe := handle.bus.Subscribe()
+	defer unsubscribe()
+	token, authenticated := api.TokenFromRequest(r)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case event := <-events:
+			// Events of the containers that the token is not limited to are left out, including the hook output
+			if authenticated && event.ContainerID != "" && !token.AllowsContainer(event.Container, event.Image) {
+				continue
+			}
+			// The session counts are recomputed for limited tokens, so that they do not reveal the other containers
+			if authenticated && token.IsLimited() && event.Report != nil {
+				event.Data = session.ReportCounts(event.Report, func(c types.ContainerReport) bool {
+					return token.AllowsContainer(c.Name(), c.ImageName())
+				})
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				log.WithError(err).Debug("Failed to marshal the session event")
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		case <-handle.closed:
+			return
+		}
+		flusher.Flush()
+	}
+}
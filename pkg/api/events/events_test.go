@@ -0,0 +1,132 @@
+package events_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/containrrr/watchtower/internal/actions/mocks"
+	"github.com/containrrr/watchtower/pkg/api"
+	"github.com/containrrr/watchtower/pkg/api/events"
+	"github.com/containrrr/watchtower/pkg/session"
+)
+
+func TestEvents(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Events API Suite")
+}
+
+var _ = Describe("the events API", func() {
+	var bus *session.EventBus
+	var handler *events.Handler
+	var server *httptest.Server
+
+	readEvent := func(reader *bufio.Reader) (string, session.Event) {
+		var eventType string
+		var event session.Event
+		for {
+			line, err := reader.ReadString('\n')
+			Expect(err).NotTo(HaveOccurred())
+			line = strings.TrimSuffix(line, "\n")
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				eventType = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				Expect(json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event)).To(Succeed())
+			case line == "":
+				return eventType, event
+			}
+		}
+	}
+
+	BeforeEach(func() {
+		bus = session.Events()
+		handler = events.New(bus)
+		server = httptest.NewServer(http.HandlerFunc(handler.Handle))
+	})
+
+	AfterEach(func() {
+		handler.Close()
+		server.Close()
+	})
+
+	It("should stream the published events", func() {
+		res, err := http.Get(server.URL + handler.Path)
+		Expect(err).NotTo(HaveOccurred())
+		defer res.Body.Close()
+		Expect(res.StatusCode).To(Equal(http.StatusOK))
+		Expect(res.Header.Get("Content-Type")).To(Equal("text/event-stream"))
+
+		bus.Publish(session.Event{Type: session.SessionStartedEvent})
+		bus.Publish(session.Event{Type: session.PullStartedEvent, Container: "test-container-01"})
+
+		reader := bufio.NewReader(res.Body)
+		eventType, event := readEvent(reader)
+		Expect(eventType).To(Equal("session-started"))
+		Expect(event.Type).To(Equal(session.SessionStartedEvent))
+		Expect(event.Time).NotTo(BeZero())
+
+		eventType, event = readEvent(reader)
+		Expect(eventType).To(Equal("pull-started"))
+		Expect(event.Container).To(Equal("test-container-01"))
+	})
+
+	It("should only count the containers that a limited token may access", func() {
+		httpAPI := api.New("")
+		httpAPI.Tokens = []api.Token{{
+			Name:       "limited",
+			Token:      "limited-token",
+			Scopes:     []api.Scope{api.ScopeStatusRead},
+			Containers: []string{"updt1"},
+		}}
+		limited := httptest.NewServer(httpAPI.RequireScope(api.ScopeStatusRead, handler.Handle))
+		defer limited.Close()
+
+		req, err := http.NewRequest("GET", limited.URL+handler.Path, nil)
+		Expect(err).NotTo(HaveOccurred())
+		req.Header.Set("Authorization", "Bearer limited-token")
+		res, err := http.DefaultClient.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		defer res.Body.Close()
+		Expect(res.StatusCode).To(Equal(http.StatusOK))
+
+		report := mocks.CreateMockProgressReport(session.UpdatedState, session.FreshState, session.FailedState)
+		bus.Publish(session.Event{
+			Type:   session.SessionFinishedEvent,
+			Report: report,
+			Data:   session.ReportCounts(report, nil),
+		})
+
+		_, event := readEvent(bufio.NewReader(res.Body))
+		Expect(event.Type).To(Equal(session.SessionFinishedEvent))
+		Expect(event.Data).To(HaveKeyWithValue("scanned", BeNumerically("==", 1)))
+		Expect(event.Data).To(HaveKeyWithValue("updated", BeNumerically("==", 1)))
+		Expect(event.Data).To(HaveKeyWithValue("fresh", BeNumerically("==", 0)))
+		Expect(event.Data).To(HaveKeyWithValue("failed", BeNumerically("==", 0)))
+	})
+
+	It("should end the streams when closed", func() {
+		res, err := http.Get(server.URL + handler.Path)
+		Expect(err).NotTo(HaveOccurred())
+		defer res.Body.Close()
+
+		handler.Close()
+		Eventually(func() error {
+			_, err := bufio.NewReader(res.Body).ReadString('\n')
+			return err
+		}).Should(HaveOccurred())
+	})
+
+	It("should only allow GET requests", func() {
+		res, err := http.Post(server.URL+handler.Path, "text/plain", nil)
+		Expect(err).NotTo(HaveOccurred())
+		defer res.Body.Close()
+		Expect(res.StatusCode).To(Equal(http.StatusMethodNotAllowed))
+	})
+})
@@ -0,0 +1,59 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/containrrr/watchtower/pkg/health"
+	log "github.com/sirupsen/logrus"
+)
+
+// Handler is an API handler serving the health of watchtower
+type Handler struct {
+	Path    string
+	monitor *health.Monitor
+}
+
+// New is a factory function creating a new Handler instance, serving the health tracked by the monitor
+func New(monitor *health.Monitor) *Handler {
+	return &Handler{
+		Path:    "/v1/health",
+		monitor: monitor,
+	}
+}
+
+// ServeHTTP implements http.Handler. The overall health is served at the path itself, while the liveness and readiness
+// are served at the live and ready sub paths. Unhealthy states are answered with 503 Service Unavailable.
+func (handle *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	status := handle.monitor.Status(time.Now())
+	healthy := status.Healthy
+	switch strings.Trim(strings.TrimPrefix(r.URL.Path, handle.Path), "/") {
+	case "":
+	case "live":
+		healthy = status.Live
+	case "ready":
+		healthy = status.Ready
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	code := http.StatusOK
+	if !healthy {
+		code = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		log.WithError(err).Debug("Failed to write the HTTP API response")
+	}
+}
@@ -0,0 +1,66 @@
+package health_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	apiHealth "github.com/containrrr/watchtower/pkg/api/health"
+	"github.com/containrrr/watchtower/pkg/health"
+)
+
+func TestHealth(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Health API Suite")
+}
+
+var _ = Describe("the health API", func() {
+	var monitor *health.Monitor
+	var handler *apiHealth.Handler
+
+	get := func(path string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", "http://localhost:8080"+path, nil))
+		var body map[string]interface{}
+		if recorder.Header().Get("Content-Type") == "application/json" {
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &body)).To(Succeed())
+		}
+		return recorder, body
+	}
+
+	BeforeEach(func() {
+		monitor = health.NewMonitor(time.Hour)
+		handler = apiHealth.New(monitor)
+	})
+
+	It("should report a healthy instance", func() {
+		monitor.RecordPing(nil, time.Now())
+		res, body := get("/v1/health")
+		Expect(res.Code).To(Equal(http.StatusOK))
+		Expect(body["status"]).To(Equal("healthy"))
+		Expect(body["checks"]).To(HaveKey(health.DaemonCheck))
+	})
+
+	It("should report an unhealthy instance as unavailable", func() {
+		monitor.RecordPing(errors.New("connection refused"), time.Now())
+		res, body := get("/v1/health")
+		Expect(res.Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(body["status"]).To(Equal("unhealthy"))
+	})
+
+	It("should serve the liveness and readiness separately", func() {
+		monitor.RecordPing(errors.New("connection refused"), time.Now())
+		res, _ := get("/v1/health/live")
+		Expect(res.Code).To(Equal(http.StatusOK))
+		res, _ = get("/v1/health/ready")
+		Expect(res.Code).To(Equal(http.StatusServiceUnavailable))
+		res, _ = get("/v1/health/unknown")
+		Expect(res.Code).To(Equal(http.StatusNotFound))
+	})
+})
@@ -0,0 +1,42 @@
+package openapi
+
+import (
+	_ "embed"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Document is the OpenAPI 3 document describing the HTTP API
+//
+//go:embed openapi.json
+var Document []byte
+
+// Handler is an API handler serving the OpenAPI document
+type Handler struct {
+	Path string
+}
+
+// New is a factory function creating a new Handler instance
+func New() *Handler {
+	return &Handler{
+		Path: "/v1/openapi.json",
+	}
+}
+
+// ServeHTTP implements http.Handler
+func (handle *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		if _, err := w.Write(Document); err != nil {
+			log.WithError(err).Debug("Failed to write the HTTP API response")
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package openapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/containrrr/watchtower/internal/actions/mocks"
+	"github.com/containrrr/watchtower/pkg/api/containers"
+	"github.com/containrrr/watchtower/pkg/api/events"
+	apiHealth "github.com/containrrr/watchtower/pkg/api/health"
+	apiMetrics "github.com/containrrr/watchtower/pkg/api/metrics"
+	"github.com/containrrr/watchtower/pkg/api/openapi"
+	"github.com/containrrr/watchtower/pkg/api/scheduler"
+	"github.com/containrrr/watchtower/pkg/api/sessions"
+	"github.com/containrrr/watchtower/pkg/api/update"
+	"github.com/containrrr/watchtower/pkg/api/webhook"
+	"github.com/containrrr/watchtower/pkg/health"
+	"github.com/containrrr/watchtower/pkg/pause"
+	"github.com/containrrr/watchtower/pkg/session"
+	"github.com/containrrr/watchtower/pkg/types"
+)
+
+func TestOpenAPI(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "OpenAPI Suite")
+}
+
+type object = map[string]interface{}
+
+var _ = Describe("the OpenAPI document", func() {
+	var document object
+
+	BeforeEach(func() {
+		Expect(json.Unmarshal(openapi.Document, &document)).To(Succeed())
+	})
+
+	schema := func(name string) object {
+		return object{"$ref": "#/components/schemas/" + name}
+	}
+
+	It("should be an OpenAPI 3 document", func() {
+		Expect(document["openapi"]).To(HavePrefix("3.0."))
+		Expect(document).To(HaveKey("info"))
+	})
+
+	It("should describe all the endpoints of the API", func() {
+		updateHandler := update.New(nil, nil)
+		containersHandler := containers.New(nil, nil, types.UpdateParams{})
+		schedulerHandler := scheduler.New(nil)
+		healthHandler := apiHealth.New(nil)
+		expected := []string{
+			updateHandler.Path,
+			updateHandler.JobsPath + "{id}",
+			containersHandler.Path,
+			containersHandler.Path + "/{name}",
+			events.New(session.Events()).Path,
+			sessions.New().Path,
+			apiMetrics.New().Path,
+			schedulerHandler.Path,
+			schedulerHandler.Path + "/pause",
+			schedulerHandler.Path + "/resume",
+			webhook.New(nil, "").Path,
+			healthHandler.Path,
+			healthHandler.Path + "/live",
+			healthHandler.Path + "/ready",
+			openapi.New().Path,
+		}
+
+		var paths []string
+		for path := range document["paths"].(object) {
+			paths = append(paths, path)
+		}
+		Expect(paths).To(ConsistOf(expected))
+	})
+
+	It("should only reference defined components", func() {
+		Expect(undefinedReferences(document, document)).To(BeEmpty())
+	})
+
+	It("should use unique operation IDs", func() {
+		ids := map[string]bool{}
+		for _, operations := range document["paths"].(object) {
+			for _, operation := range operations.(object) {
+				id := operation.(object)["operationId"].(string)
+				Expect(ids).NotTo(HaveKey(id))
+				ids[id] = true
+			}
+		}
+	})
+
+	Describe("the schemas", func() {
+		It("should match the update jobs", func() {
+			queue := update.NewQueue(func(target update.Target) (types.Report, error) {
+				return mocks.CreateMockProgressReport(session.UpdatedState, session.FailedState, session.FreshState), nil
+			}, nil)
+			queued := queue.Add(update.Target{Images: []string{"mock/updt1"}})
+			Expect(validate(document, schema("Job"), queued)).To(BeEmpty())
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			done, err := queue.Wait(ctx, queued.ID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(validate(document, schema("Job"), done)).To(BeEmpty())
+		})
+
+		It("should match the container status", func() {
+			status := containers.Status{
+				Name:      "test-container",
+				Image:     "fake-image:latest",
+				Scope:     "production",
+				LastCheck: &containers.Result{State: "Fresh", Time: time.Now(), Error: "timeout", LatestImageID: "sha256:01"},
+			}
+			Expect(validate(document, schema("ContainerList"), map[string][]containers.Status{
+				"containers": {status},
+			})).To(BeEmpty())
+		})
+
+		It("should match the sessions", func() {
+			handler := sessions.New()
+			handler.RecordSession(mocks.CreateMockProgressReport(session.UpdatedState, session.FailedState), time.Now(), time.Now(), errors.New("failed"))
+			recorder := httptest.NewRecorder()
+			handler.Handle(recorder, httptest.NewRequest("GET", "http://localhost:8080"+handler.Path, nil))
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(validateJSON(document, schema("SessionList"), recorder.Body.Bytes())).To(BeEmpty())
+		})
+
+		It("should match the scheduler state", func() {
+			dir, err := os.MkdirTemp("", "watchtower-openapi")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(dir)
+			store := pause.NewStore(filepath.Join(dir, "pause.json"))
+			state, err := store.Pause("maintenance", time.Now().Add(time.Hour))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(validate(document, schema("SchedulerState"), state)).To(BeEmpty())
+			state, err = store.Resume()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(validate(document, schema("SchedulerState"), state)).To(BeEmpty())
+		})
+
+		It("should match the session events", func() {
+			event := session.Event{
+				Type:        session.HookOutputEvent,
+				Time:        time.Now(),
+				Container:   "test-container",
+				ContainerID: "abc123",
+				Image:       "fake-image:latest",
+				Error:       "exit code 1",
+				Data:        map[string]interface{}{"output": "done"},
+			}
+			Expect(validate(document, schema("Event"), event)).To(BeEmpty())
+
+			eventSchema := resolve(document, schema("Event"))
+			typeSchema := eventSchema["properties"].(object)["type"].(object)
+			Expect(typeSchema["enum"]).To(ContainElements(
+				string(session.SessionStartedEvent),
+				string(session.ContainerFailedEvent),
+				string(session.SessionFinishedEvent),
+			))
+		})
+
+		It("should match the health status", func() {
+			monitor := health.NewMonitor(time.Hour)
+			monitor.RecordPing(errors.New("connection refused"), time.Now())
+			Expect(validate(document, schema("Health"), monitor.Status(time.Now()))).To(BeEmpty())
+		})
+
+		It("should match the pushes received by the webhook", func() {
+			pushes, err := webhook.ParsePushes([]byte(`{"push_data": {"tag": "latest"}, "repository": {"repo_name": "containrrr/watchtower"}}`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(validate(document, schema("WebhookResult"), object{
+				"pushes": pushes,
+				"job":    update.Job{ID: "1", State: update.JobQueued, Target: update.Target{Images: []string{"containrrr/watchtower"}}},
+			})).To(BeEmpty())
+		})
+	})
+
+	It("should be served by the handler", func() {
+		handler := openapi.New()
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", "http://localhost:8080"+handler.Path, nil))
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+		Expect(recorder.Body.Bytes()).To(Equal(openapi.Document))
+
+		recorder = httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest("POST", "http://localhost:8080"+handler.Path, nil))
+		Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+	})
+})
+
+// validate returns the differences between the JSON encoding of the value and the schema
+func validate(document object, schema object, value interface{}) []string {
+	data, err := json.Marshal(value)
+	Expect(err).NotTo(HaveOccurred())
+	return validateJSON(document, schema, data)
+}
+
+func validateJSON(document object, schema object, data []byte) []string {
+	var value interface{}
+	Expect(json.Unmarshal(data, &value)).To(Succeed())
+	return validateValue(document, schema, value, "$")
+}
+
+// validateValue checks the types of the value, that the required properties are present, and that no undocumented
+// properties are included
+func validateValue(document object, schema object, value interface{}, path string) []string {
+	schema = resolve(document, schema)
+	if value == nil {
+		if schema["nullable"] == true {
+			return nil
+		}
+		return []string{path + " is null"}
+	}
+
+	var problems []string
+	switch schema["type"] {
+	case "object":
+		properties, ok := value.(object)
+		if !ok {
+			return []string{fmt.Sprintf("%s is not an object", path)}
+		}
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, name := range required {
+				if _, found := properties[name.(string)]; !found {
+					problems = append(problems, fmt.Sprintf("%s.%s is missing", path, name))
+				}
+			}
+		}
+		documented, _ := schema["properties"].(object)
+		names := make([]string, 0, len(properties))
+		for name := range properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if property, found := documented[name]; found {
+				problems = append(problems, validateValue(document, property.(object), properties[name], path+"."+name)...)
+			} else if additional, ok := schema["additionalProperties"].(object); ok {
+				problems = append(problems, validateValue(document, additional, properties[name], path+"."+name)...)
+			} else if schema["additionalProperties"] != true {
+				problems = append(problems, fmt.Sprintf("%s.%s is not documented", path, name))
+			}
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s is not an array", path)}
+		}
+		for i, item := range items {
+			problems = append(problems, validateValue(document, schema["items"].(object), item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			problems = append(problems, fmt.Sprintf("%s is not a string", path))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			problems = append(problems, fmt.Sprintf("%s is not a boolean", path))
+		}
+	case "integer", "number":
+		if _, ok := value.(float64); !ok {
+			problems = append(problems, fmt.Sprintf("%s is not a number", path))
+		}
+	}
+	return problems
+}
+
+// resolve returns the schema that is referenced by the schema, if any
+func resolve(document object, schema object) object {
+	ref, found := schema["$ref"].(string)
+	if !found {
+		return schema
+	}
+	var target interface{} = document
+	for _, name := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		target = target.(object)[name]
+	}
+	return target.(object)
+}
+
+// undefinedReferences returns the references within the value that cannot be resolved in the document
+func undefinedReferences(document object, value interface{}) []string {
+	var undefined []string
+	switch value := value.(type) {
+	case object:
+		if ref, found := value["$ref"].(string); found {
+			var target interface{} = document
+			for _, name := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+				if parent, ok := target.(object); ok {
+					target = parent[name]
+				} else {
+					target = nil
+				}
+			}
+			if target == nil {
+				undefined = append(undefined, ref)
+			}
+		}
+		for _, child := range value {
+			undefined = append(undefined, undefinedReferences(document, child)...)
+		}
+	case []interface{}:
+		for _, child := range value {
+			undefined = append(undefined, undefinedReferences(document, child)...)
+		}
+	}
+	return undefined
+}
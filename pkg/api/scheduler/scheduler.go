@@ -0,0 +1,114 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/containrrr/watchtower/pkg/pause"
+	log "github.com/sirupsen/logrus"
+)
+
+// Handler is an API handler used for pausing and resuming the scheduled updates
+type Handler struct {
+	Path  string
+	store *pause.Store
+	now   func() time.Time
+}
+
+// New is a factory function creating a new Handler instance, changing the pause state in the store
+func New(store *pause.Store) *Handler {
+	return &Handler{
+		Path:  "/v1/scheduler",
+		store: store,
+		now:   time.Now,
+	}
+}
+
+// Handle serves the pause state, and pauses or resumes the scheduled updates depending on the path
+func (handle *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	action := strings.Trim(strings.TrimPrefix(r.URL.Path, handle.Path), "/")
+	switch action {
+	case "":
+		if !allowMethod(w, r, http.MethodGet) {
+			return
+		}
+		state, err := handle.store.Status()
+		handle.writeState(w, state, err)
+	case "pause":
+		if !allowMethod(w, r, http.MethodPost) {
+			return
+		}
+		until, err := handle.parseExpiry(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		state, err := handle.store.Pause(r.FormValue("reason"), until)
+		if err == nil {
+			log.WithField("reason", state.Reason).Info("Scheduled updates paused by HTTP API request.")
+		}
+		handle.writeState(w, state, err)
+	case "resume":
+		if !allowMethod(w, r, http.MethodPost) {
+			return
+		}
+		state, err := handle.store.Resume()
+		if err == nil {
+			log.Info("Scheduled updates resumed by HTTP API request.")
+		}
+		handle.writeState(w, state, err)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// parseExpiry returns when the requested pause expires, given either as a duration or a time, or the zero time if the
+// pause should last until it is resumed
+func (handle *Handler) parseExpiry(r *http.Request) (time.Time, error) {
+	duration := r.FormValue("duration")
+	until := r.FormValue("until")
+	switch {
+	case duration != "" && until != "":
+		return time.Time{}, fmt.Errorf("only one of duration and until can be given")
+	case duration != "":
+		d, err := time.ParseDuration(duration)
+		if err != nil || d <= 0 {
+			return time.Time{}, fmt.Errorf("invalid duration %q", duration)
+		}
+		return handle.now().Add(d), nil
+	case until != "":
+		t, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid time %q, expected RFC 3339", until)
+		}
+		if !t.After(handle.now()) {
+			return time.Time{}, fmt.Errorf("the pause expiry must be in the future")
+		}
+		return t, nil
+	}
+	return time.Time{}, nil
+}
+
+func (handle *Handler) writeState(w http.ResponseWriter, state pause.State, err error) {
+	if err != nil {
+		log.WithError(err).Error("Failed to update the scheduler pause state")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(state); err != nil {
+		log.WithError(err).Debug("Failed to write the HTTP API response")
+	}
+}
+
+func allowMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method == method {
+		return true
+	}
+	w.Header().Set("Allow", method)
+	http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	return false
+}
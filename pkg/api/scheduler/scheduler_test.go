@@ -0,0 +1,83 @@
+package scheduler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/containrrr/watchtower/pkg/api/scheduler"
+	"github.com/containrrr/watchtower/pkg/pause"
+)
+
+func TestScheduler(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Scheduler API Suite")
+}
+
+var _ = Describe("the scheduler API", func() {
+	var handler *scheduler.Handler
+
+	request := func(method string, path string) (*httptest.ResponseRecorder, pause.State) {
+		recorder := httptest.NewRecorder()
+		handler.Handle(recorder, httptest.NewRequest(method, "http://localhost:8080"+path, nil))
+		var state pause.State
+		if recorder.Code == http.StatusOK {
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &state)).To(Succeed())
+		}
+		return recorder, state
+	}
+
+	BeforeEach(func() {
+		handler = scheduler.New(pause.NewStore(""))
+	})
+
+	It("should pause and resume the scheduled updates", func() {
+		res, state := request("POST", "/v1/scheduler/pause?reason=incident")
+		Expect(res.Code).To(Equal(http.StatusOK))
+		Expect(state.Paused).To(BeTrue())
+		Expect(state.Reason).To(Equal("incident"))
+		Expect(state.Until).To(BeZero())
+
+		_, state = request("GET", "/v1/scheduler")
+		Expect(state.Paused).To(BeTrue())
+
+		res, state = request("POST", "/v1/scheduler/resume")
+		Expect(res.Code).To(Equal(http.StatusOK))
+		Expect(state.Paused).To(BeFalse())
+
+		_, state = request("GET", "/v1/scheduler")
+		Expect(state.Paused).To(BeFalse())
+	})
+
+	It("should set the expiry of the pause", func() {
+		_, state := request("POST", "/v1/scheduler/pause?duration=2h")
+		Expect(state.Until).To(BeTemporally("~", time.Now().Add(2*time.Hour), time.Minute))
+
+		until := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+		_, state = request("POST", "/v1/scheduler/pause?until="+until.Format(time.RFC3339))
+		Expect(state.Until).To(BeTemporally("==", until))
+	})
+
+	It("should reject invalid expiries", func() {
+		res, _ := request("POST", "/v1/scheduler/pause?duration=soon")
+		Expect(res.Code).To(Equal(http.StatusBadRequest))
+		res, _ = request("POST", "/v1/scheduler/pause?until=2000-01-01T00:00:00Z")
+		Expect(res.Code).To(Equal(http.StatusBadRequest))
+		res, _ = request("POST", "/v1/scheduler/pause?duration=1h&until=2100-01-01T00:00:00Z")
+		Expect(res.Code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("should only change the state on POST requests", func() {
+		res, _ := request("GET", "/v1/scheduler/pause")
+		Expect(res.Code).To(Equal(http.StatusMethodNotAllowed))
+		res, _ = request("POST", "/v1/scheduler")
+		Expect(res.Code).To(Equal(http.StatusMethodNotAllowed))
+		res, _ = request("POST", "/v1/scheduler/unknown")
+		Expect(res.Code).To(Equal(http.StatusNotFound))
+	})
+})
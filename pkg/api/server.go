@@ -0,0 +1,172 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultPort is the port that the API listens on unless another one is configured
+	DefaultPort = 8080
+
+	readHeaderTimeout = 10 * time.Second
+	idleTimeout       = 2 * time.Minute
+	shutdownTimeout   = 30 * time.Second
+)
+
+// ServerConfig contains the settings for where the API listens and how the connections are secured
+type ServerConfig struct {
+	// Host is the address to listen on, or empty to listen on all interfaces
+	Host string
+	Port int
+	// Socket is the path of a Unix socket to listen on instead of a TCP port
+	Socket string
+	// TLSCert and TLSKey are the paths of the PEM encoded certificate and private key used for serving HTTPS
+	TLSCert string
+	TLSKey  string
+	// TLSClientCA is the path of a PEM encoded CA bundle used for verifying the required client certificates
+	TLSClientCA string
+	// TLSClientCert and TLSClientKey are the paths of the PEM encoded certificate and private key that the local client
+	// presents when client certificates are required. The server certificate is presented if they are not set.
+	TLSClientCert string
+	TLSClientKey  string
+}
+
+// Address returns the address that the API listens on, for logging
+func (c ServerConfig) Address() string {
+	if c.Socket != "" {
+		return "unix://" + c.Socket
+	}
+	return net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+}
+
+// UsesTLS returns whether the API is served over HTTPS
+func (c ServerConfig) UsesTLS() bool {
+	return c.TLSCert != "" || c.TLSKey != ""
+}
+
+// listen opens the TCP port or Unix socket that the API is served on
+func (c ServerConfig) listen() (net.Listener, error) {
+	if c.Socket == "" {
+		return net.Listen("tcp", c.Address())
+	}
+
+	// Remove the socket left behind by a previous run, as it would prevent listening on it
+	if info, err := os.Stat(c.Socket); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%q exists and is not a socket", c.Socket)
+		}
+		if err := os.Remove(c.Socket); err != nil {
+			return nil, err
+		}
+	}
+
+	listener, err := net.Listen("unix", c.Socket)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(c.Socket, 0o660); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+// tlsConfig returns the TLS configuration for serving HTTPS, or nil if TLS is not enabled
+func (c ServerConfig) tlsConfig() (*tls.Config, error) {
+	if !c.UsesTLS() {
+		if c.TLSClientCA != "" {
+			return nil, errors.New("client certificate verification requires a TLS certificate and key")
+		}
+		return nil, nil
+	}
+	if c.TLSCert == "" || c.TLSKey == "" {
+		return nil, errors.New("both a TLS certificate and key are required")
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.TLSCert, c.TLSKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the TLS certificate: %w", err)
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if c.TLSClientCA != "" {
+		bundle, err := os.ReadFile(c.TLSClientCA)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(bundle) {
+			return nil, fmt.Errorf("no certificates found in the client CA %q", c.TLSClientCA)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return config, nil
+}
+
+// LocalClient returns a client connecting to the API served by another process on this host using the same settings,
+// and the base URL of the API. As the certificate is issued for the public name of the API, it is not verified. When
+// client certificates are required, the client certificate is presented, falling back to the server certificate, which
+// is only accepted if it is signed by the client CA and allows client authentication.
+func (c ServerConfig) LocalClient(timeout time.Duration) (*http.Client, string, error) {
+	transport := &http.Transport{}
+	client := &http.Client{Transport: transport, Timeout: timeout}
+
+	scheme := "http"
+	if c.UsesTLS() {
+		scheme = "https"
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true, MinVersion: tls.VersionTLS12}
+		if c.TLSClientCA != "" {
+			cert, err := c.localClientCertificate()
+			if err != nil {
+				return nil, "", err
+			}
+			transport.TLSClientConfig.Certificates = []tls.Certificate{cert}
+		}
+	}
+
+	if c.Socket != "" {
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", c.Socket)
+		}
+		return client, scheme + "://localhost", nil
+	}
+
+	host := c.Host
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "localhost"
+	}
+	return client, scheme + "://" + net.JoinHostPort(host, strconv.Itoa(c.Port)), nil
+}
+
+// localClientCertificate loads the certificate that the local client presents when client certificates are required
+func (c ServerConfig) localClientCertificate() (tls.Certificate, error) {
+	if c.TLSClientCert == "" && c.TLSClientKey == "" {
+		cert, err := tls.LoadX509KeyPair(c.TLSCert, c.TLSKey)
+		if err != nil {
+			return tls.Certificate{}, fmt.Errorf("failed to load the TLS certificate: %w", err)
+		}
+		return cert, nil
+	}
+	if c.TLSClientCert == "" || c.TLSClientKey == "" {
+		return tls.Certificate{}, errors.New("both a TLS client certificate and key are required")
+	}
+	cert, err := tls.LoadX509KeyPair(c.TLSClientCert, c.TLSClientKey)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to load the TLS client certificate: %w", err)
+	}
+	return cert, nil
+}
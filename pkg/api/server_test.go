@@ -0,0 +1,261 @@
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// writeCertificate writes a self-signed certificate for localhost and its key to the directory
+func writeCertificate(dir string) (certPath string, keyPath string, cert *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).NotTo(HaveOccurred())
+	cert, err = x509.ParseCertificate(der)
+	Expect(err).NotTo(HaveOccurred())
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	Expect(err).NotTo(HaveOccurred())
+
+	certPath = filepath.Join(dir, "cert.pem")
+	keyPath = filepath.Join(dir, "key.pem")
+	Expect(os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)).To(Succeed())
+	Expect(os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600)).To(Succeed())
+	return certPath, keyPath, cert
+}
+
+// writeIssuedCertificate writes a certificate for localhost issued by the CA with the extended key usage, and its key,
+// to the directory using the name as the file prefix
+func writeIssuedCertificate(dir string, name string, ca tls.Certificate, usage x509.ExtKeyUsage) (certPath string, keyPath string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	caCert, err := x509.ParseCertificate(ca.Certificate[0])
+	Expect(err).NotTo(HaveOccurred())
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, ca.PrivateKey)
+	Expect(err).NotTo(HaveOccurred())
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	Expect(err).NotTo(HaveOccurred())
+
+	certPath = filepath.Join(dir, name+"-cert.pem")
+	keyPath = filepath.Join(dir, name+"-key.pem")
+	Expect(os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)).To(Succeed())
+	Expect(os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600)).To(Succeed())
+	return certPath, keyPath
+}
+
+var _ = Describe("the API server", func() {
+	var dir string
+	var socket string
+	var api *API
+
+	// socketClient returns a client connecting to the API socket for all requests
+	socketClient := func(tlsConfig *tls.Config) *http.Client {
+		return &http.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+			},
+			TLSClientConfig: tlsConfig,
+		}}
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "watchtower-api")
+		Expect(err).NotTo(HaveOccurred())
+		socket = filepath.Join(dir, "api.sock")
+
+		api = New(token)
+		api.Server = ServerConfig{Socket: socket}
+		api.RegisterFunc("/hello", ScopeStatusRead, testHandler)
+	})
+
+	AfterEach(func() {
+		Expect(api.Stop()).To(Succeed())
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	When("listening on a Unix socket", func() {
+		It("should serve the registered handlers", func() {
+			Expect(api.Start(false)).To(Succeed())
+
+			req, _ := http.NewRequest("GET", "http://watchtower/hello", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			res, err := socketClient(nil).Do(req)
+			Expect(err).NotTo(HaveOccurred())
+			defer res.Body.Close()
+			Expect(res.StatusCode).To(Equal(http.StatusOK))
+		})
+		It("should replace a socket left behind by a previous run", func() {
+			listener, err := net.Listen("unix", socket)
+			Expect(err).NotTo(HaveOccurred())
+			listener.(*net.UnixListener).SetUnlinkOnClose(false)
+			Expect(listener.Close()).To(Succeed())
+
+			Expect(api.Start(false)).To(Succeed())
+		})
+		It("should be reachable using the local client", func() {
+			Expect(api.Start(false)).To(Succeed())
+
+			client, baseURL, err := api.Server.LocalClient(time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			req, _ := http.NewRequest("GET", baseURL+"/hello", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			res, err := client.Do(req)
+			Expect(err).NotTo(HaveOccurred())
+			defer res.Body.Close()
+			Expect(res.StatusCode).To(Equal(http.StatusOK))
+		})
+		It("should not remove other files", func() {
+			Expect(os.WriteFile(socket, []byte{}, 0o600)).To(Succeed())
+			Expect(api.Start(false)).NotTo(Succeed())
+		})
+	})
+
+	When("serving over HTTPS", func() {
+		It("should require a client certificate signed by the client CA", func() {
+			certPath, keyPath, cert := writeCertificate(dir)
+			api.Server.TLSCert = certPath
+			api.Server.TLSKey = keyPath
+			api.Server.TLSClientCA = certPath
+			Expect(api.Start(false)).To(Succeed())
+
+			pool := x509.NewCertPool()
+			pool.AddCert(cert)
+			clientCert, err := tls.LoadX509KeyPair(certPath, keyPath)
+			Expect(err).NotTo(HaveOccurred())
+
+			req, _ := http.NewRequest("GET", "https://localhost/hello", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			_, err = socketClient(&tls.Config{RootCAs: pool}).Do(req)
+			Expect(err).To(HaveOccurred())
+
+			res, err := socketClient(&tls.Config{RootCAs: pool, Certificates: []tls.Certificate{clientCert}}).Do(req)
+			Expect(err).NotTo(HaveOccurred())
+			defer res.Body.Close()
+			Expect(res.StatusCode).To(Equal(http.StatusOK))
+		})
+		It("should present the server certificate using the local client", func() {
+			certPath, keyPath, _ := writeCertificate(dir)
+			api.Server.TLSCert = certPath
+			api.Server.TLSKey = keyPath
+			api.Server.TLSClientCA = certPath
+			Expect(api.Start(false)).To(Succeed())
+
+			client, baseURL, err := api.Server.LocalClient(time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(baseURL).To(HavePrefix("https://"))
+			req, _ := http.NewRequest("GET", baseURL+"/hello", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			res, err := client.Do(req)
+			Expect(err).NotTo(HaveOccurred())
+			defer res.Body.Close()
+			Expect(res.StatusCode).To(Equal(http.StatusOK))
+		})
+		When("the server certificate is only issued for server authentication", func() {
+			var localGet func() error
+
+			BeforeEach(func() {
+				caPath, caKeyPath, _ := writeCertificate(dir)
+				ca, err := tls.LoadX509KeyPair(caPath, caKeyPath)
+				Expect(err).NotTo(HaveOccurred())
+				api.Server.TLSCert, api.Server.TLSKey = writeIssuedCertificate(dir, "server", ca, x509.ExtKeyUsageServerAuth)
+				api.Server.TLSClientCA = caPath
+				Expect(api.Start(false)).To(Succeed())
+
+				localGet = func() error {
+					client, baseURL, err := api.Server.LocalClient(time.Second)
+					if err != nil {
+						return err
+					}
+					res, err := client.Get(baseURL + "/hello")
+					if err != nil {
+						return err
+					}
+					return res.Body.Close()
+				}
+				api.Server.TLSClientCert, api.Server.TLSClientKey = writeIssuedCertificate(dir, "client", ca, x509.ExtKeyUsageClientAuth)
+			})
+
+			It("should not accept the server certificate from the local client", func() {
+				api.Server.TLSClientCert, api.Server.TLSClientKey = "", ""
+				Expect(localGet()).NotTo(Succeed())
+			})
+			It("should present the client certificate using the local client", func() {
+				Expect(localGet()).To(Succeed())
+			})
+			It("should require both a client certificate and a key", func() {
+				api.Server.TLSClientKey = ""
+				Expect(localGet()).To(MatchError(ContainSubstring("client certificate and key")))
+			})
+		})
+		It("should require both a certificate and a key", func() {
+			certPath, _, _ := writeCertificate(dir)
+			api.Server.TLSCert = certPath
+			Expect(api.Start(false)).NotTo(Succeed())
+		})
+	})
+
+	It("should serve public endpoints without a token", func() {
+		public := New("")
+		public.Server = ServerConfig{Socket: filepath.Join(dir, "public.sock")}
+		public.RegisterPublicHandler("/public", http.HandlerFunc(testHandler))
+		Expect(public.Start(false)).To(Succeed())
+		defer func() { Expect(public.Stop()).To(Succeed()) }()
+
+		client, baseURL, err := public.Server.LocalClient(time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		res, err := client.Get(baseURL + "/public")
+		Expect(err).NotTo(HaveOccurred())
+		defer res.Body.Close()
+		Expect(res.StatusCode).To(Equal(http.StatusOK))
+	})
+
+	It("should return once the API has been shut down when blocking", func() {
+		result := make(chan error)
+		go func() {
+			result <- api.Start(true)
+		}()
+		Eventually(func() error {
+			_, err := os.Stat(socket)
+			return err
+		}).Should(Succeed())
+
+		Expect(api.Stop()).To(Succeed())
+		Eventually(result).Should(Receive(MatchError(http.ErrServerClosed)))
+	})
+})
@@ -0,0 +1,113 @@
+package sessions
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containrrr/watchtower/pkg/api"
+	"github.com/containrrr/watchtower/pkg/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// maxSessions is the number of recent sessions that are kept
+const maxSessions = 50
+
+// Handler is an API handler serving the history of the recent update sessions
+type Handler struct {
+	Path     string
+	mutex    sync.RWMutex
+	sessions []Session
+}
+
+// Session is the summary of a finished update session
+type Session struct {
+	Started    time.Time         `json:"started"`
+	Finished   time.Time         `json:"finished"`
+	Error      string            `json:"error,omitempty"`
+	Containers []ContainerResult `json:"containers"`
+}
+
+// ContainerResult is the outcome of a container included in a session
+type ContainerResult struct {
+	Name  string `json:"name"`
+	Image string `json:"image"`
+	State string `json:"state"`
+	Error string `json:"error,omitempty"`
+}
+
+// New is a factory function creating a new Handler instance
+func New() *Handler {
+	return &Handler{
+		Path: "/v1/sessions",
+	}
+}
+
+// RecordSession adds the session to the history, dropping the oldest session once the history is full
+func (handle *Handler) RecordSession(report types.Report, started time.Time, finished time.Time, err error) {
+	session := Session{
+		Started:    started,
+		Finished:   finished,
+		Containers: []ContainerResult{},
+	}
+	if err != nil {
+		session.Error = err.Error()
+	}
+	if report != nil {
+		for _, c := range report.All() {
+			session.Containers = append(session.Containers, ContainerResult{
+				Name:  strings.TrimPrefix(c.Name(), "/"),
+				Image: c.ImageName(),
+				State: c.State(),
+				Error: c.Error(),
+			})
+		}
+	}
+
+	handle.mutex.Lock()
+	defer handle.mutex.Unlock()
+	handle.sessions = append(handle.sessions, session)
+	if len(handle.sessions) > maxSessions {
+		handle.sessions = handle.sessions[len(handle.sessions)-maxSessions:]
+	}
+}
+
+// Handle serves the recent sessions, most recent first
+func (handle *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	token, authenticated := api.TokenFromRequest(r)
+
+	handle.mutex.RLock()
+	sessions := make([]Session, 0, len(handle.sessions))
+	for i := len(handle.sessions) - 1; i >= 0; i-- {
+		session := handle.sessions[i]
+		if authenticated && token.IsLimited() {
+			session.Containers = allowedContainers(session.Containers, token)
+		}
+		sessions = append(sessions, session)
+	}
+	handle.mutex.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string][]Session{"sessions": sessions}); err != nil {
+		log.WithError(err).Debug("Failed to write the HTTP API response")
+	}
+}
+
+// allowedContainers returns the results of the containers that the token is limited to
+func allowedContainers(containers []ContainerResult, token api.Token) []ContainerResult {
+	allowed := make([]ContainerResult, 0, len(containers))
+	for _, c := range containers {
+		if token.AllowsContainer(c.Name, c.Image) {
+			allowed = append(allowed, c)
+		}
+	}
+	return allowed
+}
@@ -0,0 +1,93 @@
+package sessions_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/containrrr/watchtower/internal/actions/mocks"
+	"github.com/containrrr/watchtower/pkg/api"
+	"github.com/containrrr/watchtower/pkg/api/sessions"
+	"github.com/containrrr/watchtower/pkg/session"
+)
+
+func TestSessions(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Sessions API Suite")
+}
+
+var _ = Describe("the sessions API", func() {
+	var handler *sessions.Handler
+	var started = time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+
+	get := func(fn http.HandlerFunc, token string) []sessions.Session {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "http://localhost:8080/v1/sessions", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		fn(recorder, req)
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+
+		var body struct {
+			Sessions []sessions.Session `json:"sessions"`
+		}
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &body)).To(Succeed())
+		return body.Sessions
+	}
+
+	BeforeEach(func() {
+		handler = sessions.New()
+	})
+
+	It("should list the recorded sessions, most recent first", func() {
+		handler.RecordSession(mocks.CreateMockProgressReport(session.UpdatedState, session.FreshState),
+			started, started.Add(time.Second), nil)
+		handler.RecordSession(mocks.CreateMockProgressReport(session.FailedState),
+			started.Add(time.Hour), started.Add(time.Hour+time.Second), errors.New("session failed"))
+
+		list := get(handler.Handle, "")
+		Expect(list).To(HaveLen(2))
+		Expect(list[0].Started).To(Equal(started.Add(time.Hour)))
+		Expect(list[0].Error).To(Equal("session failed"))
+		Expect(list[0].Containers).To(ConsistOf(sessions.ContainerResult{
+			Name:  "fail1",
+			Image: "mock/fail1:latest",
+			State: "Failed",
+			Error: "accidentally the whole container",
+		}))
+		Expect(list[1].Finished).To(Equal(started.Add(time.Second)))
+		Expect(list[1].Containers).To(HaveLen(2))
+	})
+
+	It("should only keep the most recent sessions", func() {
+		for i := 0; i < 60; i++ {
+			at := started.Add(time.Duration(i) * time.Minute)
+			handler.RecordSession(mocks.CreateMockProgressReport(), at, at, nil)
+		}
+		list := get(handler.Handle, "")
+		Expect(list).To(HaveLen(50))
+		Expect(list[0].Started).To(Equal(started.Add(59 * time.Minute)))
+	})
+
+	It("should only include the containers that the token is limited to", func() {
+		handler.RecordSession(mocks.CreateMockProgressReport(session.UpdatedState, session.FreshState),
+			started, started, nil)
+
+		httpAPI := api.New("")
+		httpAPI.Tokens = []api.Token{{
+			Name:   "limited",
+			Token:  "limited-token",
+			Scopes: []api.Scope{api.ScopeStatusRead},
+			Images: []string{"mock/frsh1"},
+		}}
+		list := get(httpAPI.RequireScope(api.ScopeStatusRead, handler.Handle), "limited-token")
+		Expect(list).To(HaveLen(1))
+		Expect(list[0].Containers).To(HaveLen(1))
+		Expect(list[0].Containers[0].Name).To(Equal("frsh1"))
+	})
+})
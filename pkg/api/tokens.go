@@ -0,0 +1,152 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/containrrr/watchtower/pkg/filters"
+	"github.com/spf13/viper"
+)
+
+// Scope is a permission granted to an API token
+type Scope string
+
+// The scopes that the API endpoints require
+const (
+	// ScopeAll grants all the scopes
+	ScopeAll            Scope = "*"
+	ScopeMetricsRead    Scope = "metrics:read"
+	ScopeStatusRead     Scope = "status:read"
+	ScopeUpdateTrigger  Scope = "update:trigger"
+	ScopeSchedulerWrite Scope = "scheduler:write"
+)
+
+// defaultTokenName is the name of the token given using --http-api-token, which is granted all the scopes
+const defaultTokenName = "default"
+
+// Token is a named API token, granting a set of scopes for the containers and images it is limited to
+type Token struct {
+	Name  string `mapstructure:"name"`
+	Token string `mapstructure:"token"`
+	// Scopes are the permissions granted to the token
+	Scopes []Scope `mapstructure:"scopes"`
+	// Containers limits the token to the containers with these names, if not empty
+	Containers []string `mapstructure:"containers"`
+	// Images limits the token to the containers using these images, if not empty
+	Images []string `mapstructure:"images"`
+}
+
+// HasScope returns whether the token grants the scope. The empty scope is granted to all tokens.
+func (t Token) HasScope(scope Scope) bool {
+	if scope == "" {
+		return true
+	}
+	for _, s := range t.Scopes {
+		if s == scope || s == ScopeAll {
+			return true
+		}
+	}
+	return false
+}
+
+// IsLimited returns whether the token is limited to certain containers or images
+func (t Token) IsLimited() bool {
+	return len(t.Containers) > 0 || len(t.Images) > 0
+}
+
+// AllowsContainer returns whether the token may access the container with the given name and image
+func (t Token) AllowsContainer(name string, image string) bool {
+	return t.AllowsContainerName(name) && t.AllowsImage(image)
+}
+
+// AllowsContainerName returns whether the token may access the container with the given name, ignoring its image
+func (t Token) AllowsContainerName(name string) bool {
+	return len(t.Containers) == 0 || filters.ContainsName(t.Containers, name)
+}
+
+// AllowsImage returns whether the token may access containers using the image, ignoring the tag
+func (t Token) AllowsImage(image string) bool {
+	if len(t.Images) == 0 {
+		return true
+	}
+	for _, allowed := range t.Images {
+		if imageRepository(allowed) == imageRepository(image) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches returns whether the token value equals the given value, taking constant time
+func (t Token) matches(value string) bool {
+	return t.Token != "" && subtle.ConstantTimeCompare([]byte(t.Token), []byte(value)) == 1
+}
+
+// imageRepository returns the image name without the tag or digest
+func imageRepository(image string) string {
+	if at := strings.Index(image, "@"); at >= 0 {
+		image = image[:at]
+	}
+	if colon := strings.LastIndex(image, ":"); colon > strings.LastIndex(image, "/") {
+		image = image[:colon]
+	}
+	return image
+}
+
+// ReadTokensFile reads the API tokens from a YAML, JSON or TOML file. Token values that are paths to existing files
+// are replaced by the contents of the files, allowing the tokens to be kept in secrets.
+func ReadTokensFile(path string) ([]Token, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read API tokens file: %w", err)
+	}
+
+	var tokens []Token
+	if err := v.UnmarshalKey("tokens", &tokens); err != nil {
+		return nil, fmt.Errorf("failed to parse API tokens file: %w", err)
+	}
+
+	names := make(map[string]bool, len(tokens))
+	for i, token := range tokens {
+		if token.Name == "" {
+			return nil, fmt.Errorf("API token %d has no name", i+1)
+		}
+		if names[token.Name] {
+			return nil, fmt.Errorf("API token %q is defined more than once", token.Name)
+		}
+		names[token.Name] = true
+
+		if info, err := os.Stat(token.Token); err == nil && !info.IsDir() {
+			content, err := os.ReadFile(token.Token)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read API token %q: %w", token.Name, err)
+			}
+			tokens[i].Token = strings.TrimSpace(string(content))
+		}
+		if tokens[i].Token == "" {
+			return nil, fmt.Errorf("API token %q has no token value", token.Name)
+		}
+		if len(token.Scopes) == 0 {
+			return nil, fmt.Errorf("API token %q has no scopes", token.Name)
+		}
+	}
+	return tokens, nil
+}
+
+type tokenContextKey struct{}
+
+// TokenFromRequest returns the token that the request was authenticated with
+func TokenFromRequest(r *http.Request) (Token, bool) {
+	token, found := r.Context().Value(tokenContextKey{}).(Token)
+	return token, found
+}
+
+// withToken returns the request with the token that it was authenticated with added to its context
+func withToken(r *http.Request, token Token) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), tokenContextKey{}, token))
+}
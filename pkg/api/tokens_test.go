@@ -0,0 +1,179 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("API tokens", func() {
+	var api *API
+	var audit bytes.Buffer
+
+	request := func(scope Scope, value string) int {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/hello", nil)
+		if value != "" {
+			req.Header.Set("Authorization", "Bearer "+value)
+		}
+		api.RequireScope(scope, testHandler)(rec, req)
+		return rec.Code
+	}
+
+	auditEntries := func() []AuditEntry {
+		var entries []AuditEntry
+		decoder := json.NewDecoder(&audit)
+		for decoder.More() {
+			var entry AuditEntry
+			Expect(decoder.Decode(&entry)).To(Succeed())
+			entries = append(entries, entry)
+		}
+		return entries
+	}
+
+	BeforeEach(func() {
+		audit.Reset()
+		api = New("")
+		api.Audit = NewAuditLogWriter(&audit)
+		api.Tokens = []Token{
+			{Name: "prometheus", Token: "metrics-token", Scopes: []Scope{ScopeMetricsRead}},
+			{Name: "ci", Token: "ci-token", Scopes: []Scope{ScopeUpdateTrigger, ScopeStatusRead}},
+		}
+	})
+
+	It("should allow requests with a token granting the scope", func() {
+		Expect(request(ScopeMetricsRead, "metrics-token")).To(Equal(http.StatusOK))
+		Expect(request(ScopeUpdateTrigger, "ci-token")).To(Equal(http.StatusOK))
+	})
+
+	It("should forbid requests with a token lacking the scope", func() {
+		Expect(request(ScopeUpdateTrigger, "metrics-token")).To(Equal(http.StatusForbidden))
+	})
+
+	It("should reject requests without a valid token", func() {
+		Expect(request(ScopeMetricsRead, "")).To(Equal(http.StatusUnauthorized))
+		Expect(request(ScopeMetricsRead, "unknown")).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should grant all the scopes to the default token", func() {
+		api.Token = token
+		Expect(request(ScopeSchedulerWrite, token)).To(Equal(http.StatusOK))
+	})
+
+	It("should record every request in the audit log", func() {
+		request(ScopeMetricsRead, "metrics-token")
+		request(ScopeUpdateTrigger, "metrics-token")
+		request(ScopeMetricsRead, "unknown")
+
+		entries := auditEntries()
+		Expect(entries).To(HaveLen(3))
+		Expect(entries[0].Token).To(Equal("prometheus"))
+		Expect(entries[0].Result).To(Equal("allowed"))
+		Expect(entries[0].URI).To(Equal("/hello"))
+		Expect(entries[1].Token).To(Equal("prometheus"))
+		Expect(entries[1].Result).To(Equal("forbidden"))
+		Expect(entries[2].Token).To(BeEmpty())
+		Expect(entries[2].Result).To(Equal("unauthorized"))
+	})
+
+	It("should mask secrets passed as query parameters in the audit log", func() {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/hello?token=s3cr3t&source=ci", nil)
+		api.RequireScope(ScopeMetricsRead, testHandler)(rec, req)
+
+		entries := auditEntries()
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].URI).NotTo(ContainSubstring("s3cr3t"))
+		Expect(entries[0].URI).To(Equal("/hello?source=ci&token=REDACTED"))
+	})
+
+	It("should mask the webhook secret in the audit log", func() {
+		handler := func(w http.ResponseWriter, r *http.Request) {}
+		api.RegisterExternalFunc("/v1/webhook", "webhook", handler)
+		recorder := httptest.NewRecorder()
+		api.Handler().ServeHTTP(recorder, httptest.NewRequest("POST", "http://localhost:8080/v1/webhook?secret=s3cr3t&source=hub", nil))
+
+		entries := auditEntries()
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].URI).NotTo(ContainSubstring("s3cr3t"))
+		Expect(entries[0].URI).To(Equal("/v1/webhook?secret=REDACTED&source=hub"))
+	})
+
+	Describe("the container and image limits", func() {
+		limited := Token{Containers: []string{"web"}, Images: []string{"foo/bar"}}
+
+		It("should only allow the listed containers and images", func() {
+			Expect(limited.AllowsContainer("/web", "foo/bar:latest")).To(BeTrue())
+			Expect(limited.AllowsContainer("db", "foo/bar:latest")).To(BeFalse())
+			Expect(limited.AllowsContainer("web", "foo/baz")).To(BeFalse())
+		})
+
+		It("should ignore the image tag and digest", func() {
+			Expect(limited.AllowsImage("foo/bar:1.2")).To(BeTrue())
+			Expect(limited.AllowsImage("foo/bar@sha256:abc")).To(BeTrue())
+			Expect(Token{Images: []string{"localhost:5000/foo"}}.AllowsImage("localhost:5000/foo:1")).To(BeTrue())
+		})
+	})
+
+	Describe("reading the tokens file", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = os.MkdirTemp("", "watchtower-tokens")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		writeFile := func(name string, content string) string {
+			path := filepath.Join(dir, name)
+			Expect(os.WriteFile(path, []byte(content), 0o600)).To(Succeed())
+			return path
+		}
+
+		It("should read the tokens, using the contents of referenced files", func() {
+			secret := writeFile("secret", "s3cr3t\n")
+			path := writeFile("tokens.yaml", `
+tokens:
+  - name: prometheus
+    token: `+secret+`
+    scopes: [metrics:read]
+  - name: ci
+    token: ci-token
+    scopes: [update:trigger]
+    images: [foo/bar]
+`)
+			tokens, err := ReadTokensFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tokens).To(HaveLen(2))
+			Expect(tokens[0].Token).To(Equal("s3cr3t"))
+			Expect(tokens[0].Scopes).To(Equal([]Scope{ScopeMetricsRead}))
+			Expect(tokens[1].Images).To(Equal([]string{"foo/bar"}))
+		})
+
+		It("should reject tokens without scopes", func() {
+			path := writeFile("tokens.yaml", "tokens:\n  - name: ci\n    token: ci-token\n")
+			_, err := ReadTokensFile(path)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should reject duplicate token names", func() {
+			path := writeFile("tokens.yaml", `
+tokens:
+  - {name: ci, token: a, scopes: [status:read]}
+  - {name: ci, token: b, scopes: [status:read]}
+`)
+			_, err := ReadTokensFile(path)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
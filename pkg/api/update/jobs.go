@@ -0,0 +1,264 @@
+package update
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/containrrr/watchtower/pkg/filters"
+	"github.com/containrrr/watchtower/pkg/notifications"
+	"github.com/containrrr/watchtower/pkg/types"
+)
+
+// ErrJobNotFound is returned when waiting for a job that is not known, or has been removed after finishing
+var ErrJobNotFound = errors.New("job not found")
+
+// The states that an update job goes through
+const (
+	JobQueued  = "queued"
+	JobRunning = "running"
+	JobDone    = "done"
+)
+
+// maxFinishedJobs is the number of finished jobs that are kept for retrieving their results
+const maxFinishedJobs = 100
+
+// UpdateFunc runs an update session for the containers selected by the target
+type UpdateFunc func(target Target) (types.Report, error)
+
+// Target selects the containers that are updated by a job, as the containers matching both the images and the
+// container names. A nil list does not restrict the containers, so the empty target selects all the containers.
+type Target struct {
+	Images     []string
+	Containers []string
+}
+
+// IsAll returns whether the target selects all the containers
+func (t Target) IsAll() bool {
+	return t.Images == nil && t.Containers == nil
+}
+
+// Filter returns a filter selecting the containers of the target among the ones selected by the base filter. The
+// container names are matched exactly, as they may come from the limits of an API token.
+func (t Target) Filter(baseFilter types.Filter) types.Filter {
+	return filters.FilterByImage(t.Images, filters.FilterByExactNames(t.Containers, baseFilter))
+}
+
+// merge returns a target selecting the containers of both targets, if it can be expressed as a single target
+func (t Target) merge(other Target) (Target, bool) {
+	switch {
+	case t.IsAll() || other.IsAll():
+		return Target{}, true
+	case t.Containers == nil && other.Containers == nil:
+		return Target{Images: uniqueValues(t.Images, other.Images)}, true
+	case t.Images == nil && other.Images == nil:
+		return Target{Containers: uniqueValues(t.Containers, other.Containers)}, true
+	}
+	return Target{}, false
+}
+
+// Job is an update session triggered by an API request
+type Job struct {
+	ID string
+	Target
+	State    string
+	Created  time.Time
+	Started  time.Time
+	Finished time.Time
+	Report   types.Report
+	Error    error
+	done     chan struct{}
+}
+
+// Succeeded returns whether the job finished without errors and without any of the containers failing to update
+func (job Job) Succeeded() bool {
+	return job.State == JobDone && job.Error == nil && (job.Report == nil || len(job.Report.Failed()) == 0)
+}
+
+// MarshalJSON implements json.Marshaler
+func (job Job) MarshalJSON() ([]byte, error) {
+	data := map[string]interface{}{
+		`id`:         job.ID,
+		`state`:      job.State,
+		`images`:     job.Images,
+		`containers`: job.Containers,
+		`created`:    job.Created,
+	}
+	if !job.Started.IsZero() {
+		data[`started`] = job.Started
+	}
+	if job.State == JobDone {
+		data[`finished`] = job.Finished
+		data[`succeeded`] = job.Succeeded()
+		if job.Error != nil {
+			data[`error`] = job.Error.Error()
+		}
+		if job.Report != nil {
+			data[`report`] = notifications.ReportJSON(job.Report)
+		}
+	}
+	return json.Marshal(data)
+}
+
+// Queue runs the triggered update jobs one at a time. Jobs triggered while other jobs are waiting to run are coalesced
+// into a waiting job when possible, as it will include the current state of all the requested containers when it runs.
+type Queue struct {
+	fn       UpdateFunc
+	lock     chan bool
+	mutex    sync.Mutex
+	jobs     map[string]*Job
+	finished []string
+	pending  []*Job
+	wake     chan struct{}
+}
+
+// NewQueue creates a new Queue running the jobs using fn, while holding the lock shared with the scheduler
+func NewQueue(fn UpdateFunc, updateLock chan bool) *Queue {
+	if updateLock == nil {
+		updateLock = make(chan bool, 1)
+		updateLock <- true
+	}
+
+	queue := &Queue{
+		fn:   fn,
+		lock: updateLock,
+		jobs: make(map[string]*Job),
+		wake: make(chan struct{}, 1),
+	}
+	go queue.run()
+	return queue
+}
+
+// Add queues an update of the containers selected by the target, and returns the job that will perform it
+func (queue *Queue) Add(target Target) Job {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+
+	target = Target{Images: uniqueValues(target.Images), Containers: uniqueValues(target.Containers)}
+	for _, job := range queue.pending {
+		if merged, ok := job.Target.merge(target); ok {
+			job.Target = merged
+			return *job
+		}
+	}
+
+	job := &Job{
+		ID:      newJobID(),
+		Target:  target,
+		State:   JobQueued,
+		Created: time.Now(),
+		done:    make(chan struct{}),
+	}
+	queue.jobs[job.ID] = job
+	queue.pending = append(queue.pending, job)
+
+	select {
+	case queue.wake <- struct{}{}:
+	default:
+	}
+	return *job
+}
+
+// Get returns the job with the given ID, if it is still known
+func (queue *Queue) Get(id string) (Job, bool) {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+
+	job, found := queue.jobs[id]
+	if !found {
+		return Job{}, false
+	}
+	return *job, true
+}
+
+// Wait blocks until the job with the given ID has finished, or the context is done, and returns the job
+func (queue *Queue) Wait(ctx context.Context, id string) (Job, error) {
+	queue.mutex.Lock()
+	job, found := queue.jobs[id]
+	queue.mutex.Unlock()
+	if !found {
+		return Job{}, ErrJobNotFound
+	}
+
+	select {
+	case <-job.done:
+	case <-ctx.Done():
+		return Job{}, ctx.Err()
+	}
+
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+	return *job, nil
+}
+
+func (queue *Queue) run() {
+	for range queue.wake {
+		for queue.runNext() {
+		}
+	}
+}
+
+// runNext runs the first waiting job, and returns whether there was one
+func (queue *Queue) runNext() bool {
+	lockValue := <-queue.lock
+	defer func() { queue.lock <- lockValue }()
+
+	queue.mutex.Lock()
+	if len(queue.pending) == 0 {
+		queue.mutex.Unlock()
+		return false
+	}
+	job := queue.pending[0]
+	queue.pending = queue.pending[1:]
+	job.State = JobRunning
+	job.Started = time.Now()
+	queue.mutex.Unlock()
+
+	report, err := queue.fn(job.Target)
+	queue.finish(job, report, err)
+	return true
+}
+
+func (queue *Queue) finish(job *Job, report types.Report, err error) {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+
+	job.State = JobDone
+	job.Finished = time.Now()
+	job.Report = report
+	job.Error = err
+	close(job.done)
+
+	queue.finished = append(queue.finished, job.ID)
+	if len(queue.finished) > maxFinishedJobs {
+		delete(queue.jobs, queue.finished[0])
+		queue.finished = queue.finished[1:]
+	}
+}
+
+// uniqueValues returns the sorted unique values of the lists, or nil if there are none
+func uniqueValues(lists ...[]string) []string {
+	found := make(map[string]bool)
+	var unique []string
+	for _, list := range lists {
+		for _, value := range list {
+			if !found[value] {
+				found[value] = true
+				unique = append(unique, value)
+			}
+		}
+	}
+	sort.Strings(unique)
+	return unique
+}
+
+func newJobID() string {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
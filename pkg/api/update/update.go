@@ -1,41 +1,51 @@
 package update
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 
+	"github.com/containrrr/watchtower/pkg/api"
+	"github.com/containrrr/watchtower/pkg/types"
 	log "github.com/sirupsen/logrus"
 )
 
-var (
-	lock chan bool
-)
-
 // New is a factory function creating a new  Handler instance
-func New(updateFn func(images []string), updateLock chan bool) *Handler {
-	if updateLock != nil {
-		lock = updateLock
-	} else {
-		lock = make(chan bool, 1)
-		lock <- true
-	}
+func New(updateFn UpdateFunc, updateLock chan bool) *Handler {
+	return NewWithQueue(NewQueue(updateFn, updateLock))
+}
 
+// NewWithQueue creates a new Handler instance adding the jobs to the queue, allowing it to be shared with other
+// handlers triggering updates
+func NewWithQueue(queue *Queue) *Handler {
 	return &Handler{
-		fn:   updateFn,
-		Path: "/v1/update",
+		queue:    queue,
+		Path:     "/v1/update",
+		JobsPath: "/v1/jobs/",
 	}
 }
 
 // Handler is an API handler used for triggering container update scans
 type Handler struct {
-	fn   func(images []string)
-	Path string
+	queue    *Queue
+	Path     string
+	JobsPath string
 }
 
 // Handle is the actual http.Handle function doing all the heavy lifting
 func (handle *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	// Updates change the containers, so they are only triggered by POST requests
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
 	log.Info("Updates triggered by HTTP API request.")
 
 	_, err := io.Copy(os.Stdout, r.Body)
@@ -55,18 +65,164 @@ func (handle *Handler) Handle(w http.ResponseWriter, r *http.Request) {
 		images = nil
 	}
 
-	if len(images) > 0 {
-		chanValue := <-lock
-		defer func() { lock <- chanValue }()
-		handle.fn(images)
-	} else {
-		select {
-		case chanValue := <-lock:
-			defer func() { lock <- chanValue }()
-			handle.fn(images)
-		default:
-			log.Debug("Skipped. Another update already running.")
+	var containers []string
+	for _, container := range r.URL.Query()["container"] {
+		containers = append(containers, strings.Split(container, ",")...)
+	}
+
+	target := Target{Images: images, Containers: containers}
+	if token, found := api.TokenFromRequest(r); found && token.IsLimited() {
+		if target, err = limitTarget(target, token); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+	}
+
+	job := handle.queue.Add(target)
+	log.WithField("job", job.ID).Debug("Update job queued.")
+
+	if wait(r) {
+		handle.writeFinishedJob(w, r, job.ID)
+		return
+	}
+
+	w.Header().Set("Location", handle.JobsPath+job.ID)
+	writeJob(w, r, http.StatusAccepted, job)
+}
+
+// HandleJob serves the status of the update job with the ID given in the path
+func (handle *Handler) HandleJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, handle.JobsPath), "/")
+	if wait(r) {
+		handle.writeFinishedJob(w, r, id)
+		return
+	}
+
+	job, found := handle.queue.Get(id)
+	if !found {
+		http.Error(w, ErrJobNotFound.Error(), http.StatusNotFound)
+		return
+	}
+	writeJob(w, r, http.StatusOK, job)
+}
+
+// writeFinishedJob waits for the job to finish and writes it, unless the request is cancelled first
+func (handle *Handler) writeFinishedJob(w http.ResponseWriter, r *http.Request, id string) {
+	job, err := handle.queue.Wait(r.Context(), id)
+	if errors.Is(err, ErrJobNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.WithError(err).WithField("job", id).Debug("Stopped waiting for the update job.")
+		return
+	}
+	writeJob(w, r, http.StatusOK, job)
+}
+
+// limitTarget restricts the target to the containers and images that the token is limited to
+func limitTarget(target Target, token api.Token) (Target, error) {
+	if len(token.Images) > 0 {
+		if len(target.Images) == 0 {
+			target.Images = token.Images
+		}
+		for _, image := range target.Images {
+			if !token.AllowsImage(image) {
+				return target, fmt.Errorf("the token does not allow updating the image %q", image)
+			}
+		}
+	}
+	if len(token.Containers) > 0 {
+		if len(target.Containers) == 0 {
+			target.Containers = token.Containers
+		}
+		for _, container := range target.Containers {
+			if !token.AllowsContainerName(container) {
+				return target, fmt.Errorf("the token does not allow updating the container %q", container)
+			}
+		}
+	}
+	return target, nil
+}
+
+// limitJob restricts the job to the containers and images that the token is limited to. Jobs are shared between the
+// requests that were coalesced into them, so they can include containers that the token may not access.
+func limitJob(job Job, token api.Token) Job {
+	job.Images = allowedValues(job.Images, token.Images, token.AllowsImage)
+	job.Containers = allowedValues(job.Containers, token.Containers, token.AllowsContainerName)
+	if job.Report != nil {
+		job.Report = limitedReport{report: job.Report, token: token}
+	}
+	return job
+}
+
+// allowedValues returns the values that are allowed, or the limits if the values are nil, as it stands for all values
+func allowedValues(values []string, limits []string, allows func(string) bool) []string {
+	if len(limits) == 0 {
+		return values
+	}
+	if values == nil {
+		return limits
+	}
+	allowed := []string{}
+	for _, value := range values {
+		if allows(value) {
+			allowed = append(allowed, value)
+		}
+	}
+	return allowed
+}
+
+// limitedReport is a session report only including the containers that the token may access
+type limitedReport struct {
+	report types.Report
+	token  api.Token
+}
+
+func (r limitedReport) filter(reports []types.ContainerReport) []types.ContainerReport {
+	allowed := []types.ContainerReport{}
+	for _, report := range reports {
+		if r.token.AllowsContainer(report.Name(), report.ImageName()) {
+			allowed = append(allowed, report)
 		}
 	}
+	return allowed
+}
+
+func (r limitedReport) Scanned() []types.ContainerReport  { return r.filter(r.report.Scanned()) }
+func (r limitedReport) Updated() []types.ContainerReport  { return r.filter(r.report.Updated()) }
+func (r limitedReport) Failed() []types.ContainerReport   { return r.filter(r.report.Failed()) }
+func (r limitedReport) Skipped() []types.ContainerReport  { return r.filter(r.report.Skipped()) }
+func (r limitedReport) Stale() []types.ContainerReport    { return r.filter(r.report.Stale()) }
+func (r limitedReport) Fresh() []types.ContainerReport    { return r.filter(r.report.Fresh()) }
+func (r limitedReport) Rejected() []types.ContainerReport { return r.filter(r.report.Rejected()) }
+func (r limitedReport) Unknown() []types.ContainerReport  { return r.filter(r.report.Unknown()) }
+func (r limitedReport) All() []types.ContainerReport      { return r.filter(r.report.All()) }
+
+// wait returns whether the request asks for the response to be delayed until the job has finished
+func wait(r *http.Request) bool {
+	wait, _ := strconv.ParseBool(r.URL.Query().Get("wait"))
+	return wait
+}
 
+// writeJob writes the job, limited to the containers and images that the token of the request may access
+func writeJob(w http.ResponseWriter, r *http.Request, status int, job Job) {
+	if token, found := api.TokenFromRequest(r); found && token.IsLimited() {
+		job = limitJob(job, token)
+	}
+	writeJSON(w, status, job)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.WithError(err).Debug("Failed to write the HTTP API response")
+	}
 }
@@ -0,0 +1,226 @@
+package update_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/containrrr/watchtower/internal/actions/mocks"
+	"github.com/containrrr/watchtower/pkg/api"
+	"github.com/containrrr/watchtower/pkg/api/update"
+	"github.com/containrrr/watchtower/pkg/filters"
+	"github.com/containrrr/watchtower/pkg/session"
+	"github.com/containrrr/watchtower/pkg/types"
+)
+
+func TestUpdate(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Update API Suite")
+}
+
+type jobResponse struct {
+	ID         string                 `json:"id"`
+	State      string                 `json:"state"`
+	Images     []string               `json:"images"`
+	Containers []string               `json:"containers"`
+	Succeeded  bool                   `json:"succeeded"`
+	Error      string                 `json:"error"`
+	Report     map[string]interface{} `json:"report"`
+}
+
+var _ = Describe("the update API", func() {
+	var handler *update.Handler
+	var lock chan bool
+	var mutex sync.Mutex
+	var calls [][]string
+	var targets []update.Target
+	var report types.Report
+
+	request := func(fn http.HandlerFunc, method string, url string) (*httptest.ResponseRecorder, jobResponse) {
+		recorder := httptest.NewRecorder()
+		fn(recorder, httptest.NewRequest(method, url, nil))
+		var job jobResponse
+		if recorder.Header().Get("Content-Type") == "application/json" {
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &job)).To(Succeed())
+		}
+		return recorder, job
+	}
+
+	BeforeEach(func() {
+		calls = nil
+		targets = nil
+		report = mocks.CreateMockProgressReport(session.UpdatedState, session.FreshState)
+		lock = make(chan bool, 1)
+		lock <- true
+		handler = update.New(func(target update.Target) (types.Report, error) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			calls = append(calls, target.Images)
+			targets = append(targets, target)
+			return report, nil
+		}, lock)
+	})
+
+	It("should return the ID of the queued job", func() {
+		res, job := request(handler.Handle, "POST", "http://localhost:8080/v1/update")
+		Expect(res.Code).To(Equal(http.StatusAccepted))
+		Expect(job.ID).NotTo(BeEmpty())
+		Expect(res.Header().Get("Location")).To(Equal("/v1/jobs/" + job.ID))
+
+		res, job = request(handler.HandleJob, "GET", "http://localhost:8080/v1/jobs/"+job.ID+"?wait=true")
+		Expect(res.Code).To(Equal(http.StatusOK))
+		Expect(job.State).To(Equal(update.JobDone))
+		Expect(job.Succeeded).To(BeTrue())
+		Expect(job.Report["updated"]).To(HaveLen(1))
+		Expect(job.Report["fresh"]).To(HaveLen(1))
+	})
+
+	It("should wait for the job to finish when requested", func() {
+		res, job := request(handler.Handle, "POST", "http://localhost:8080/v1/update?image=foo/bar&wait=true")
+		Expect(res.Code).To(Equal(http.StatusOK))
+		Expect(job.State).To(Equal(update.JobDone))
+		Expect(job.Images).To(Equal([]string{"foo/bar"}))
+		Expect(calls).To(Equal([][]string{{"foo/bar"}}))
+	})
+
+	It("should only allow POST requests", func() {
+		res, _ := request(handler.Handle, "GET", "http://localhost:8080/v1/update")
+		Expect(res.Code).To(Equal(http.StatusMethodNotAllowed))
+		Expect(res.Header().Get("Allow")).To(Equal(http.MethodPost))
+		Expect(calls).To(BeEmpty())
+	})
+
+	It("should only update the requested containers", func() {
+		res, _ := request(handler.Handle, "POST", "http://localhost:8080/v1/update?container=web,db&wait=true")
+		Expect(res.Code).To(Equal(http.StatusOK))
+		Expect(targets).To(Equal([]update.Target{{Containers: []string{"db", "web"}}}))
+	})
+
+	When("using a token limited to certain containers", func() {
+		var httpAPI *api.API
+		var limited http.HandlerFunc
+
+		withToken := func(value string, fn http.HandlerFunc) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				r.Header.Set("Authorization", "Bearer "+value)
+				httpAPI.RequireScope(api.ScopeUpdateTrigger, fn)(w, r)
+			}
+		}
+
+		BeforeEach(func() {
+			httpAPI = api.New("")
+			httpAPI.Tokens = []api.Token{{
+				Name:       "limited",
+				Token:      "limited-token",
+				Scopes:     []api.Scope{api.ScopeUpdateTrigger},
+				Containers: []string{"web"},
+			}, {
+				Name:       "updater",
+				Token:      "updater-token",
+				Scopes:     []api.Scope{api.ScopeUpdateTrigger},
+				Containers: []string{"updt1"},
+			}}
+			limited = withToken("limited-token", handler.Handle)
+		})
+
+		It("should only show the containers the token is limited to in the jobs of other requests", func() {
+			_, job := request(handler.Handle, "POST", "http://localhost:8080/v1/update?wait=true")
+			Expect(job.Report["updated"]).To(HaveLen(1))
+			Expect(job.Report["fresh"]).To(HaveLen(1))
+
+			res, limitedJob := request(withToken("updater-token", handler.HandleJob), "GET", "http://localhost:8080/v1/jobs/"+job.ID)
+			Expect(res.Code).To(Equal(http.StatusOK))
+			Expect(limitedJob.Containers).To(Equal([]string{"updt1"}))
+			Expect(limitedJob.Report["updated"]).To(HaveLen(1))
+			Expect(limitedJob.Report["fresh"]).To(BeEmpty())
+			Expect(limitedJob.Report["scanned"]).To(HaveLen(1))
+
+			_, limitedJob = request(withToken("limited-token", handler.HandleJob), "GET", "http://localhost:8080/v1/jobs/"+job.ID)
+			Expect(limitedJob.Containers).To(Equal([]string{"web"}))
+			Expect(limitedJob.Report["updated"]).To(BeEmpty())
+			Expect(limitedJob.Report["scanned"]).To(BeEmpty())
+		})
+
+		It("should only update the containers the token is limited to", func() {
+			res, _ := request(limited, "POST", "http://localhost:8080/v1/update?wait=true")
+			Expect(res.Code).To(Equal(http.StatusOK))
+			Expect(targets).To(Equal([]update.Target{{Containers: []string{"web"}}}))
+		})
+
+		It("should not update containers whose names only contain the allowed name", func() {
+			res, _ := request(limited, "POST", "http://localhost:8080/v1/update?wait=true")
+			Expect(res.Code).To(Equal(http.StatusOK))
+			Expect(targets).To(HaveLen(1))
+
+			filter := targets[0].Filter(filters.NoFilter)
+			Expect(filter(mocks.CreateMockContainer("web", "/web", "nginx:latest", time.Now()))).To(BeTrue())
+			Expect(filter(mocks.CreateMockContainer("web1", "/web1", "nginx:latest", time.Now()))).To(BeFalse())
+			Expect(filter(mocks.CreateMockContainer("webx", "/webx", "nginx:latest", time.Now()))).To(BeFalse())
+
+			res, _ = request(limited, "POST", "http://localhost:8080/v1/update?container=web1")
+			Expect(res.Code).To(Equal(http.StatusForbidden))
+		})
+
+		It("should forbid updating other containers", func() {
+			res, _ := request(limited, "POST", "http://localhost:8080/v1/update?container=db")
+			Expect(res.Code).To(Equal(http.StatusForbidden))
+			Expect(targets).To(BeEmpty())
+		})
+	})
+
+	It("should report the job as failed if any of the containers failed to update", func() {
+		report = mocks.CreateMockProgressReport(session.UpdatedState, session.FailedState)
+		_, job := request(handler.Handle, "POST", "http://localhost:8080/v1/update?wait=true")
+		Expect(job.Succeeded).To(BeFalse())
+		Expect(job.Report["failed"]).To(HaveLen(1))
+	})
+
+	It("should return not found for unknown jobs", func() {
+		res, _ := request(handler.HandleJob, "GET", "http://localhost:8080/v1/jobs/unknown")
+		Expect(res.Code).To(Equal(http.StatusNotFound))
+		res, _ = request(handler.HandleJob, "GET", "http://localhost:8080/v1/jobs/unknown?wait=true")
+		Expect(res.Code).To(Equal(http.StatusNotFound))
+	})
+
+	When("another update is running", func() {
+		var lockValue bool
+
+		BeforeEach(func() {
+			lockValue = <-lock
+		})
+
+		It("should queue the job until the update has finished", func() {
+			_, job := request(handler.Handle, "POST", "http://localhost:8080/v1/update")
+			Consistently(func() string {
+				_, job := request(handler.HandleJob, "GET", "http://localhost:8080/v1/jobs/"+job.ID)
+				return job.State
+			}, "50ms").Should(Equal(update.JobQueued))
+
+			lock <- lockValue
+			_, job = request(handler.HandleJob, "GET", "http://localhost:8080/v1/jobs/"+job.ID+"?wait=true")
+			Expect(job.State).To(Equal(update.JobDone))
+		})
+
+		It("should coalesce the queued jobs", func() {
+			_, first := request(handler.Handle, "POST", "http://localhost:8080/v1/update?image=foo/bar")
+			_, second := request(handler.Handle, "POST", "http://localhost:8080/v1/update?image=foo/baz,foo/bar")
+			Expect(second.ID).To(Equal(first.ID))
+			Expect(second.Images).To(Equal([]string{"foo/bar", "foo/baz"}))
+
+			_, third := request(handler.Handle, "POST", "http://localhost:8080/v1/update")
+			Expect(third.ID).To(Equal(first.ID))
+			Expect(third.Images).To(BeNil())
+
+			lock <- lockValue
+			_, job := request(handler.HandleJob, "GET", "http://localhost:8080/v1/jobs/"+first.ID+"?wait=true")
+			Expect(job.State).To(Equal(update.JobDone))
+			Expect(calls).To(Equal([][]string{nil}))
+		})
+	})
+})
@@ -0,0 +1,177 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/distribution/reference"
+)
+
+// ErrUnknownPayload is returned for payloads that are not in any of the supported formats
+var ErrUnknownPayload = errors.New("unknown push event payload format")
+
+// Push is an image pushed to a registry
+type Push struct {
+	// Repository is the name of the image, including the registry host unless pushed to Docker Hub
+	Repository string `json:"repository"`
+	Tag        string `json:"tag,omitempty"`
+}
+
+// payload contains the fields used from all the supported formats
+type payload struct {
+	// Docker Hub
+	PushData *struct {
+		Tag string `json:"tag"`
+	} `json:"push_data"`
+	Repository struct {
+		RepoName string `json:"repo_name"`
+	} `json:"repository"`
+
+	// Harbor
+	Type      string `json:"type"`
+	EventData *struct {
+		Resources []struct {
+			Tag         string `json:"tag"`
+			ResourceURL string `json:"resource_url"`
+		} `json:"resources"`
+	} `json:"event_data"`
+
+	// GitHub, using either the package or the legacy registry package events
+	Action          string         `json:"action"`
+	Package         *githubPackage `json:"package"`
+	RegistryPackage *githubPackage `json:"registry_package"`
+
+	// CNCF distribution registry, also used by GitLab
+	Events []struct {
+		Action string `json:"action"`
+		Target struct {
+			MediaType  string `json:"mediaType"`
+			Repository string `json:"repository"`
+			Tag        string `json:"tag"`
+		} `json:"target"`
+		Request struct {
+			Host string `json:"host"`
+		} `json:"request"`
+	} `json:"events"`
+}
+
+type githubPackage struct {
+	Name           string `json:"name"`
+	PackageType    string `json:"package_type"`
+	PackageVersion struct {
+		PackageURL        string `json:"package_url"`
+		ContainerMetadata struct {
+			Tag struct {
+				Name string `json:"name"`
+			} `json:"tag"`
+		} `json:"container_metadata"`
+	} `json:"package_version"`
+	Owner struct {
+		Login string `json:"login"`
+	} `json:"owner"`
+}
+
+// ParsePushes returns the images pushed according to a Docker Hub, Harbor, GitHub or distribution registry event.
+// Events that do not push any images, such as pulls, return no pushes.
+func ParsePushes(body []byte) ([]Push, error) {
+	var p payload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case p.PushData != nil:
+		if p.Repository.RepoName == "" {
+			return nil, ErrUnknownPayload
+		}
+		return []Push{{Repository: p.Repository.RepoName, Tag: p.PushData.Tag}}, nil
+
+	case p.EventData != nil:
+		if p.Type != "PUSH_ARTIFACT" {
+			return nil, nil
+		}
+		var pushes []Push
+		for _, resource := range p.EventData.Resources {
+			pushes = append(pushes, pushFromReference(resource.ResourceURL, resource.Tag))
+		}
+		return pushes, nil
+
+	case p.Package != nil || p.RegistryPackage != nil:
+		pkg := p.Package
+		if pkg == nil {
+			pkg = p.RegistryPackage
+		}
+		if !strings.EqualFold(pkg.PackageType, "container") || (p.Action != "published" && p.Action != "updated") {
+			return nil, nil
+		}
+		tag := pkg.PackageVersion.ContainerMetadata.Tag.Name
+		if url := pkg.PackageVersion.PackageURL; url != "" {
+			return []Push{pushFromReference(url, tag)}, nil
+		}
+		repository := "ghcr.io/" + strings.ToLower(pkg.Owner.Login) + "/" + strings.ToLower(pkg.Name)
+		return []Push{{Repository: repository, Tag: tag}}, nil
+
+	case p.Events != nil:
+		var pushes []Push
+		for _, event := range p.Events {
+			target := event.Target
+			// Pushing an image also sends events for each of its layers, which are left out
+			if event.Action != "push" || (target.Tag == "" && !strings.Contains(target.MediaType, "manifest")) {
+				continue
+			}
+			repository := target.Repository
+			if event.Request.Host != "" {
+				repository = event.Request.Host + "/" + repository
+			}
+			pushes = append(pushes, Push{Repository: repository, Tag: target.Tag})
+		}
+		return pushes, nil
+	}
+
+	return nil, ErrUnknownPayload
+}
+
+// pushFromReference returns the push of an image reference, such as registry.example.com/library/app:v1
+func pushFromReference(ref string, tag string) Push {
+	ref = strings.TrimSuffix(ref, ":")
+	if at := strings.Index(ref, "@"); at >= 0 {
+		ref = ref[:at]
+	}
+	if colon := strings.LastIndex(ref, ":"); colon > strings.LastIndex(ref, "/") {
+		if tag == "" {
+			tag = ref[colon+1:]
+		}
+		ref = ref[:colon]
+	}
+	return Push{Repository: ref, Tag: tag}
+}
+
+// ImageNames returns the names that containers may refer to the pushed image by, including the pushed tag if any, so that
+// only the containers using that tag are updated. Images on Docker Hub can be referred to both with and without the
+// registry and library prefixes.
+func (push Push) ImageNames() []string {
+	names := []string{push.Repository}
+	if named, err := reference.ParseNormalizedNamed(push.Repository); err == nil {
+		familiar := reference.FamiliarName(named)
+		names = []string{familiar}
+		if reference.Domain(named) == "docker.io" {
+			path := reference.Path(named)
+			names = append(names, path, "docker.io/"+familiar, "docker.io/"+path, "index.docker.io/"+path)
+		}
+		names = append(names, named.Name())
+	}
+
+	unique := names[:0]
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if push.Tag != "" {
+			name += ":" + push.Tag
+		}
+		if !seen[name] {
+			seen[name] = true
+			unique = append(unique, name)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,120 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/containrrr/watchtower/pkg/api/update"
+	log "github.com/sirupsen/logrus"
+)
+
+// maxPayloadSize is the largest push event payload that is accepted
+const maxPayloadSize = 1 << 20
+
+// Handler is an API handler receiving registry push events, and queueing updates of the containers using the pushed
+// images
+type Handler struct {
+	Path   string
+	secret string
+	queue  *update.Queue
+}
+
+// New is a factory function creating a new Handler instance, adding the update jobs to the queue. The events must be
+// authenticated using the secret.
+func New(queue *update.Queue, secret string) *Handler {
+	return &Handler{
+		Path:   "/v1/webhook",
+		secret: secret,
+		queue:  queue,
+	}
+}
+
+// Handle validates the push event and queues an update of the containers using the pushed images
+func (handle *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPayloadSize))
+	if err != nil {
+		http.Error(w, "failed to read the push event", http.StatusBadRequest)
+		return
+	}
+
+	if !handle.authenticate(r, body) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if r.Header.Get("X-GitHub-Event") == "ping" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	pushes, err := ParsePushes(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(pushes) == 0 {
+		log.Debug("Ignoring registry event without any pushed images.")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	var images []string
+	for _, push := range pushes {
+		log.WithFields(log.Fields{
+			"repository": push.Repository,
+			"tag":        push.Tag,
+		}).Info("Updates triggered by a registry push event.")
+		images = append(images, push.ImageNames()...)
+	}
+
+	job := handle.queue.Add(update.Target{Images: images})
+	log.WithField("job", job.ID).Debug("Update job queued.")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"pushes": pushes,
+		"job":    job,
+	}); err != nil {
+		log.WithError(err).Debug("Failed to write the HTTP API response")
+	}
+}
+
+// authenticate returns whether the request carries the secret, in the form supported by the registry that sent it:
+// a GitHub signature, a GitLab token, an authorization header (Harbor and the distribution registry), or the secret
+// query parameter for registries that cannot set headers, such as Docker Hub
+func (handle *Handler) authenticate(r *http.Request, body []byte) bool {
+	if handle.secret == "" {
+		return false
+	}
+
+	if signature := r.Header.Get("X-Hub-Signature-256"); signature != "" {
+		mac := hmac.New(sha256.New, []byte(handle.secret))
+		mac.Write(body)
+		expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+		return hmac.Equal([]byte(signature), []byte(expected))
+	}
+
+	authorization := r.Header.Get("Authorization")
+	if bearer, found := strings.CutPrefix(authorization, "Bearer "); found {
+		authorization = bearer
+	}
+	for _, value := range []string{r.Header.Get("X-Gitlab-Token"), authorization, r.URL.Query().Get("secret")} {
+		if value != "" && subtle.ConstantTimeCompare([]byte(value), []byte(handle.secret)) == 1 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,203 @@
+package webhook_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/containrrr/watchtower/internal/actions/mocks"
+	"github.com/containrrr/watchtower/pkg/api/update"
+	"github.com/containrrr/watchtower/pkg/api/webhook"
+	"github.com/containrrr/watchtower/pkg/filters"
+	"github.com/containrrr/watchtower/pkg/types"
+)
+
+const secret = "s3cr3t"
+
+func TestWebhook(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Webhook API Suite")
+}
+
+var _ = Describe("the registry push events", func() {
+	It("should parse Docker Hub events", func() {
+		pushes, err := webhook.ParsePushes([]byte(`{
+			"callback_url": "https://registry.hub.docker.com/u/foo/bar/hook/1234/",
+			"push_data": {"pusher": "foo", "tag": "latest"},
+			"repository": {"name": "bar", "namespace": "foo", "repo_name": "foo/bar"}
+		}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pushes).To(Equal([]webhook.Push{{Repository: "foo/bar", Tag: "latest"}}))
+	})
+
+	It("should parse Harbor events", func() {
+		pushes, err := webhook.ParsePushes([]byte(`{
+			"type": "PUSH_ARTIFACT",
+			"event_data": {
+				"resources": [{"digest": "sha256:abc", "tag": "v1", "resource_url": "harbor.example.com/library/app:v1"}],
+				"repository": {"name": "app", "namespace": "library", "repo_full_name": "library/app"}
+			}
+		}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pushes).To(Equal([]webhook.Push{{Repository: "harbor.example.com/library/app", Tag: "v1"}}))
+	})
+
+	It("should parse GitHub package events", func() {
+		pushes, err := webhook.ParsePushes([]byte(`{
+			"action": "published",
+			"package": {
+				"name": "app",
+				"package_type": "CONTAINER",
+				"owner": {"login": "Foo"},
+				"package_version": {
+					"package_url": "ghcr.io/foo/app:1.2.3",
+					"container_metadata": {"tag": {"name": "1.2.3"}}
+				}
+			}
+		}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pushes).To(Equal([]webhook.Push{{Repository: "ghcr.io/foo/app", Tag: "1.2.3"}}))
+	})
+
+	It("should parse distribution registry notifications, leaving out pulls and layers", func() {
+		pushes, err := webhook.ParsePushes([]byte(`{"events": [
+			{"action": "push", "target": {"mediaType": "application/octet-stream", "repository": "foo/app"},
+			 "request": {"host": "registry.example.com:5000"}},
+			{"action": "push", "target": {"mediaType": "application/vnd.docker.distribution.manifest.v2+json",
+			 "repository": "foo/app", "tag": "v2"}, "request": {"host": "registry.example.com:5000"}},
+			{"action": "pull", "target": {"mediaType": "application/vnd.docker.distribution.manifest.v2+json",
+			 "repository": "foo/other", "tag": "v1"}, "request": {"host": "registry.example.com:5000"}}
+		]}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pushes).To(Equal([]webhook.Push{{Repository: "registry.example.com:5000/foo/app", Tag: "v2"}}))
+	})
+
+	It("should reject unknown payloads", func() {
+		_, err := webhook.ParsePushes([]byte(`{"foo": "bar"}`))
+		Expect(err).To(MatchError(webhook.ErrUnknownPayload))
+	})
+
+	It("should return all the names of Docker Hub images", func() {
+		Expect(webhook.Push{Repository: "library/nginx"}.ImageNames()).To(ConsistOf(
+			"nginx", "library/nginx", "docker.io/nginx", "docker.io/library/nginx", "index.docker.io/library/nginx",
+		))
+		Expect(webhook.Push{Repository: "ghcr.io/foo/app"}.ImageNames()).To(Equal([]string{"ghcr.io/foo/app"}))
+	})
+
+	It("should include the pushed tag in the image names", func() {
+		Expect(webhook.Push{Repository: "library/nginx", Tag: "1.25"}.ImageNames()).To(ContainElements(
+			"nginx:1.25", "docker.io/library/nginx:1.25",
+		))
+		Expect(webhook.Push{Repository: "ghcr.io/foo/app", Tag: "v1"}.ImageNames()).To(Equal([]string{"ghcr.io/foo/app:v1"}))
+	})
+})
+
+var _ = Describe("the webhook API", func() {
+	var handler *webhook.Handler
+	var mutex sync.Mutex
+	var targets *[]update.Target
+	const payload = `{"push_data": {"tag": "latest"}, "repository": {"repo_name": "foo/bar"}}`
+
+	request := func(r *http.Request) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		handler.Handle(recorder, r)
+		return recorder
+	}
+
+	updated := func() []update.Target {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return *targets
+	}
+
+	BeforeEach(func() {
+		// Each test records its own updates, as the jobs of the previous tests may still be running
+		recorded := &[]update.Target{}
+		targets = recorded
+		handler = webhook.New(update.NewQueue(func(target update.Target) (types.Report, error) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			*recorded = append(*recorded, target)
+			return nil, nil
+		}, nil), secret)
+	})
+
+	It("should queue an update of the pushed image", func() {
+		res := request(httptest.NewRequest("POST", "/v1/webhook?secret="+secret, strings.NewReader(payload)))
+		Expect(res.Code).To(Equal(http.StatusAccepted))
+		Eventually(updated).Should(HaveLen(1))
+		Expect(updated()[0].Images).To(ContainElements("foo/bar:latest", "docker.io/foo/bar:latest"))
+		Expect(updated()[0].Containers).To(BeNil())
+	})
+
+	It("should update the containers of images pushed to a registry with a port", func() {
+		res := request(httptest.NewRequest("POST", "/v1/webhook?secret="+secret, strings.NewReader(`{"events": [
+			{"action": "push", "target": {"mediaType": "application/vnd.docker.distribution.manifest.v2+json",
+			 "repository": "app", "tag": "latest"}, "request": {"host": "localhost:5000"}}
+		]}`)))
+		Expect(res.Code).To(Equal(http.StatusAccepted))
+		Eventually(updated).Should(HaveLen(1))
+
+		filter := updated()[0].Filter(filters.NoFilter)
+		Expect(filter(mocks.CreateMockContainer("app", "/app", "localhost:5000/app:latest", time.Now()))).To(BeTrue())
+		Expect(filter(mocks.CreateMockContainer("other", "/other", "localhost:5000/other:latest", time.Now()))).To(BeFalse())
+		Expect(filter(mocks.CreateMockContainer("hub", "/hub", "app:latest", time.Now()))).To(BeFalse())
+	})
+
+	It("should only update the containers using the pushed tag", func() {
+		res := request(httptest.NewRequest("POST", "/v1/webhook?secret="+secret, strings.NewReader(
+			`{"push_data": {"tag": "v2"}, "repository": {"repo_name": "foo/bar"}}`,
+		)))
+		Expect(res.Code).To(Equal(http.StatusAccepted))
+		Eventually(updated).Should(HaveLen(1))
+
+		filter := updated()[0].Filter(filters.NoFilter)
+		Expect(filter(mocks.CreateMockContainer("v2", "/v2", "foo/bar:v2", time.Now()))).To(BeTrue())
+		Expect(filter(mocks.CreateMockContainer("v1", "/v1", "foo/bar:v1", time.Now()))).To(BeFalse())
+		Expect(filter(mocks.CreateMockContainer("latest", "/latest", "foo/bar", time.Now()))).To(BeFalse())
+	})
+
+	It("should accept the secret as a token or authorization header", func() {
+		req := httptest.NewRequest("POST", "/v1/webhook", strings.NewReader(payload))
+		req.Header.Set("X-Gitlab-Token", secret)
+		Expect(request(req).Code).To(Equal(http.StatusAccepted))
+
+		req = httptest.NewRequest("POST", "/v1/webhook", strings.NewReader(payload))
+		req.Header.Set("Authorization", "Bearer "+secret)
+		Expect(request(req).Code).To(Equal(http.StatusAccepted))
+	})
+
+	It("should validate GitHub signatures", func() {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(payload))
+
+		req := httptest.NewRequest("POST", "/v1/webhook", strings.NewReader(payload))
+		req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+		Expect(request(req).Code).To(Equal(http.StatusAccepted))
+
+		req = httptest.NewRequest("POST", "/v1/webhook?secret="+secret, strings.NewReader(payload))
+		req.Header.Set("X-Hub-Signature-256", "sha256=0000")
+		Expect(request(req).Code).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should reject events without the secret", func() {
+		res := request(httptest.NewRequest("POST", "/v1/webhook?secret=wrong", strings.NewReader(payload)))
+		Expect(res.Code).To(Equal(http.StatusUnauthorized))
+		Consistently(updated, "50ms").Should(BeEmpty())
+	})
+
+	It("should not queue updates for events without pushes", func() {
+		res := request(httptest.NewRequest("POST", "/v1/webhook?secret="+secret, strings.NewReader(`{"events": []}`)))
+		Expect(res.Code).To(Equal(http.StatusNoContent))
+		Consistently(updated, "50ms").Should(BeEmpty())
+	})
+})
@@ -2,21 +2,30 @@ package container
 
 import (
 	"bytes"
+	"crypto"
 	"fmt"
 	"io"
+	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
+	ref "github.com/distribution/reference"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/network"
 	sdkClient "github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/net/context"
 
 	"github.com/containrrr/watchtower/pkg/registry"
 	"github.com/containrrr/watchtower/pkg/registry/digest"
+	"github.com/containrrr/watchtower/pkg/registry/helpers"
+	"github.com/containrrr/watchtower/pkg/registry/manifest"
+	"github.com/containrrr/watchtower/pkg/registry/metadata"
+	"github.com/containrrr/watchtower/pkg/registry/signature"
 	t "github.com/containrrr/watchtower/pkg/types"
 )
 
@@ -30,10 +39,15 @@ type Client interface {
 	StopContainer(t.Container, time.Duration) error
 	StartContainer(t.Container) (t.ContainerID, error)
 	RenameContainer(t.Container, string) error
-	IsContainerStale(t.Container, t.UpdateParams) (stale bool, latestImage t.ImageID, err error)
-	ExecuteCommand(containerID t.ContainerID, command string, timeout int) (SkipUpdate bool, err error)
+	IsContainerStale(t.Container, t.UpdateParams) (t.ImageCheck, error)
+	ExecuteCommand(containerID t.ContainerID, command string, timeout int) (SkipUpdate bool, output *t.CommandOutput, err error)
 	RemoveImageByID(t.ImageID) error
 	WarnOnHeadPullFailed(container t.Container) bool
+	VerifyImageSignature(container t.Container, image t.ImageID, keys []crypto.PublicKey) error
+	GetImageMetadata(container t.Container, image t.ImageID) (t.ImageMetadata, error)
+	CheckContainerRunning(containerID t.ContainerID) error
+	GetContainerLogs(containerID t.ContainerID, lines int) (string, error)
+	Ping() error
 }
 
 // NewClient returns a new Client instance which can be used to interact with
@@ -62,6 +76,8 @@ type ClientOptions struct {
 	ReviveStopped     bool
 	IncludeRestarting bool
 	WarnOnHeadFailed  WarningStrategy
+	RegistryMirrors   map[string]string
+	MirrorFallback    bool
 }
 
 // WarningStrategy is a value determining when to show warnings
@@ -310,22 +326,85 @@ func (client dockerClient) doStartContainer(bg context.Context, c t.Container, c
 	return nil
 }
 
+// CheckContainerRunning returns an error if the container is not running, or is restarting after having exited
+func (client dockerClient) CheckContainerRunning(containerID t.ContainerID) error {
+	bg := context.Background()
+	containerInfo, err := client.api.ContainerInspect(bg, string(containerID))
+	if err != nil {
+		return err
+	}
+	if containerInfo.State == nil {
+		return errorNoContainerInfo
+	}
+	if !containerInfo.State.Running || containerInfo.State.Restarting {
+		return fmt.Errorf("%w with exit code %d", ErrContainerExited, containerInfo.State.ExitCode)
+	}
+	return nil
+}
+
+// Ping checks that the Docker daemon can be reached
+func (client dockerClient) Ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := client.api.Ping(ctx)
+	return err
+}
+
+// GetContainerLogs returns the last lines of the combined stdout and stderr logs of the container
+func (client dockerClient) GetContainerLogs(containerID t.ContainerID, lines int) (string, error) {
+	bg := context.Background()
+	containerInfo, err := client.api.ContainerInspect(bg, string(containerID))
+	if err != nil {
+		return "", err
+	}
+
+	reader, err := client.api.ContainerLogs(bg, string(containerID), types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Tail:       strconv.Itoa(lines),
+	})
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	var logs bytes.Buffer
+	if containerInfo.Config != nil && containerInfo.Config.Tty {
+		_, err = io.Copy(&logs, reader)
+	} else {
+		// Without a TTY, the output streams are multiplexed using a header for each frame
+		_, err = stdcopy.StdCopy(&logs, &logs, reader)
+	}
+	if err != nil {
+		return "", err
+	}
+	return logs.String(), nil
+}
+
 func (client dockerClient) RenameContainer(c t.Container, newName string) error {
 	bg := context.Background()
 	log.Debugf("Renaming container %s (%s) to %s", c.Name(), c.ID().ShortID(), newName)
 	return client.api.ContainerRename(bg, string(c.ID()), newName)
 }
 
-func (client dockerClient) IsContainerStale(container t.Container, params t.UpdateParams) (stale bool, latestImage t.ImageID, err error) {
+func (client dockerClient) IsContainerStale(container t.Container, params t.UpdateParams) (t.ImageCheck, error) {
 	ctx := context.Background()
+	check := t.ImageCheck{LatestImage: container.SafeImageID()}
+	var err error
+
+	if params.CheckOnly {
+		check.Stale, check.LatestDigest, err = client.CheckImageDigest(container)
+		return check, err
+	}
 
 	if container.IsNoPull(params) {
 		log.Debugf("Skipping image pull.")
-	} else if err := client.PullImage(ctx, container); err != nil {
-		return false, container.SafeImageID(), err
+	} else if check.Pull, err = client.PullImage(ctx, container); err != nil {
+		return check, err
 	}
 
-	return client.HasNewImage(ctx, container)
+	check.Stale, check.LatestImage, err = client.HasNewImage(ctx, container)
+	return check, err
 }
 
 func (client dockerClient) HasNewImage(ctx context.Context, container t.Container) (hasNew bool, latestImage t.ImageID, err error) {
@@ -347,9 +426,52 @@ func (client dockerClient) HasNewImage(ctx context.Context, container t.Containe
 	return true, newImageID, nil
 }
 
+// CheckImageDigest checks whether the container image is stale by comparing the digest reported by the registry
+// against the repo digests of the local image, without pulling the image. If the image is stale, the digest reported
+// by the registry is returned. If the digests could not be compared, an error wrapping ErrUnknownStaleness is returned.
+func (client dockerClient) CheckImageDigest(container t.Container) (stale bool, latestDigest string, err error) {
+	imageName := container.ImageName()
+
+	if strings.HasPrefix(imageName, "sha256:") {
+		return false, "", fmt.Errorf("%w: container uses a pinned image", ErrUnknownStaleness)
+	}
+	if !container.HasImageInfo() || len(container.ImageInfo().RepoDigests) == 0 {
+		return false, "", fmt.Errorf("%w: the local image has no repo digests", ErrUnknownStaleness)
+	}
+
+	var registryContainer t.Container = container
+	if mirrorName, found := helpers.GetMirroredImageName(imageName, client.RegistryMirrors); found {
+		registryContainer = mirroredContainer{container, mirrorName}
+	}
+
+	opts, err := registry.GetPullOptions(registryContainer.ImageName())
+	if err != nil {
+		return false, "", fmt.Errorf("%w: %v", ErrUnknownStaleness, err)
+	}
+
+	remoteDigest, err := digest.GetRemoteDigest(registryContainer, opts.RegistryAuth)
+	if err != nil {
+		return false, "", fmt.Errorf("%w: %v", ErrUnknownStaleness, err)
+	}
+	if remoteDigest == "" {
+		return false, "", fmt.Errorf("%w: the registry did not return a digest", ErrUnknownStaleness)
+	}
+
+	for _, repoDigest := range container.ImageInfo().RepoDigests {
+		if _, localDigest, _ := strings.Cut(repoDigest, "@"); localDigest == remoteDigest {
+			log.Debugf("No new images found for %s", container.Name())
+			return false, "", nil
+		}
+	}
+
+	log.Infof("Found new %s image (%s)", imageName, t.ImageID(remoteDigest).ShortID())
+	return true, remoteDigest, nil
+}
+
 // PullImage pulls the latest image for the supplied container, optionally skipping if it's digest can be confirmed
-// to match the one that the registry reports via a HEAD request
-func (client dockerClient) PullImage(ctx context.Context, container t.Container) error {
+// to match the one that the registry reports via a HEAD request. The returned statistics are empty if no image was
+// pulled.
+func (client dockerClient) PullImage(ctx context.Context, container t.Container) (t.PullStats, error) {
 	containerName := container.Name()
 	imageName := container.ImageName()
 
@@ -359,14 +481,61 @@ func (client dockerClient) PullImage(ctx context.Context, container t.Container)
 	}
 
 	if strings.HasPrefix(imageName, "sha256:") {
-		return fmt.Errorf("container uses a pinned image, and cannot be updated by watchtower")
+		return t.PullStats{}, fmt.Errorf("container uses a pinned image, and cannot be updated by watchtower")
+	}
+
+	if mirrorName, found := helpers.GetMirroredImageName(imageName, client.RegistryMirrors); found {
+		log.WithFields(fields).Debugf("Using registry mirror image %s", mirrorName)
+		stats, err := client.pullMirroredImage(ctx, container, mirrorName)
+		if err == nil {
+			return stats, nil
+		}
+		if !client.MirrorFallback {
+			return t.PullStats{}, err
+		}
+		log.WithFields(fields).Warnf("Failed to pull image from registry mirror, falling back to upstream registry: %v", err)
+	}
+
+	return client.pullImage(ctx, container)
+}
+
+// pullMirroredImage pulls the image for the supplied container from a registry mirror, and tags it using the
+// original image name so that the container can keep its image reference
+func (client dockerClient) pullMirroredImage(ctx context.Context, container t.Container, mirrorName string) (t.PullStats, error) {
+	imageName := container.ImageName()
+
+	stats, err := client.pullImage(ctx, mirroredContainer{container, mirrorName})
+	if err != nil || stats.IsEmpty() {
+		return stats, err
+	}
+
+	if err := client.api.ImageTag(ctx, mirrorName, imageName); err != nil {
+		return t.PullStats{}, err
+	}
+
+	// Only the mirror tag is removed, as the image is still referenced by the original name
+	if _, err := client.api.ImageRemove(ctx, mirrorName, types.ImageRemoveOptions{}); err != nil {
+		log.WithField("image", mirrorName).Debugf("Failed to remove registry mirror tag: %v", err)
+	}
+	return stats, nil
+}
+
+// pullImage pulls the image for the supplied container, returning the statistics of the pull, which are empty if no
+// image was pulled
+func (client dockerClient) pullImage(ctx context.Context, container t.Container) (t.PullStats, error) {
+	containerName := container.Name()
+	imageName := container.ImageName()
+
+	fields := log.Fields{
+		"image":     imageName,
+		"container": containerName,
 	}
 
 	log.WithFields(fields).Debugf("Trying to load authentication credentials.")
 	opts, err := registry.GetPullOptions(imageName)
 	if err != nil {
 		log.Debugf("Error loading authentication credentials %s", err)
-		return err
+		return t.PullStats{}, err
 	}
 	if opts.RegistryAuth != "" {
 		log.Debug("Credentials loaded")
@@ -383,26 +552,137 @@ func (client dockerClient) PullImage(ctx context.Context, container t.Container)
 		log.WithFields(fields).Log(headLevel, "Reason: ", err)
 	} else if match {
 		log.Debug("No pull needed. Skipping image.")
-		return nil
+		return t.PullStats{}, nil
 	} else {
 		log.Debug("Digests did not match, doing a pull.")
 	}
 
 	log.WithFields(fields).Debugf("Pulling image")
 
+	start := time.Now()
 	response, err := client.api.ImagePull(ctx, imageName, opts)
 	if err != nil {
 		log.Debugf("Error pulling image %s, %s", imageName, err)
-		return err
+		return t.PullStats{}, err
 	}
 
 	defer response.Close()
 	// the pull request will be aborted prematurely unless the response is read
-	if _, err = io.ReadAll(response); err != nil {
-		log.Error(err)
+	size, err := readPullResponse(response, fields)
+	if err != nil {
+		log.WithFields(fields).Debugf("Error pulling image: %v", err)
+		return t.PullStats{}, err
+	}
+
+	stats := t.PullStats{Bytes: size, Duration: time.Since(start)}
+	log.WithFields(fields).Debugf("Pulled image, downloaded %d bytes in %v", stats.Bytes, stats.Duration.Round(time.Millisecond))
+	return stats, nil
+}
+
+// mirroredContainer is a container that uses the image name of a registry mirror when
+// talking to the registry, while the container itself keeps its original image reference
+type mirroredContainer struct {
+	t.Container
+	imageName string
+}
+
+// ImageName returns the name of the image on the registry mirror
+func (c mirroredContainer) ImageName() string {
+	return c.imageName
+}
+
+// VerifyImageSignature checks that the registry holds a valid signature for the supplied image, made by one of
+// the public keys
+func (client dockerClient) VerifyImageSignature(container t.Container, image t.ImageID, keys []crypto.PublicKey) error {
+	ctx := context.Background()
+
+	imageInfo, _, err := client.api.ImageInspectWithRaw(ctx, string(image))
+	if err != nil {
 		return err
 	}
-	return nil
+
+	var registryContainer t.Container = container
+	if mirrorName, found := helpers.GetMirroredImageName(container.ImageName(), client.RegistryMirrors); found {
+		registryContainer = mirroredContainer{container, mirrorName}
+	}
+
+	imageDigest, err := getRepoDigest(imageInfo.RepoDigests, container.ImageName(), registryContainer.ImageName())
+	if err != nil {
+		return err
+	}
+
+	opts, err := registry.GetPullOptions(registryContainer.ImageName())
+	if err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"container": container.Name(),
+		"digest":    imageDigest,
+	}).Debug("Verifying image signature")
+	return signature.Verify(registryContainer, container.ImageName(), imageDigest, opts.RegistryAuth, keys)
+}
+
+// GetImageMetadata returns the metadata of the latest image for the supplied container. The labels are read from
+// the image config in the registry, falling back to the local image if the registry could not be queried and the
+// latest image has been pulled.
+func (client dockerClient) GetImageMetadata(container t.Container, image t.ImageID) (t.ImageMetadata, error) {
+	labels, err := client.getRemoteImageLabels(container)
+	if err == nil {
+		return t.NewImageMetadata(labels), nil
+	}
+	log.WithField("image", container.ImageName()).Debugf("Could not read image metadata from registry: %v", err)
+	if image == container.SafeImageID() {
+		return t.ImageMetadata{}, err
+	}
+
+	imageInfo, _, err := client.api.ImageInspectWithRaw(context.Background(), string(image))
+	if err != nil {
+		return t.ImageMetadata{}, err
+	}
+	if imageInfo.Config == nil {
+		return t.ImageMetadata{}, nil
+	}
+	return t.NewImageMetadata(imageInfo.Config.Labels), nil
+}
+
+func (client dockerClient) getRemoteImageLabels(container t.Container) (map[string]string, error) {
+	var registryContainer t.Container = container
+	if mirrorName, found := helpers.GetMirroredImageName(container.ImageName(), client.RegistryMirrors); found {
+		registryContainer = mirroredContainer{container, mirrorName}
+	}
+
+	opts, err := registry.GetPullOptions(registryContainer.ImageName())
+	if err != nil {
+		return nil, err
+	}
+
+	platform := manifest.Platform{OS: runtime.GOOS, Architecture: runtime.GOARCH}
+	if imageInfo := container.ImageInfo(); imageInfo != nil && imageInfo.Os != "" {
+		platform = manifest.Platform{OS: imageInfo.Os, Architecture: imageInfo.Architecture, Variant: imageInfo.Variant}
+	}
+
+	return metadata.GetLabels(registryContainer, opts.RegistryAuth, platform)
+}
+
+// getRepoDigest returns the digest from the repo digests that belongs to one of the supplied image repositories
+func getRepoDigest(repoDigests []string, imageNames ...string) (string, error) {
+	for _, repoDigest := range repoDigests {
+		repo, digest, found := strings.Cut(repoDigest, "@")
+		if !found {
+			continue
+		}
+		normalizedRepo, err := ref.ParseNormalizedNamed(repo)
+		if err != nil {
+			continue
+		}
+		for _, imageName := range imageNames {
+			if normalizedImage, err := ref.ParseNormalizedNamed(imageName); err == nil && normalizedImage.Name() == normalizedRepo.Name() {
+				return digest, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no repository digest available for image %s", imageNames[0])
 }
 
 func (client dockerClient) RemoveImageByID(id t.ImageID) error {
@@ -439,7 +719,7 @@ func (client dockerClient) RemoveImageByID(id t.ImageID) error {
 	return err
 }
 
-func (client dockerClient) ExecuteCommand(containerID t.ContainerID, command string, timeout int) (SkipUpdate bool, err error) {
+func (client dockerClient) ExecuteCommand(containerID t.ContainerID, command string, timeout int) (SkipUpdate bool, output *t.CommandOutput, err error) {
 	bg := context.Background()
 	clog := log.WithField("containerID", containerID)
 
@@ -452,7 +732,7 @@ func (client dockerClient) ExecuteCommand(containerID t.ContainerID, command str
 
 	exec, err := client.api.ContainerExecCreate(bg, string(containerID), execConfig)
 	if err != nil {
-		return false, err
+		return false, nil, err
 	}
 
 	response, attachErr := client.api.ContainerExecAttach(bg, exec.ID, types.ExecStartCheck{
@@ -467,10 +747,10 @@ func (client dockerClient) ExecuteCommand(containerID t.ContainerID, command str
 	execStartCheck := types.ExecStartCheck{Detach: false, Tty: true}
 	err = client.api.ContainerExecStart(bg, exec.ID, execStartCheck)
 	if err != nil {
-		return false, err
+		return false, nil, err
 	}
 
-	var output string
+	var execOutput string
 	if attachErr == nil {
 		defer response.Close()
 		var writer bytes.Buffer
@@ -478,21 +758,21 @@ func (client dockerClient) ExecuteCommand(containerID t.ContainerID, command str
 		if err != nil {
 			clog.Error(err)
 		} else if written > 0 {
-			output = strings.TrimSpace(writer.String())
+			execOutput = strings.TrimSpace(writer.String())
 		}
 	}
 
 	// Inspect the exec to get the exit code and print a message if the
 	// exit code is not success.
-	skipUpdate, err := client.waitForExecOrTimeout(bg, exec.ID, output, timeout)
+	skipUpdate, output, err := client.waitForExecOrTimeout(bg, exec.ID, execOutput, timeout)
 	if err != nil {
-		return true, err
+		return true, output, err
 	}
 
-	return skipUpdate, nil
+	return skipUpdate, output, nil
 }
 
-func (client dockerClient) waitForExecOrTimeout(bg context.Context, ID string, execOutput string, timeout int) (SkipUpdate bool, err error) {
+func (client dockerClient) waitForExecOrTimeout(bg context.Context, ID string, execOutput string, timeout int) (SkipUpdate bool, output *t.CommandOutput, err error) {
 	const ExTempFail = 75
 	var ctx context.Context
 	var cancel context.CancelFunc
@@ -516,7 +796,7 @@ func (client dockerClient) waitForExecOrTimeout(bg context.Context, ID string, e
 		}).Debug("Awaiting timeout or completion")
 
 		if err != nil {
-			return false, err
+			return false, nil, err
 		}
 		if execInspect.Running {
 			time.Sleep(1 * time.Second)
@@ -525,17 +805,18 @@ func (client dockerClient) waitForExecOrTimeout(bg context.Context, ID string, e
 		if len(execOutput) > 0 {
 			log.Infof("Command output:\n%v", execOutput)
 		}
+		output = &t.CommandOutput{Output: execOutput, ExitCode: execInspect.ExitCode}
 
 		if execInspect.ExitCode == ExTempFail {
-			return true, nil
+			return true, output, nil
 		}
 
 		if execInspect.ExitCode > 0 {
-			return false, fmt.Errorf("command exited with code %v  %s", execInspect.ExitCode, execOutput)
+			return false, output, fmt.Errorf("command exited with code %v  %s", execInspect.ExitCode, execOutput)
 		}
 		break
 	}
-	return false, nil
+	return false, output, nil
 }
 
 func (client dockerClient) waitForStopOrTimeout(c t.Container, waitTime time.Duration) error {
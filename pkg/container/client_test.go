@@ -1,6 +1,7 @@
 package container
 
 import (
+	"bytes"
 	"github.com/docker/docker/api/types/network"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 	"github.com/docker/docker/api/types/backend"
 	cli "github.com/docker/docker/client"
 	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/onsi/gomega/gbytes"
 	"github.com/onsi/gomega/ghttp"
 	"github.com/sirupsen/logrus"
@@ -70,10 +72,148 @@ var _ = Describe("the client", func() {
 			It("should gracefully fail with a useful message", func() {
 				c := dockerClient{}
 				pinnedContainer := MockContainer(WithImageName("sha256:fa5269854a5e615e51a72b17ad3fd1e01268f278a6684c8ed3c5f0cdce3f230b"))
-				err := c.PullImage(context.Background(), pinnedContainer)
+				_, err := c.PullImage(context.Background(), pinnedContainer)
 				Expect(err).To(MatchError(`container uses a pinned image, and cannot be updated by watchtower`))
 			})
 		})
+		When("a registry mirror is configured for the image registry", func() {
+			It("should pull from the mirror and tag the image using the original name", func() {
+				mirror := "127.0.0.1:1"
+				mirrorImage := mirror + "/prefix/imagename:latest"
+				c := dockerClient{
+					api: docker,
+					ClientOptions: ClientOptions{
+						RegistryMirrors: map[string]string{"index.docker.io": mirror},
+					},
+				}
+				container := MockContainer(WithImageName("docker.io/prefix/imagename:latest"))
+
+				mockServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("POST", HaveSuffix("/images/create"), "fromImage="+mirror+"%2Fprefix%2Fimagename&tag=latest"),
+						ghttp.RespondWith(http.StatusOK, `{"status":"Pulling from prefix/imagename"}`),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("POST", HaveSuffix("/images/"+mirrorImage+"/tag")),
+						ghttp.RespondWith(http.StatusCreated, nil),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("DELETE", HaveSuffix("/images/"+mirrorImage)),
+						ghttp.RespondWithJSONEncoded(http.StatusOK, []types.ImageDeleteResponseItem{{Untagged: mirrorImage}}),
+					),
+				)
+
+				_, err := c.PullImage(context.Background(), container)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mockServer.ReceivedRequests()).To(HaveLen(3))
+			})
+		})
+	})
+	When("reading the pull response stream", func() {
+		It("should return the total size of the downloaded layers", func() {
+			c := dockerClient{api: docker}
+			container := MockContainer(WithImageName("docker.io/prefix/imagename:latest"))
+			mockServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", HaveSuffix("/images/create")),
+					ghttp.RespondWith(http.StatusOK, `{"status":"Pulling from prefix/imagename","id":"latest"}
+{"status":"Already exists","id":"a1"}
+{"status":"Downloading","progressDetail":{"current":512,"total":2048},"id":"b2"}
+{"status":"Downloading","progressDetail":{"current":2048,"total":2048},"id":"b2"}
+{"status":"Downloading","progressDetail":{"current":1000,"total":4000},"id":"c3"}
+{"status":"Download complete","id":"c3"}
+{"status":"Status: Downloaded newer image for prefix/imagename:latest"}`),
+				),
+			)
+
+			stats, err := c.PullImage(context.Background(), container)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stats.Bytes).To(Equal(int64(6048)))
+			Expect(stats.IsEmpty()).To(BeFalse())
+		})
+		It("should fail the pull when the stream contains an error", func() {
+			c := dockerClient{api: docker}
+			container := MockContainer(WithImageName("docker.io/prefix/imagename:latest"))
+			mockServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", HaveSuffix("/images/create")),
+					ghttp.RespondWith(http.StatusOK, `{"status":"Pulling from prefix/imagename","id":"latest"}
+{"errorDetail":{"message":"manifest unknown: manifest unknown"},"error":"manifest unknown: manifest unknown"}`),
+				),
+			)
+
+			stats, err := c.PullImage(context.Background(), container)
+			Expect(err).To(MatchError(ContainSubstring("manifest unknown")))
+			Expect(stats.IsEmpty()).To(BeTrue())
+		})
+	})
+	When("checking for a new image in check-only mode", func() {
+		When("the local image has no repo digests", func() {
+			It("should return an unknown staleness error without pulling", func() {
+				c := dockerClient{api: docker}
+				container := MockContainer(WithImageName("docker.io/prefix/imagename:latest"))
+				check, err := c.IsContainerStale(container, t.UpdateParams{CheckOnly: true})
+				Expect(err).To(MatchError(ErrUnknownStaleness))
+				Expect(check.Stale).To(BeFalse())
+				Expect(check.LatestImage).To(Equal(container.SafeImageID()))
+				Expect(mockServer.ReceivedRequests()).To(BeEmpty())
+			})
+		})
+		When("the image is pinned", func() {
+			It("should return an unknown staleness error", func() {
+				c := dockerClient{api: docker}
+				pinnedContainer := MockContainer(WithImageName("sha256:fa5269854a5e615e51a72b17ad3fd1e01268f278a6684c8ed3c5f0cdce3f230b"))
+				_, err := c.IsContainerStale(pinnedContainer, t.UpdateParams{CheckOnly: true})
+				Expect(err).To(MatchError(ErrUnknownStaleness))
+			})
+		})
+	})
+	When("checking whether a started container is running", func() {
+		It("should succeed for running containers", func() {
+			container := MockContainer(WithContainerState(types.ContainerState{Running: true}))
+			cid := container.ContainerInfo().ID
+			mockServer.AppendHandlers(mocks.GetContainerHandler(cid, container.ContainerInfo()))
+
+			Expect(dockerClient{api: docker}.CheckContainerRunning(t.ContainerID(cid))).To(Succeed())
+		})
+		It("should return the exit code of containers that exited", func() {
+			container := MockContainer(WithContainerState(types.ContainerState{Running: false, ExitCode: 2}))
+			cid := container.ContainerInfo().ID
+			mockServer.AppendHandlers(mocks.GetContainerHandler(cid, container.ContainerInfo()))
+
+			err := dockerClient{api: docker}.CheckContainerRunning(t.ContainerID(cid))
+			Expect(err).To(MatchError(ErrContainerExited))
+			Expect(err).To(MatchError(ContainSubstring("exit code 2")))
+		})
+		It("should fail for containers that are restarting", func() {
+			container := MockContainer(WithContainerState(types.ContainerState{Running: true, Restarting: true}))
+			cid := container.ContainerInfo().ID
+			mockServer.AppendHandlers(mocks.GetContainerHandler(cid, container.ContainerInfo()))
+
+			Expect(dockerClient{api: docker}.CheckContainerRunning(t.ContainerID(cid))).To(MatchError(ErrContainerExited))
+		})
+	})
+	When("getting the logs of a container", func() {
+		It("should combine the multiplexed output streams", func() {
+			container := MockContainer(WithContainerState(types.ContainerState{Running: false}))
+			cid := container.ContainerInfo().ID
+
+			var stream bytes.Buffer
+			_, _ = stdcopy.NewStdWriter(&stream, stdcopy.Stdout).Write([]byte("Starting\n"))
+			_, _ = stdcopy.NewStdWriter(&stream, stdcopy.Stderr).Write([]byte("Error: missing port\n"))
+
+			mockServer.AppendHandlers(
+				mocks.GetContainerHandler(cid, container.ContainerInfo()),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", HaveSuffix("/containers/%v/logs", cid), "stderr=1&stdout=1&tail=20"),
+					ghttp.RespondWith(http.StatusOK, stream.Bytes()),
+				),
+			)
+
+			logs, err := dockerClient{api: docker}.GetContainerLogs(t.ContainerID(cid), 20)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(logs).To(Equal("Starting\nError: missing port\n"))
+		})
 	})
 	When("removing a running container", func() {
 		When("the container still exist after stopping", func() {
@@ -308,7 +448,7 @@ var _ = Describe("the client", func() {
 					),
 				)
 
-				_, err := client.ExecuteCommand(containerID, cmd, 1)
+				_, _, err := client.ExecuteCommand(containerID, cmd, 1)
 				Expect(err).NotTo(HaveOccurred())
 				// Note: Since Execute requires opening up a raw TCP stream to the daemon for the output, this will fail
 				// when using the mock API server. Regardless of the outcome, the log should include the container ID
@@ -133,7 +133,11 @@ func (c Container) Enabled() (bool, bool) {
 
 // IsMonitorOnly returns whether the container should only be monitored based on values of
 // the monitor-only label, the monitor-only argument and the label-take-precedence argument.
+// Containers are always monitored only when the check-only argument is set.
 func (c Container) IsMonitorOnly(params wt.UpdateParams) bool {
+	if params.CheckOnly {
+		return true
+	}
 	return c.getContainerOrGlobalBool(params.MonitorOnly, monitorOnlyLabel, params.LabelPrecedence)
 }
 
@@ -143,6 +147,12 @@ func (c Container) IsNoPull(params wt.UpdateParams) bool {
 	return c.getContainerOrGlobalBool(params.NoPull, noPullLabel, params.LabelPrecedence)
 }
 
+// IsVerifySignature returns whether the image signature should be verified before updating based on values of
+// the verify-signature label, the verify-signatures argument and the label-take-precedence argument.
+func (c Container) IsVerifySignature(params wt.UpdateParams) bool {
+	return c.getContainerOrGlobalBool(params.VerifySignatures, verifySignatureLabel, params.LabelPrecedence)
+}
+
 func (c Container) getContainerOrGlobalBool(globalVal bool, label string, contPrecedence bool) bool {
 	if contVal, err := c.getBoolLabelValue(label); err != nil {
 		if !errors.Is(err, errorLabelNotFound) {
@@ -6,3 +6,9 @@ var errorNoImageInfo = errors.New("no available image info")
 var errorNoContainerInfo = errors.New("no available container info")
 var errorInvalidConfig = errors.New("container configuration missing or invalid")
 var errorLabelNotFound = errors.New("label was not found in container")
+
+// ErrContainerExited is returned when a container is no longer running shortly after being started
+var ErrContainerExited = errors.New("container exited after starting")
+
+// ErrUnknownStaleness is returned when it could not be determined whether the container image is stale
+var ErrUnknownStaleness = errors.New("could not compare the image digests")
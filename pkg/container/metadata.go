@@ -1,6 +1,9 @@
 package container
 
-import "strconv"
+import (
+	"strconv"
+	"strings"
+)
 
 const (
 	watchtowerLabel        = "com.centurylinklabs.watchtower"
@@ -17,6 +20,8 @@ const (
 	postUpdateLabel        = "com.centurylinklabs.watchtower.lifecycle.post-update"
 	preUpdateTimeoutLabel  = "com.centurylinklabs.watchtower.lifecycle.pre-update-timeout"
 	postUpdateTimeoutLabel = "com.centurylinklabs.watchtower.lifecycle.post-update-timeout"
+	verifySignatureLabel   = "com.centurylinklabs.watchtower.verify-signature"
+	signatureKeysLabel     = "com.centurylinklabs.watchtower.signature-keys"
 )
 
 // GetLifecyclePreCheckCommand returns the pre-check command set in the container metadata or an empty string
@@ -39,6 +44,17 @@ func (c Container) GetLifecyclePostUpdateCommand() string {
 	return c.getLabelValueOrEmpty(postUpdateLabel)
 }
 
+// GetSignatureKeys returns the paths of the public keys set in the container metadata for verifying image signatures
+func (c Container) GetSignatureKeys() []string {
+	var keys []string
+	for _, key := range strings.Split(c.getLabelValueOrEmpty(signatureKeysLabel), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
 // ContainsWatchtowerLabel takes a map of labels and values and tells
 // the consumer whether it contains a valid watchtower instance label
 func ContainsWatchtowerLabel(labels map[string]string) bool {
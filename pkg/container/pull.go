@@ -0,0 +1,74 @@
+package container
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/docker/docker/pkg/jsonmessage"
+	log "github.com/sirupsen/logrus"
+)
+
+// pullProgressInterval is the minimum time between logging the progress of an image pull
+const pullProgressInterval = 5 * time.Second
+
+// readPullResponse decodes the JSON message stream returned by the daemon when pulling an image, returning the
+// total size of the downloaded layers. Errors that the daemon reports in the stream are returned as errors.
+func readPullResponse(response io.Reader, fields log.Fields) (int64, error) {
+	decoder := json.NewDecoder(response)
+	progress := pullProgress{layers: map[string]*jsonmessage.JSONProgress{}}
+	lastLog := time.Now()
+
+	for {
+		var message jsonmessage.JSONMessage
+		if err := decoder.Decode(&message); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return progress.total(), err
+		}
+
+		if message.Error != nil {
+			return progress.total(), message.Error
+		}
+		if message.ErrorMessage != "" {
+			return progress.total(), errors.New(message.ErrorMessage)
+		}
+
+		if message.ID != "" && message.Status == "Downloading" && message.Progress != nil {
+			progress.layers[message.ID] = message.Progress
+		}
+
+		if time.Since(lastLog) >= pullProgressInterval {
+			lastLog = time.Now()
+			log.WithFields(fields).Debugf("Pulling image: %d of %d bytes downloaded (%d layers)",
+				progress.current(), progress.total(), len(progress.layers))
+		}
+	}
+
+	return progress.total(), nil
+}
+
+// pullProgress keeps track of the latest download progress of each layer in an image pull
+type pullProgress struct {
+	layers map[string]*jsonmessage.JSONProgress
+}
+
+func (p pullProgress) current() (current int64) {
+	for _, layer := range p.layers {
+		current += layer.Current
+	}
+	return current
+}
+
+func (p pullProgress) total() (total int64) {
+	for _, layer := range p.layers {
+		if layer.Total > 0 {
+			total += layer.Total
+		} else {
+			total += layer.Current
+		}
+	}
+	return total
+}
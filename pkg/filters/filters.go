@@ -41,6 +41,32 @@ func FilterByNames(names []string, baseFilter t.Filter) t.Filter {
 	}
 }
 
+// FilterByExactNames returns all containers named exactly one of the specified names, with or without the leading slash.
+// Unlike FilterByNames, the names are not treated as regular expressions.
+func FilterByExactNames(names []string, baseFilter t.Filter) t.Filter {
+	if len(names) == 0 {
+		return baseFilter
+	}
+
+	return func(c t.FilterableContainer) bool {
+		if ContainsName(names, c.Name()) {
+			return baseFilter(c)
+		}
+		return false
+	}
+}
+
+// ContainsName returns whether the container name equals one of the names, ignoring the leading slash
+func ContainsName(names []string, name string) bool {
+	name = strings.TrimPrefix(name, "/")
+	for _, n := range names {
+		if strings.TrimPrefix(n, "/") == name {
+			return true
+		}
+	}
+	return false
+}
+
 // FilterByDisableNames returns all containers that don't match any of the specified names
 func FilterByDisableNames(disableNames []string, baseFilter t.Filter) t.Filter {
 	if len(disableNames) == 0 {
@@ -101,16 +127,21 @@ func FilterByScope(scope string, baseFilter t.Filter) t.Filter {
 	}
 }
 
-// FilterByImage returns all containers that have a specific image
+// FilterByImage returns all containers that have a specific image. Images given with a tag only match the containers
+// using that tag, where images without a tag use "latest".
 func FilterByImage(images []string, baseFilter t.Filter) t.Filter {
 	if images == nil {
 		return baseFilter
 	}
 
 	return func(c t.FilterableContainer) bool {
-		image := strings.Split(c.ImageName(), ":")[0]
+		image, tag := splitImageTag(c.ImageName())
+		if tag == "" {
+			tag = "latest"
+		}
 		for _, targetImage := range images {
-			if image == targetImage {
+			targetImage, targetTag := splitImageTag(targetImage)
+			if image == targetImage && (targetTag == "" || tag == targetTag) {
 				return baseFilter(c)
 			}
 		}
@@ -119,6 +150,18 @@ func FilterByImage(images []string, baseFilter t.Filter) t.Filter {
 	}
 }
 
+// splitImageTag returns the image name without the tag or digest, and the tag, if any. The tag is only looked for after
+// the last slash, as the registry host may include a port.
+func splitImageTag(image string) (string, string) {
+	if at := strings.Index(image, "@"); at >= 0 {
+		image = image[:at]
+	}
+	if colon := strings.LastIndex(image, ":"); colon > strings.LastIndex(image, "/") {
+		return image[:colon], image[colon+1:]
+	}
+	return image, ""
+}
+
 // BuildFilter creates the needed filter of containers
 func BuildFilter(names []string, disableNames []string, enableLabel bool, scope string) (t.Filter, string) {
 	sb := strings.Builder{}
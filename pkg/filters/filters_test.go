@@ -213,6 +213,37 @@ func TestFilterByImage(t *testing.T) {
 	assert.True(t, filterMultiple(container))
 	container.AssertExpectations(t)
 
+	filterWithPort := FilterByImage([]string{"localhost:5000/app"}, NoFilter)
+
+	container = new(mocks.FilterableContainer)
+	container.On("ImageName").Return("localhost:5000/app:latest")
+	assert.True(t, filterWithPort(container))
+	assert.False(t, FilterByImage([]string{"localhost"}, NoFilter)(container))
+	container.AssertExpectations(t)
+
+	container = new(mocks.FilterableContainer)
+	container.On("ImageName").Return("localhost:5000/app@sha256:0123456789abcdef")
+	assert.True(t, filterWithPort(container))
+	container.AssertExpectations(t)
+}
+
+func TestFilterByImageWithTag(t *testing.T) {
+	filter := FilterByImage([]string{"registry:2", "localhost:5000/app:latest"}, NoFilter)
+
+	container := new(mocks.FilterableContainer)
+	container.On("ImageName").Return("registry:2")
+	assert.True(t, filter(container))
+	container.AssertExpectations(t)
+
+	container = new(mocks.FilterableContainer)
+	container.On("ImageName").Return("registry:latest")
+	assert.False(t, filter(container))
+	container.AssertExpectations(t)
+
+	container = new(mocks.FilterableContainer)
+	container.On("ImageName").Return("localhost:5000/app")
+	assert.True(t, filter(container))
+	container.AssertExpectations(t)
 }
 
 func TestBuildFilter(t *testing.T) {
@@ -331,3 +362,25 @@ func TestBuildFilterDisableContainer(t *testing.T) {
 	assert.False(t, filter(container))
 	container.AssertExpectations(t)
 }
+
+func TestFilterByExactNames(t *testing.T) {
+	filter := FilterByExactNames(nil, nil)
+	assert.Nil(t, filter)
+
+	filter = FilterByExactNames([]string{"web"}, NoFilter)
+
+	container := new(mocks.FilterableContainer)
+	container.On("Name").Return("/web")
+	assert.True(t, filter(container))
+	container.AssertExpectations(t)
+
+	container = new(mocks.FilterableContainer)
+	container.On("Name").Return("/web1")
+	assert.False(t, filter(container))
+	container.AssertExpectations(t)
+
+	container = new(mocks.FilterableContainer)
+	container.On("Name").Return("/my-web")
+	assert.False(t, filter(container))
+	container.AssertExpectations(t)
+}
@@ -0,0 +1,206 @@
+package health
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// The intervals used for deciding whether watchtower is healthy
+const (
+	// PingInterval is how often the Docker daemon is pinged
+	PingInterval = 30 * time.Second
+	// maxPingAge is how long the daemon may be unreachable before watchtower is considered unhealthy
+	maxPingAge = 3 * PingInterval
+	// tickGrace is how late a scheduled run may start before the scheduler is considered stalled
+	tickGrace = time.Minute
+)
+
+// Monitor keeps track of the state that the health of watchtower is derived from
+type Monitor struct {
+	mutex              sync.RWMutex
+	maxSessionDuration time.Duration
+	lastPing           time.Time
+	pingError          error
+	lastTick           time.Time
+	nextTick           time.Time
+	sessionStarted     time.Time
+	sessions           int
+	pauseError         error
+}
+
+// Check is the result of one of the health checks
+type Check struct {
+	Healthy bool   `json:"healthy"`
+	Message string `json:"message"`
+}
+
+// Status is the health of watchtower. It is live if the scheduler and the update sessions are making progress, and
+// ready if it can reach the Docker daemon. It is only healthy if the pause state of the scheduled updates can be read
+// as well.
+type Status struct {
+	Healthy bool             `json:"healthy"`
+	Live    bool             `json:"live"`
+	Ready   bool             `json:"ready"`
+	Checks  map[string]Check `json:"checks"`
+}
+
+// The names of the health checks
+const (
+	DaemonCheck    = "daemon"
+	SchedulerCheck = "scheduler"
+	SessionCheck   = "session"
+	PauseCheck     = "pause"
+)
+
+// NewMonitor creates a new Monitor, considering sessions that run for longer than maxSessionDuration to be stuck. A
+// zero duration disables the session check.
+func NewMonitor(maxSessionDuration time.Duration) *Monitor {
+	return &Monitor{maxSessionDuration: maxSessionDuration}
+}
+
+// WatchDaemon pings the Docker daemon using ping right away, and then every PingInterval, until stop is closed
+func (m *Monitor) WatchDaemon(ping func() error, stop <-chan struct{}) {
+	ticker := time.NewTicker(PingInterval)
+	defer ticker.Stop()
+	for {
+		m.RecordPing(ping(), time.Now())
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// RecordPing records the result of pinging the Docker daemon
+func (m *Monitor) RecordPing(err error, at time.Time) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.pingError = err
+	if err == nil {
+		m.lastPing = at
+	}
+}
+
+// RecordTick records that the scheduler has started a scheduled run, and when the next run is scheduled. It is also
+// used when the scheduler is started, with a zero tick time.
+func (m *Monitor) RecordTick(at time.Time, next time.Time) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if !at.IsZero() {
+		m.lastTick = at
+	}
+	m.nextTick = next
+}
+
+// SessionStarted records that an update session has started
+func (m *Monitor) SessionStarted(at time.Time) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.sessions == 0 {
+		m.sessionStarted = at
+	}
+	m.sessions++
+}
+
+// SessionFinished records that an update session has finished
+func (m *Monitor) SessionFinished() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.sessions > 0 {
+		m.sessions--
+	}
+	if m.sessions == 0 {
+		m.sessionStarted = time.Time{}
+	}
+}
+
+// RecordPauseState records the result of reading whether the scheduled updates are paused
+func (m *Monitor) RecordPauseState(err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.pauseError = err
+}
+
+// Status returns the health at the given time
+func (m *Monitor) Status(now time.Time) Status {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	checks := map[string]Check{
+		DaemonCheck:    m.daemonCheck(now),
+		SchedulerCheck: m.schedulerCheck(now),
+		SessionCheck:   m.sessionCheck(now),
+		PauseCheck:     m.pauseCheck(),
+	}
+	status := Status{
+		Ready:  checks[DaemonCheck].Healthy,
+		Live:   checks[SchedulerCheck].Healthy && checks[SessionCheck].Healthy,
+		Checks: checks,
+	}
+	// A pause state that cannot be read is not fixed by restarting, so it does not affect the liveness
+	status.Healthy = status.Ready && status.Live && checks[PauseCheck].Healthy
+	return status
+}
+
+func (m *Monitor) daemonCheck(now time.Time) Check {
+	switch {
+	case m.lastPing.IsZero() && m.pingError == nil:
+		return Check{Healthy: false, Message: "the Docker daemon has not been pinged yet"}
+	case m.lastPing.IsZero():
+		return Check{Healthy: false, Message: "the Docker daemon could not be reached: " + m.pingError.Error()}
+	case now.Sub(m.lastPing) > maxPingAge:
+		message := "the Docker daemon has not been reachable since " + m.lastPing.Format(time.RFC3339)
+		if m.pingError != nil {
+			message += ": " + m.pingError.Error()
+		}
+		return Check{Healthy: false, Message: message}
+	}
+	return Check{Healthy: true, Message: "last pinged at " + m.lastPing.Format(time.RFC3339)}
+}
+
+func (m *Monitor) schedulerCheck(now time.Time) Check {
+	if m.nextTick.IsZero() {
+		return Check{Healthy: true, Message: "no updates are scheduled"}
+	}
+	lastRun := ""
+	if !m.lastTick.IsZero() {
+		lastRun = ", the last run started at " + m.lastTick.Format(time.RFC3339)
+	}
+	if now.Sub(m.nextTick) > tickGrace {
+		return Check{Healthy: false, Message: "the run scheduled at " + m.nextTick.Format(time.RFC3339) + " has not started" + lastRun}
+	}
+	return Check{Healthy: true, Message: "next run scheduled at " + m.nextTick.Format(time.RFC3339) + lastRun}
+}
+
+func (m *Monitor) sessionCheck(now time.Time) Check {
+	if m.sessionStarted.IsZero() {
+		return Check{Healthy: true, Message: "no session is running"}
+	}
+	running := now.Sub(m.sessionStarted).Round(time.Second)
+	if m.maxSessionDuration > 0 && running > m.maxSessionDuration {
+		return Check{Healthy: false, Message: "the running session started " + running.String() + " ago"}
+	}
+	return Check{Healthy: true, Message: "a session has been running for " + running.String()}
+}
+
+func (m *Monitor) pauseCheck() Check {
+	if m.pauseError != nil {
+		return Check{Healthy: false, Message: "the scheduled updates are skipped, as the pause state could not be read: " + m.pauseError.Error()}
+	}
+	return Check{Healthy: true, Message: "the pause state can be read"}
+}
+
+// MarshalJSON implements json.Marshaler, adding the overall status as a string
+func (s Status) MarshalJSON() ([]byte, error) {
+	type status Status
+	result := "healthy"
+	if !s.Healthy {
+		result = "unhealthy"
+	}
+	return json.Marshal(struct {
+		Status string `json:"status"`
+		status
+	}{result, status(s)})
+}
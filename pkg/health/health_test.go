@@ -0,0 +1,108 @@
+package health_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/containrrr/watchtower/pkg/health"
+)
+
+func TestHealth(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Health Suite")
+}
+
+var _ = Describe("the health monitor", func() {
+	var monitor *health.Monitor
+	var now = time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+
+	BeforeEach(func() {
+		monitor = health.NewMonitor(time.Hour)
+		monitor.RecordPing(nil, now)
+	})
+
+	It("should be healthy when the daemon is reachable and nothing is scheduled", func() {
+		status := monitor.Status(now)
+		Expect(status.Healthy).To(BeTrue())
+		Expect(status.Live).To(BeTrue())
+		Expect(status.Ready).To(BeTrue())
+	})
+
+	When("the Docker daemon cannot be reached", func() {
+		It("should not be ready before the first successful ping", func() {
+			monitor = health.NewMonitor(time.Hour)
+			Expect(monitor.Status(now).Ready).To(BeFalse())
+			monitor.RecordPing(errors.New("connection refused"), now)
+			status := monitor.Status(now)
+			Expect(status.Ready).To(BeFalse())
+			Expect(status.Checks[health.DaemonCheck].Message).To(ContainSubstring("connection refused"))
+		})
+
+		It("should stay ready until the last successful ping is too old", func() {
+			monitor.RecordPing(errors.New("connection refused"), now.Add(health.PingInterval))
+			Expect(monitor.Status(now.Add(health.PingInterval)).Ready).To(BeTrue())
+
+			status := monitor.Status(now.Add(4 * health.PingInterval))
+			Expect(status.Ready).To(BeFalse())
+			Expect(status.Healthy).To(BeFalse())
+			Expect(status.Live).To(BeTrue())
+		})
+	})
+
+	When("updates are scheduled", func() {
+		BeforeEach(func() {
+			monitor.RecordTick(time.Time{}, now.Add(time.Hour))
+		})
+
+		It("should be live while the scheduled runs start on time", func() {
+			Expect(monitor.Status(now.Add(time.Hour + 30*time.Second)).Live).To(BeTrue())
+
+			monitor.RecordTick(now.Add(time.Hour), now.Add(2*time.Hour))
+			status := monitor.Status(now.Add(90 * time.Minute))
+			Expect(status.Live).To(BeTrue())
+			Expect(status.Checks[health.SchedulerCheck].Message).To(ContainSubstring("the last run started at"))
+		})
+
+		It("should not be live once a scheduled run is late", func() {
+			status := monitor.Status(now.Add(time.Hour + 2*time.Minute))
+			Expect(status.Live).To(BeFalse())
+			Expect(status.Checks[health.SchedulerCheck].Healthy).To(BeFalse())
+		})
+	})
+
+	When("the pause state cannot be read", func() {
+		It("should be unhealthy, but stay live and ready", func() {
+			monitor.RecordPauseState(errors.New("failed to parse the pause state"))
+			status := monitor.Status(now)
+			Expect(status.Healthy).To(BeFalse())
+			Expect(status.Live).To(BeTrue())
+			Expect(status.Ready).To(BeTrue())
+			Expect(status.Checks[health.PauseCheck].Message).To(ContainSubstring("failed to parse the pause state"))
+
+			monitor.RecordPauseState(nil)
+			Expect(monitor.Status(now).Healthy).To(BeTrue())
+		})
+	})
+
+	When("a session is running", func() {
+		It("should not be live once the session has run for too long", func() {
+			monitor.SessionStarted(now)
+			Expect(monitor.Status(now.Add(30 * time.Minute)).Live).To(BeTrue())
+			Expect(monitor.Status(now.Add(2 * time.Hour)).Live).To(BeFalse())
+
+			monitor.SessionFinished()
+			Expect(monitor.Status(now.Add(2 * time.Hour)).Checks[health.SessionCheck].Healthy).To(BeTrue())
+		})
+
+		It("should not check the duration when no maximum is set", func() {
+			monitor = health.NewMonitor(0)
+			monitor.RecordPing(nil, now)
+			monitor.SessionStarted(now)
+			Expect(monitor.Status(now.Add(48 * time.Hour)).Checks[health.SessionCheck].Healthy).To(BeTrue())
+		})
+	})
+})
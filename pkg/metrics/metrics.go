@@ -1,6 +1,8 @@
 package metrics
 
 import (
+	"time"
+
 	"github.com/containrrr/watchtower/pkg/types"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
@@ -10,9 +12,12 @@ var metrics *Metrics
 
 // Metric is the data points of a single scan
 type Metric struct {
-	Scanned int
-	Updated int
-	Failed  int
+	Scanned      int
+	Updated      int
+	Failed       int
+	Pulls        int
+	PulledBytes  int64
+	PullDuration time.Duration
 }
 
 // Metrics is the handler processing all individual scan metrics
@@ -23,16 +28,28 @@ type Metrics struct {
 	failed  prometheus.Gauge
 	total   prometheus.Counter
 	skipped prometheus.Counter
+	pulls   prometheus.Counter
+	bytes   prometheus.Counter
+	pulling prometheus.Counter
+	queued  prometheus.Gauge
 }
 
 // NewMetric returns a Metric with the counts taken from the appropriate types.Report fields
 func NewMetric(report types.Report) *Metric {
-	return &Metric{
+	metric := &Metric{
 		Scanned: len(report.Scanned()),
 		// Note: This is for backwards compatibility. ideally, stale containers should be counted separately
 		Updated: len(report.Updated()) + len(report.Stale()),
 		Failed:  len(report.Failed()),
 	}
+	for _, container := range report.All() {
+		if stats := container.PullStats(); !stats.IsEmpty() {
+			metric.Pulls++
+			metric.PulledBytes += stats.Bytes
+			metric.PullDuration += stats.Duration
+		}
+	}
+	return metric
 }
 
 // QueueIsEmpty checks whether any messages are enqueued in the channel
@@ -72,6 +89,22 @@ func Default() *Metrics {
 			Name: "watchtower_scans_skipped",
 			Help: "Number of skipped scans since watchtower started",
 		}),
+		pulls: promauto.NewCounter(prometheus.CounterOpts{
+			Name: "watchtower_image_pulls_total",
+			Help: "Number of image pulls since watchtower started",
+		}),
+		bytes: promauto.NewCounter(prometheus.CounterOpts{
+			Name: "watchtower_image_pull_bytes_total",
+			Help: "Number of bytes downloaded by image pulls since watchtower started",
+		}),
+		pulling: promauto.NewCounter(prometheus.CounterOpts{
+			Name: "watchtower_image_pull_seconds_total",
+			Help: "Time spent pulling images since watchtower started",
+		}),
+		queued: promauto.NewGauge(prometheus.GaugeOpts{
+			Name: "watchtower_notification_queue_depth",
+			Help: "Number of notification messages waiting to be retried",
+		}),
 		channel: make(chan *Metric, 10),
 	}
 
@@ -86,6 +119,11 @@ func RegisterScan(metric *Metric) {
 	metrics.Register(metric)
 }
 
+// SetNotificationQueueDepth sets the number of notification messages waiting to be retried
+func SetNotificationQueueDepth(depth int) {
+	Default().queued.Set(float64(depth))
+}
+
 // HandleUpdate dequeue the metric channel and processes it
 func (metrics *Metrics) HandleUpdate(channel <-chan *Metric) {
 	for change := range channel {
@@ -103,5 +141,8 @@ func (metrics *Metrics) HandleUpdate(channel <-chan *Metric) {
 		metrics.scanned.Set(float64(change.Scanned))
 		metrics.updated.Set(float64(change.Updated))
 		metrics.failed.Set(float64(change.Failed))
+		metrics.pulls.Add(float64(change.Pulls))
+		metrics.bytes.Add(float64(change.PulledBytes))
+		metrics.pulling.Add(change.PullDuration.Seconds())
 	}
 }
@@ -6,10 +6,12 @@ var commonTemplates = map[string]string{
 	`default`: `
 {{- if .Report -}}
   {{- with .Report -}}
-    {{- if ( or .Updated .Failed ) -}}
+    {{- if ( or .Updated .Failed .Rejected ) -}}
 {{len .Scanned}} Scanned, {{len .Updated}} Updated, {{len .Failed}} Failed
+      {{- with .Rejected}}, {{len .}} Rejected{{end}}
       {{- range .Updated}}
 - {{.Name}} ({{.ImageName}}): {{.CurrentImageID.ShortID}} updated to {{.LatestImageID.ShortID}}
+        {{- if and .CurrentMetadata.Version .LatestMetadata.Version}} ({{.CurrentMetadata.Version}} → {{.LatestMetadata.Version}}){{end}}
       {{- end -}}
       {{- range .Fresh}}
 - {{.Name}} ({{.ImageName}}): {{.State}}
@@ -18,6 +20,9 @@ var commonTemplates = map[string]string{
 - {{.Name}} ({{.ImageName}}): {{.State}}: {{.Error}}
 	  {{- end -}}
 	  {{- range .Failed}}
+- {{.Name}} ({{.ImageName}}): {{.State}}: {{.Error}}
+	  {{- end -}}
+	  {{- range .Rejected}}
 - {{.Name}} ({{.ImageName}}): {{.State}}: {{.Error}}
 	  {{- end -}}
     {{- end -}}
@@ -26,6 +31,22 @@ var commonTemplates = map[string]string{
   {{range .Entries -}}{{.Message}}{{"\n"}}{{- end -}}
 {{- end -}}`,
 
+	`digest`: `
+{{- with .Report -}}
+{{.Sessions}} sessions from {{.Start.Format "2006-01-02 15:04"}} to {{.End.Format "2006-01-02 15:04"}}
+{{len .Scanned}} Scanned, {{len .Updated}} Updated, {{len .Failed}} Failed, {{len .Stale}} Stale
+  {{- range .Updated}}
+- {{.Name}} ({{.ImageName}}): updated {{.Updates}} time(s), last at {{.LastUpdated.Format "2006-01-02 15:04"}}
+  {{- end -}}
+  {{- range .Failed}}
+- {{.Name}} ({{.ImageName}}): failed {{.Failures}} time(s), last at {{.LastFailed.Format "2006-01-02 15:04"}}
+    {{- with .Error}}: {{.}}{{end}}
+  {{- end -}}
+  {{- range .Stale}}
+- {{.Name}} ({{.ImageName}}): {{.LatestImageID.ShortID}} available, last checked at {{.LastSeen.Format "2006-01-02 15:04"}}
+  {{- end -}}
+{{- end -}}`,
+
 	`porcelain.v1.summary-no-log`: `
 {{- if .Report -}}
   {{- range .Report.All }}
@@ -38,3 +59,67 @@ var commonTemplates = map[string]string{
 
 	`json.v1`: `{{ . | ToJSON }}`,
 }
+
+var commonHTMLTemplates = map[string]string{
+	`default`: `
+{{- define "containers" -}}
+<table style="border-collapse: collapse; margin-bottom: 16px;">
+  <tr>
+    <th style="text-align: left; padding: 4px 8px; border-bottom: 1px solid #ccc;">Container</th>
+    <th style="text-align: left; padding: 4px 8px; border-bottom: 1px solid #ccc;">Image</th>
+    <th style="text-align: left; padding: 4px 8px; border-bottom: 1px solid #ccc;">Current</th>
+    <th style="text-align: left; padding: 4px 8px; border-bottom: 1px solid #ccc;">Latest</th>
+    <th style="text-align: left; padding: 4px 8px; border-bottom: 1px solid #ccc;">Details</th>
+  </tr>
+  {{- range .}}
+  <tr>
+    <td style="padding: 4px 8px;">{{.Name}}</td>
+    <td style="padding: 4px 8px;">{{.ImageName}}</td>
+    <td style="padding: 4px 8px;"><code>{{.CurrentImageID.ShortID}}</code>{{with .CurrentMetadata.Version}} ({{.}}){{end}}</td>
+    <td style="padding: 4px 8px;"><code>{{.LatestImageID.ShortID}}</code>{{with .LatestMetadata.Version}} ({{.}}){{end}}</td>
+    <td style="padding: 4px 8px;">{{.Error}}{{with .Logs}}<pre>{{.}}</pre>{{end}}</td>
+  </tr>
+  {{- end}}
+</table>
+{{- end -}}
+<html>
+<body style="font-family: sans-serif;">
+{{- with .Report}}
+<p>{{len .Scanned}} Scanned, {{len .Updated}} Updated, {{len .Failed}} Failed{{with .Rejected}}, {{len .}} Rejected{{end}}</p>
+{{- with .Updated}}
+<h3>Updated</h3>
+{{template "containers" .}}
+{{- end}}
+{{- with .Failed}}
+<h3>Failed</h3>
+{{template "containers" .}}
+{{- end}}
+{{- with .Rejected}}
+<h3>Rejected</h3>
+{{template "containers" .}}
+{{- end}}
+{{- with .Stale}}
+<h3>Stale</h3>
+{{template "containers" .}}
+{{- end}}
+{{- with .Unknown}}
+<h3>Unknown</h3>
+{{template "containers" .}}
+{{- end}}
+{{- with .Skipped}}
+<h3>Skipped</h3>
+{{template "containers" .}}
+{{- end}}
+{{- with .Fresh}}
+<h3>Fresh</h3>
+{{template "containers" .}}
+{{- end}}
+{{- else}}
+<pre>
+{{- range .Entries}}{{.Message}}{{"\n"}}{{end -}}
+</pre>
+{{- end}}
+</body>
+</html>
+`,
+}
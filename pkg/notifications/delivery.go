@@ -0,0 +1,113 @@
+package notifications
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	ty "github.com/containrrr/watchtower/pkg/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// DeliveryResult is the outcome of sending a notification to a single service URL
+type DeliveryResult struct {
+	URL   string
+	Error error
+}
+
+// testSender is implemented by notifiers that can send a notification right away, reporting the result for each URL
+type testSender interface {
+	sendTest(entries []*log.Entry, report ty.Report) []DeliveryResult
+}
+
+// SendTestNotification renders a notification from the log entries and the report and sends it to all the services of
+// the notifier right away, bypassing any delays, digest schedules and retry queues. It returns the delivery result for
+// each of the service URLs.
+func SendTestNotification(notifier ty.Notifier, entries []*log.Entry, report ty.Report) []DeliveryResult {
+	if sender, ok := notifier.(testSender); ok {
+		return sender.sendTest(entries, report)
+	}
+	return nil
+}
+
+func failedDeliveries(urls []string, err error) []DeliveryResult {
+	results := make([]DeliveryResult, 0, len(urls))
+	for _, url := range urls {
+		results = append(results, DeliveryResult{URL: url, Error: err})
+	}
+	return results
+}
+
+func (n *shoutrrrTypeNotifier) sendTest(entries []*log.Entry, report ty.Report) []DeliveryResult {
+	data := Data{n.data, entries, report}
+	text, err := n.buildMessage(data)
+	if err == nil && text == "" {
+		err = errors.New("the notification template rendered an empty message")
+	}
+	if err != nil {
+		return failedDeliveries(n.GetURLs(), fmt.Errorf("notification template error: %w", err))
+	}
+
+	message := notificationMessage{text: text}
+	if n.htmlTemplate != nil {
+		if message.html, err = n.buildHTMLMessage(data); err != nil {
+			return failedDeliveries(n.GetURLs(), fmt.Errorf("HTML notification template error: %w", err))
+		}
+	}
+
+	results := make([]DeliveryResult, 0, len(n.Urls)+len(n.htmlSenders))
+	errs := n.Router.Send(message.text, n.params)
+	for i, url := range n.Urls {
+		result := DeliveryResult{URL: url}
+		if i < len(errs) {
+			result.Error = errs[i]
+		}
+		results = append(results, result)
+	}
+	for _, sender := range n.htmlSenders {
+		results = append(results, DeliveryResult{URL: sender.url, Error: sender.Send(message, n.params)})
+	}
+	return results
+}
+
+func (n *webhookNotifier) sendTest(entries []*log.Entry, report ty.Report) []DeliveryResult {
+	payload, err := Data{n.data, entries, report}.MarshalJSON()
+	if err == nil {
+		err = n.post(payload)
+	}
+	return []DeliveryResult{{URL: n.URL, Error: err}}
+}
+
+func (g notifierGroup) sendTest(entries []*log.Entry, report ty.Report) []DeliveryResult {
+	var results []DeliveryResult
+	for _, n := range g {
+		results = append(results, SendTestNotification(n, entries, report)...)
+	}
+	return results
+}
+
+// sendTest sends the unfiltered report to all the routes, so that each of them receive the test notification
+func (n *routingNotifier) sendTest(entries []*log.Entry, report ty.Report) []DeliveryResult {
+	results := SendTestNotification(n.Notifier, entries, report)
+	for _, route := range n.routes {
+		results = append(results, route.notifier.sendTest(nil, report)...)
+	}
+	return results
+}
+
+// sendTest sends the report as a digest of a single session
+func (n *digestNotifier) sendTest(entries []*log.Entry, report ty.Report) []DeliveryResult {
+	if report != nil {
+		now := time.Now()
+		digest := NewDigest(now)
+		digest.Add(report, now)
+		digest.End = now
+		report = digest
+	}
+	return SendTestNotification(n.Notifier, entries, report)
+}
+
+// sendTest sends the report as is, without recording it in the notification state
+func (n *dedupNotifier) sendTest(entries []*log.Entry, report ty.Report) []DeliveryResult {
+	return SendTestNotification(n.Notifier, entries, report)
+}
@@ -0,0 +1,58 @@
+package notifications
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/containrrr/watchtower/internal/actions/mocks"
+	s "github.com/containrrr/watchtower/pkg/session"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("sending test notifications", func() {
+	entries := []*logrus.Entry{{Message: "This is a test notification"}}
+
+	It("should return the delivery result for each service URL", func() {
+		shoutrrr := createNotifier([]string{"logger://", "generic://failing"}, logrus.InfoLevel, "", true, StaticData{}, false, 0)
+		shoutrrr.Router = failingRouter{errs: []error{nil, errors.New("connection refused")}}
+
+		results := SendTestNotification(shoutrrr, entries, nil)
+		Expect(results).To(HaveLen(2))
+		Expect(results[0]).To(Equal(DeliveryResult{URL: "logger://"}))
+		Expect(results[1].URL).To(Equal("generic://failing"))
+		Expect(results[1].Error).To(MatchError("connection refused"))
+	})
+
+	It("should fail all the services when the template renders an empty message", func() {
+		shoutrrr := createNotifier([]string{"logger://"}, logrus.InfoLevel, "", false, StaticData{}, false, 0)
+
+		results := SendTestNotification(shoutrrr, nil, nil)
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].Error).To(HaveOccurred())
+	})
+
+	It("should send the notification through all the wrapping notifiers", func() {
+		server := ghttp.NewServer()
+		defer server.Close()
+		server.AppendHandlers(ghttp.CombineHandlers(
+			ghttp.VerifyRequest("POST", "/hook"),
+			ghttp.RespondWith(http.StatusNoContent, nil),
+		))
+
+		shoutrrr := createNotifier([]string{"logger://"}, logrus.InfoLevel, "digest", false, StaticData{}, false, 0)
+		routes := []Route{{Name: "team", URLs: []string{"logger://team"}, Containers: []string{"nothing-matches"}}}
+		webhook := createWebhookNotifier(server.URL()+"/hook", "", http.Header{}, 0, logrus.InfoLevel, StaticData{})
+		notifier := newDigestNotifier(notifierGroup{newRoutingNotifier(shoutrrr, routes, logrus.InfoLevel, "", StaticData{}, false), webhook}, "@daily")
+
+		results := SendTestNotification(notifier, entries, mocks.CreateMockProgressReport(s.UpdatedState))
+		Expect(results).To(HaveLen(3))
+		for _, result := range results {
+			Expect(result.Error).NotTo(HaveOccurred())
+		}
+		Expect(results[1].URL).To(Equal("logger://team"))
+		Expect(server.ReceivedRequests()).To(HaveLen(1))
+	})
+})
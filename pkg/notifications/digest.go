@@ -0,0 +1,261 @@
+package notifications
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	ty "github.com/containrrr/watchtower/pkg/types"
+	"github.com/robfig/cron"
+	log "github.com/sirupsen/logrus"
+)
+
+// DigestContainer summarizes a container over all the sessions included in a digest. The embedded ContainerReport is
+// the report of the container from the last session it was included in.
+type DigestContainer struct {
+	ty.ContainerReport
+	Sessions    int
+	Updates     int
+	Failures    int
+	FirstSeen   time.Time
+	LastSeen    time.Time
+	LastUpdated time.Time
+	LastFailed  time.Time
+}
+
+// Digest is a report aggregating the container reports of multiple sessions
+type Digest struct {
+	Start    time.Time
+	End      time.Time
+	Sessions int
+
+	containers map[string]*DigestContainer
+}
+
+// NewDigest creates an empty digest for the period starting at start
+func NewDigest(start time.Time) *Digest {
+	return &Digest{
+		Start:      start,
+		End:        start,
+		containers: map[string]*DigestContainer{},
+	}
+}
+
+// Add includes the container reports of a session that ended at now in the digest
+func (d *Digest) Add(report ty.Report, now time.Time) {
+	d.Sessions++
+	d.End = now
+
+	for _, cr := range report.All() {
+		// Containers are identified by name, since their IDs change when they are updated
+		dc, found := d.containers[cr.Name()]
+		if !found {
+			dc = &DigestContainer{FirstSeen: now}
+			d.containers[cr.Name()] = dc
+		}
+		dc.ContainerReport = cr
+		dc.Sessions++
+		dc.LastSeen = now
+
+		switch cr.State() {
+		case "Updated":
+			dc.Updates++
+			dc.LastUpdated = now
+		case "Failed":
+			dc.Failures++
+			dc.LastFailed = now
+		}
+	}
+}
+
+// IsEmpty returns whether no sessions have been added to the digest
+func (d *Digest) IsEmpty() bool {
+	return d.Sessions == 0
+}
+
+func (d *Digest) filter(include func(dc *DigestContainer) bool) []ty.ContainerReport {
+	names := make([]string, 0, len(d.containers))
+	for name, dc := range d.containers {
+		if include(dc) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	reports := make([]ty.ContainerReport, len(names))
+	for i, name := range names {
+		reports[i] = d.containers[name]
+	}
+	return reports
+}
+
+func (d *Digest) withState(state string) []ty.ContainerReport {
+	return d.filter(func(dc *DigestContainer) bool {
+		return dc.State() == state
+	})
+}
+
+// Scanned returns all the containers that were scanned during the period
+func (d *Digest) Scanned() []ty.ContainerReport {
+	return d.filter(func(dc *DigestContainer) bool {
+		return dc.State() != "Skipped"
+	})
+}
+
+// Updated returns the containers that were updated at least once during the period
+func (d *Digest) Updated() []ty.ContainerReport {
+	return d.filter(func(dc *DigestContainer) bool {
+		return dc.Updates > 0
+	})
+}
+
+// Failed returns the containers that failed to update at least once during the period
+func (d *Digest) Failed() []ty.ContainerReport {
+	return d.filter(func(dc *DigestContainer) bool {
+		return dc.Failures > 0
+	})
+}
+
+// Skipped returns the containers that were skipped in the last session they were included in
+func (d *Digest) Skipped() []ty.ContainerReport {
+	return d.withState("Skipped")
+}
+
+// Stale returns the containers that were still stale in the last session they were included in
+func (d *Digest) Stale() []ty.ContainerReport {
+	return d.withState("Stale")
+}
+
+// Fresh returns the containers that were up to date in the last session they were included in
+func (d *Digest) Fresh() []ty.ContainerReport {
+	return d.withState("Fresh")
+}
+
+// Rejected returns the containers whose new image was rejected in the last session they were included in
+func (d *Digest) Rejected() []ty.ContainerReport {
+	return d.withState("Rejected")
+}
+
+// Unknown returns the containers whose staleness was unknown in the last session they were included in
+func (d *Digest) Unknown() []ty.ContainerReport {
+	return d.withState("Unknown")
+}
+
+// All returns all the containers included in the digest
+func (d *Digest) All() []ty.ContainerReport {
+	return d.filter(func(*DigestContainer) bool {
+		return true
+	})
+}
+
+// Filter returns a copy of the digest only containing the containers that match filter
+func (d *Digest) Filter(filter func(ty.ContainerReport) bool) *Digest {
+	filtered := &Digest{
+		Start:      d.Start,
+		End:        d.End,
+		Sessions:   d.Sessions,
+		containers: map[string]*DigestContainer{},
+	}
+	for name, dc := range d.containers {
+		if filter(dc) {
+			filtered.containers[name] = dc
+		}
+	}
+	return filtered
+}
+
+var _ ty.Report = &Digest{}
+
+// Implements Notifier, buffering the session reports and sending them as a digest on a schedule
+type digestNotifier struct {
+	ty.Notifier
+	scheduler *cron.Cron
+	mutex     sync.Mutex
+	digest    *Digest
+	inSession bool
+	due       bool
+}
+
+func newDigestNotifier(notifier ty.Notifier, schedule string) *digestNotifier {
+	n := &digestNotifier{
+		Notifier:  notifier,
+		scheduler: cron.New(),
+		digest:    NewDigest(time.Now()),
+	}
+	if err := n.scheduler.AddFunc(schedule, n.flush); err != nil {
+		log.Fatalf("Invalid notification digest schedule: %v", err)
+	}
+	return n
+}
+
+// StartNotification begins queueing up messages for the session
+func (n *digestNotifier) StartNotification() {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	n.inSession = true
+	n.Notifier.StartNotification()
+}
+
+// SendNotification adds the report to the digest instead of sending it. Notifications without a report, like the
+// startup message, are sent immediately.
+func (n *digestNotifier) SendNotification(report ty.Report) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	n.inSession = false
+	if report == nil {
+		n.Notifier.SendNotification(report)
+	} else {
+		n.digest.Add(report, time.Now())
+		if discarder, ok := n.Notifier.(entryDiscarder); ok {
+			discarder.discardEntries()
+		}
+	}
+
+	if n.due {
+		n.sendDigest()
+	}
+}
+
+// flush sends the digest, or defers it until the end of the currently running session
+func (n *digestNotifier) flush() {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	if n.inSession {
+		n.due = true
+		return
+	}
+	n.sendDigest()
+}
+
+// sendDigest sends the current digest and starts a new one, assuming that the mutex is held by the caller
+func (n *digestNotifier) sendDigest() {
+	n.due = false
+	if n.digest.IsEmpty() {
+		LocalLog.Debug("Skipping notification digest as no sessions have run since the last one")
+		return
+	}
+
+	digest := n.digest
+	digest.End = time.Now()
+	n.digest = NewDigest(digest.End)
+
+	LocalLog.WithField("sessions", digest.Sessions).Debug("Sending notification digest")
+	n.Notifier.StartNotification()
+	n.Notifier.SendNotification(digest)
+}
+
+// AddLogHook adds the notifier as a receiver of log messages and starts the digest schedule
+func (n *digestNotifier) AddLogHook() {
+	n.Notifier.AddLogHook()
+	n.scheduler.Start()
+}
+
+// Close sends the sessions that have not been included in a digest yet and waits for the messages to be sent
+func (n *digestNotifier) Close() {
+	n.scheduler.Stop()
+	n.flush()
+	n.Notifier.Close()
+}
@@ -0,0 +1,95 @@
+package notifications
+
+import (
+	"time"
+
+	"github.com/containrrr/watchtower/internal/actions/mocks"
+	s "github.com/containrrr/watchtower/pkg/session"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("notification digests", func() {
+	start := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+
+	When("aggregating sessions", func() {
+		digest := NewDigest(start)
+		digest.Add(mocks.CreateMockProgressReport(s.UpdatedState, s.FailedState, s.StaleState), start.Add(time.Hour))
+		digest.Add(mocks.CreateMockProgressReport(s.FailedState, s.StaleState), start.Add(2*time.Hour))
+
+		It("should count the sessions", func() {
+			Expect(digest.Sessions).To(Equal(2))
+			Expect(digest.End).To(Equal(start.Add(2 * time.Hour)))
+		})
+		It("should include the containers updated during the period", func() {
+			Expect(routeNames(digest)).To(ConsistOf("updt1", "fail1", "stal1"))
+			Expect(digest.Updated()).To(HaveLen(1))
+			updated := digest.Updated()[0].(*DigestContainer)
+			Expect(updated.Updates).To(Equal(1))
+			Expect(updated.LastUpdated).To(Equal(start.Add(time.Hour)))
+		})
+		It("should count the failures of each container", func() {
+			Expect(digest.Failed()).To(HaveLen(1))
+			failed := digest.Failed()[0].(*DigestContainer)
+			Expect(failed.Failures).To(Equal(2))
+			Expect(failed.FirstSeen).To(Equal(start.Add(time.Hour)))
+			Expect(failed.LastFailed).To(Equal(start.Add(2 * time.Hour)))
+		})
+		It("should use the last state of the containers that are still stale", func() {
+			Expect(digest.Stale()).To(HaveLen(1))
+			Expect(digest.Stale()[0].(*DigestContainer).Sessions).To(Equal(2))
+		})
+		It("should keep the period when filtered", func() {
+			route := Route{Name: "failures", Containers: []string{"fail1", "stal1"}, States: []string{"failed"}}
+			filtered := digest.Filter(route.Matches)
+			Expect(routeNames(filtered)).To(ConsistOf("fail1"))
+			Expect(filtered.Sessions).To(Equal(2))
+		})
+		It("should render the digest template", func() {
+			notifier := createNotifier([]string{}, logrus.InfoLevel, "digest", false, StaticData{}, false, 0)
+			message, err := notifier.buildMessage(Data{Report: digest})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(message).To(HavePrefix("2 sessions from 2026-03-02 08:00 to 2026-03-02 10:00\n"))
+			Expect(message).To(ContainSubstring("3 Scanned, 1 Updated, 1 Failed, 1 Stale"))
+			Expect(message).To(ContainSubstring("- updt1 (mock/updt1:latest): updated 1 time(s), last at 2026-03-02 09:00"))
+			Expect(message).To(ContainSubstring("- fail1 (mock/fail1:latest): failed 2 time(s), last at 2026-03-02 10:00: "))
+		})
+	})
+
+	When("sending digests", func() {
+		var shoutrrr *shoutrrrTypeNotifier
+		var notifier *digestNotifier
+
+		BeforeEach(func() {
+			shoutrrr = createNotifier([]string{}, logrus.InfoLevel, "digest", false, StaticData{}, false, 0)
+			notifier = &digestNotifier{Notifier: shoutrrr, digest: NewDigest(start)}
+		})
+
+		It("should buffer the session reports until the digest is due", func() {
+			for i := 0; i < 2; i++ {
+				notifier.StartNotification()
+				Expect(shoutrrr.Fire(&logrus.Entry{Message: "Found new image"})).To(Succeed())
+				notifier.SendNotification(mocks.CreateMockProgressReport(s.UpdatedState))
+			}
+			Expect(shoutrrr.messages).NotTo(Receive())
+			Expect(shoutrrr.entries).To(BeNil())
+
+			notifier.flush()
+			Expect(shoutrrr.messages).To(Receive(ContainSubstring("updated 2 time(s)")))
+			Expect(notifier.digest.IsEmpty()).To(BeTrue())
+		})
+		It("should wait for the running session to finish", func() {
+			notifier.StartNotification()
+			notifier.flush()
+			Expect(shoutrrr.messages).NotTo(Receive())
+
+			notifier.SendNotification(mocks.CreateMockProgressReport(s.FailedState))
+			Expect(shoutrrr.messages).To(Receive(ContainSubstring("1 sessions")))
+		})
+		It("should not send empty digests", func() {
+			notifier.flush()
+			Expect(shoutrrr.messages).NotTo(Receive())
+		})
+	})
+})
@@ -0,0 +1,135 @@
+package notifications
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/containrrr/shoutrrr"
+	"github.com/containrrr/shoutrrr/pkg/services/smtp"
+	"github.com/containrrr/shoutrrr/pkg/types"
+	"github.com/containrrr/watchtower/pkg/notifications/templates"
+)
+
+const smtpScheme = "smtp"
+
+// notificationMessage is a rendered notification, with an optional HTML version for the services supporting it, and
+// an optional function that is called once it has been sent
+type notificationMessage struct {
+	text      string
+	html      string
+	delivered func(err error)
+}
+
+// String returns the plain text message
+func (m notificationMessage) String() string {
+	return m.text
+}
+
+func getHTMLTemplate(tplString string) (*htmltemplate.Template, error) {
+	if builtin, found := commonHTMLTemplates[tplString]; found {
+		tplString = builtin
+	}
+	return htmltemplate.New("").Funcs(htmltemplate.FuncMap(templates.Funcs)).Parse(tplString)
+}
+
+func (n *shoutrrrTypeNotifier) buildHTMLMessage(data Data) (string, error) {
+	var body bytes.Buffer
+	if err := n.htmlTemplate.Execute(&body, data); err != nil {
+		return "", err
+	}
+	return body.String(), nil
+}
+
+// htmlEmailSender sends notifications to an SMTP service as multipart messages with a plain text and an HTML part
+type htmlEmailSender struct {
+	url     string
+	service *smtp.Service
+	// mutex guards the HTML template of the service, which is replaced for every message
+	mutex sync.Mutex
+}
+
+func newHTMLEmailSender(rawURL string, logger types.StdLogger) (*htmlEmailSender, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	query := parsed.Query()
+	for key := range query {
+		if strings.EqualFold(key, "usehtml") {
+			query.Del(key)
+		}
+	}
+	query.Set("usehtml", "yes")
+	parsed.RawQuery = query.Encode()
+
+	sender, err := shoutrrr.NewSender(logger)
+	if err != nil {
+		return nil, err
+	}
+	service, err := sender.Locate(parsed.String())
+	if err != nil {
+		return nil, err
+	}
+	smtpService, ok := service.(*smtp.Service)
+	if !ok {
+		return nil, fmt.Errorf("%q is not an SMTP service URL", GetScheme(rawURL))
+	}
+	if err := smtpService.SetTemplateString("plain", "{{.message}}"); err != nil {
+		return nil, err
+	}
+	return &htmlEmailSender{url: rawURL, service: smtpService}, nil
+}
+
+// Send sends the message, using the HTML version for the HTML part if there is one
+func (s *htmlEmailSender) Send(message notificationMessage, params *types.Params) error {
+	html := message.html
+	if html == "" {
+		html = "<pre>" + htmltemplate.HTMLEscapeString(message.text) + "</pre>"
+	}
+
+	// The SMTP service renders both parts of the multipart message from the same message string using its templates,
+	// so the plain text is sent as the message, while the HTML part is rendered from a template holding the HTML body
+	// as a quoted string literal
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.service.SetTemplateString("HTML", "{{"+strconv.Quote(html)+"}}"); err != nil {
+		return err
+	}
+	return s.service.Send(message.text, params)
+}
+
+// enableHTML makes the notifier send multipart messages rendered with the HTML template to the SMTP services
+func (n *shoutrrrTypeNotifier) enableHTML(tpl *htmltemplate.Template, stdout bool) error {
+	logger := getShoutrrrLogger(stdout)
+
+	var urls []string
+	for _, serviceURL := range n.Urls {
+		if GetScheme(serviceURL) != smtpScheme {
+			urls = append(urls, serviceURL)
+			continue
+		}
+		sender, err := newHTMLEmailSender(serviceURL, logger)
+		if err != nil {
+			return err
+		}
+		n.htmlSenders = append(n.htmlSenders, sender)
+	}
+
+	if len(n.htmlSenders) == 0 {
+		return nil
+	}
+
+	r, err := shoutrrr.NewSender(logger, urls...)
+	if err != nil {
+		return err
+	}
+	n.Urls = urls
+	n.Router = r
+	n.htmlTemplate = tpl
+	return nil
+}
@@ -0,0 +1,90 @@
+package notifications
+
+import (
+	"bytes"
+
+	"github.com/containrrr/watchtower/internal/actions/mocks"
+	s "github.com/containrrr/watchtower/pkg/session"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+const mockSMTPURL = "smtp://127.0.0.1:1/?fromaddress=watchtower%40example.com&toaddresses=ops%40example.com&usestarttls=no"
+
+var _ = Describe("HTML email notifications", func() {
+	When("enabling HTML notifications", func() {
+		It("should send the SMTP services multipart messages", func() {
+			tpl, err := getHTMLTemplate("default")
+			Expect(err).NotTo(HaveOccurred())
+
+			notifier := createNotifier([]string{"logger://", mockSMTPURL}, logrus.InfoLevel, "", false, StaticData{}, false, 0)
+			Expect(notifier.enableHTML(tpl, false)).To(Succeed())
+
+			Expect(notifier.Urls).To(Equal([]string{"logger://"}))
+			Expect(notifier.htmlSenders).To(HaveLen(1))
+			Expect(notifier.GetNames()).To(Equal([]string{"logger", "smtp"}))
+			Expect(notifier.GetURLs()).To(Equal([]string{"logger://", mockSMTPURL}))
+		})
+		It("should render the HTML template for each message", func() {
+			tpl, err := getHTMLTemplate("default")
+			Expect(err).NotTo(HaveOccurred())
+
+			notifier := createNotifier([]string{mockSMTPURL}, logrus.InfoLevel, "", false, StaticData{}, false, 0)
+			Expect(notifier.enableHTML(tpl, false)).To(Succeed())
+			notifier.sendEntries(nil, mocks.CreateMockProgressReport(s.UpdatedState), nil)
+
+			var message notificationMessage
+			Expect(notifier.messages).To(Receive(&message))
+			Expect(message.text).To(HavePrefix("1 Scanned, 1 Updated, 0 Failed"))
+			Expect(message.html).To(ContainSubstring("<h3>Updated</h3>"))
+		})
+	})
+
+	When("using the default HTML template", func() {
+		tpl, _ := getHTMLTemplate("default")
+		render := func(data Data) string {
+			var body bytes.Buffer
+			Expect(tpl.Execute(&body, data)).To(Succeed())
+			return body.String()
+		}
+
+		It("should show a table of the containers for each state", func() {
+			html := render(Data{Report: mocks.CreateMockProgressReport(s.UpdatedState, s.FailedState, s.FailedState)})
+			Expect(html).To(ContainSubstring("<p>3 Scanned, 1 Updated, 2 Failed</p>"))
+			Expect(html).To(ContainSubstring("<h3>Updated</h3>"))
+			Expect(html).To(ContainSubstring("<h3>Failed</h3>"))
+			Expect(html).NotTo(ContainSubstring("<h3>Stale</h3>"))
+			Expect(html).To(ContainSubstring(`<td style="padding: 4px 8px;">fail2</td>`))
+			Expect(html).To(ContainSubstring(`<td style="padding: 4px 8px;">accidentally the whole container</td>`))
+		})
+		It("should escape the log entries when there is no report", func() {
+			html := render(Data{Entries: []*logrus.Entry{{Message: "Found <new> image"}}})
+			Expect(html).To(ContainSubstring("<pre>Found &lt;new&gt; image\n</pre>"))
+		})
+	})
+
+	When("sending a multipart email", func() {
+		It("should use the plain text and HTML messages for each of the parts", func() {
+			sender, err := newHTMLEmailSender(mockSMTPURL, getShoutrrrLogger(false))
+			Expect(err).NotTo(HaveOccurred())
+
+			// The service is not reachable, but the templates are set up before sending
+			message := notificationMessage{text: "1 Updated: {{.message}}", html: "<p>1 Updated: \"{{.message}}\"</p>\n"}
+			Expect(sender.Send(message, nil)).NotTo(Succeed())
+
+			data := map[string]string{"message": message.text}
+			for part, expected := range map[string]string{"plain": message.text, "HTML": message.html} {
+				tpl, found := sender.service.GetTemplate(part)
+				Expect(found).To(BeTrue())
+				var body bytes.Buffer
+				Expect(tpl.Execute(&body, data)).To(Succeed())
+				Expect(body.String()).To(Equal(expected))
+			}
+		})
+		It("should reject services other than SMTP", func() {
+			_, err := newHTMLEmailSender("logger://", getShoutrrrLogger(false))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -22,14 +22,7 @@ func (d Data) MarshalJSON() ([]byte, error) {
 
 	var report jsonMap
 	if d.Report != nil {
-		report = jsonMap{
-			`scanned`: marshalReports(d.Report.Scanned()),
-			`updated`: marshalReports(d.Report.Updated()),
-			`failed`:  marshalReports(d.Report.Failed()),
-			`skipped`: marshalReports(d.Report.Skipped()),
-			`stale`:   marshalReports(d.Report.Stale()),
-			`fresh`:   marshalReports(d.Report.Fresh()),
-		}
+		report = ReportJSON(d.Report)
 	}
 
 	return json.Marshal(jsonMap{
@@ -40,6 +33,26 @@ func (d Data) MarshalJSON() ([]byte, error) {
 	})
 }
 
+// ReportJSON returns the representation of the session report that is used in the JSON notification data
+func ReportJSON(report t.Report) map[string]interface{} {
+	data := jsonMap{
+		`scanned`:  marshalReports(report.Scanned()),
+		`updated`:  marshalReports(report.Updated()),
+		`failed`:   marshalReports(report.Failed()),
+		`skipped`:  marshalReports(report.Skipped()),
+		`stale`:    marshalReports(report.Stale()),
+		`fresh`:    marshalReports(report.Fresh()),
+		`rejected`: marshalReports(report.Rejected()),
+		`unknown`:  marshalReports(report.Unknown()),
+	}
+	if digest, ok := report.(*Digest); ok {
+		data[`start`] = digest.Start
+		data[`end`] = digest.End
+		data[`sessions`] = digest.Sessions
+	}
+	return data
+}
+
 func marshalReports(reports []t.ContainerReport) []jsonMap {
 	jsonReports := make([]jsonMap, len(reports))
 	for i, report := range reports {
@@ -51,11 +64,57 @@ func marshalReports(reports []t.ContainerReport) []jsonMap {
 			`imageName`:      report.ImageName(),
 			`state`:          report.State(),
 		}
+		if digest := report.LatestDigest(); digest != "" {
+			jsonReports[i][`latestDigest`] = digest
+		}
 		if errorMessage := report.Error(); errorMessage != "" {
 			jsonReports[i][`error`] = errorMessage
 		}
+		if metadata := report.CurrentMetadata(); !metadata.IsEmpty() {
+			jsonReports[i][`currentImage`] = marshalMetadata(metadata)
+		}
+		if metadata := report.LatestMetadata(); !metadata.IsEmpty() {
+			jsonReports[i][`latestImage`] = marshalMetadata(metadata)
+		}
+		if stats := report.PullStats(); !stats.IsEmpty() {
+			jsonReports[i][`pull`] = jsonMap{
+				`bytes`:    stats.Bytes,
+				`duration`: stats.Duration.Seconds(),
+			}
+		}
+		if logs := report.Logs(); logs != "" {
+			jsonReports[i][`logs`] = logs
+		}
+		if digest, ok := report.(*DigestContainer); ok {
+			jsonReports[i][`digest`] = marshalDigestContainer(digest)
+		}
 	}
 	return jsonReports
 }
 
+func marshalMetadata(metadata t.ImageMetadata) jsonMap {
+	return jsonMap{
+		`version`:  metadata.Version,
+		`revision`: metadata.Revision,
+		`source`:   metadata.Source,
+	}
+}
+
+func marshalDigestContainer(digest *DigestContainer) jsonMap {
+	summary := jsonMap{
+		`sessions`:  digest.Sessions,
+		`updates`:   digest.Updates,
+		`failures`:  digest.Failures,
+		`firstSeen`: digest.FirstSeen,
+		`lastSeen`:  digest.LastSeen,
+	}
+	if !digest.LastUpdated.IsZero() {
+		summary[`lastUpdated`] = digest.LastUpdated
+	}
+	if !digest.LastFailed.IsZero() {
+		summary[`lastFailed`] = digest.LastFailed
+	}
+	return summary
+}
+
 var _ json.Marshaler = &Data{}
@@ -42,6 +42,7 @@ var _ = Describe("JSON template", func() {
 				"state": "Fresh"
 			}
 		],
+		"rejected": [],
 		"scanned": [
 			{
 				"currentImageId": "01d110000000",
@@ -89,6 +90,7 @@ var _ = Describe("JSON template", func() {
 			}
 		],
 		"stale": [],
+		"unknown": [],
 		"updated": [
 			{
 				"currentImageId": "01d110000000",
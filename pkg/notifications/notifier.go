@@ -1,6 +1,7 @@
 package notifications
 
 import (
+	htmltemplate "html/template"
 	"os"
 	"strings"
 	"time"
@@ -25,10 +26,180 @@ func NewNotifier(c *cobra.Command) ty.Notifier {
 	tplString, _ := f.GetString("notification-template")
 	urls, _ := f.GetStringArray("notification-url")
 
+	digestSchedule, _ := f.GetString("notification-digest-schedule")
+	if digestSchedule != "" {
+		// Digests are sent as reports, using the digest template unless one is configured
+		if !reportTemplate {
+			tplString = "digest"
+		}
+		reportTemplate = true
+	}
+
 	data := GetTemplateData(c)
 	urls, delay := AppendLegacyUrls(urls, c)
 
-	return createNotifier(urls, logLevel, tplString, !reportTemplate, data, stdout, delay)
+	urlTemplates, err := GetURLTemplates(c)
+	if err != nil {
+		log.Fatalf("Invalid notification URL templates: %v", err)
+	}
+	defaultURLs, templatedGroups := groupURLsByTemplate(urls, urlTemplates)
+
+	shoutrrrNotifier := createNotifier(defaultURLs, logLevel, tplString, !reportTemplate, data, stdout, delay)
+	var tplFile *templateFile
+	if tplFilePath, _ := f.GetString("notification-template-file"); tplFilePath != "" {
+		tplFile = newTemplateFile(tplFilePath, !reportTemplate)
+		shoutrrrNotifier.templateFile = tplFile
+	}
+
+	// Services with their own template get a separate notifier, rendering the messages using that template
+	shoutrrrNotifiers := []*shoutrrrTypeNotifier{shoutrrrNotifier}
+	group := notifierGroup{shoutrrrNotifier}
+	for _, templated := range templatedGroups {
+		n := createURLTemplateNotifier(templated, logLevel, !reportTemplate, data, stdout, delay)
+		shoutrrrNotifiers = append(shoutrrrNotifiers, n)
+		group = append(group, n)
+	}
+	var notifier ty.Notifier = shoutrrrNotifier
+	if len(group) > 1 {
+		notifier = group
+	}
+
+	var htmlTemplate *htmltemplate.Template
+	if htmlTplString, _ := f.GetString("notification-html-template"); htmlTplString != "" {
+		if htmlTemplate, err = getHTMLTemplate(htmlTplString); err != nil {
+			log.Fatalf("Invalid notification HTML template: %v", err)
+		}
+		for _, n := range shoutrrrNotifiers {
+			enableHTML(n, htmlTemplate, stdout)
+		}
+	}
+
+	var queue *DeliveryQueue
+	if queueFile, _ := f.GetString("notification-queue-file"); queueFile != "" {
+		queueSize, _ := f.GetInt("notification-queue-size")
+		queue = newDeliveryQueue(queueFile, queueSize)
+		for _, n := range shoutrrrNotifiers {
+			n.queue = queue
+		}
+	}
+
+	routes, err := GetRoutes(c)
+	if err != nil {
+		log.Fatalf("Invalid notification routes: %v", err)
+	}
+	if len(routes) > 0 {
+		// Routes always send reports, so the default report template is used unless one is configured
+		routeTemplate := tplString
+		if !reportTemplate {
+			routeTemplate = ""
+		}
+		routing := newRoutingNotifier(notifier, routes, logLevel, routeTemplate, data, stdout)
+		for _, route := range routing.routes {
+			route.notifier.queue = queue
+			if reportTemplate {
+				route.notifier.templateFile = tplFile
+			}
+			if htmlTemplate != nil {
+				enableHTML(route.notifier, htmlTemplate, stdout)
+			}
+		}
+		notifier = routing
+	}
+
+	if webhookURL, _ := f.GetString("notification-webhook-url"); webhookURL != "" {
+		notifier = notifierGroup{notifier, newWebhookNotifier(c, logLevel, data)}
+	}
+
+	if digestSchedule != "" {
+		notifier = newDigestNotifier(notifier, digestSchedule)
+	}
+
+	if stateFile, _ := f.GetString("notification-state-file"); stateFile != "" {
+		reminder, _ := f.GetDuration("notification-reminder-interval")
+		notifier = newDedupNotifier(notifier, stateFile, reminder)
+	}
+
+	return notifier
+}
+
+// createURLTemplateNotifier creates a notifier for the URLs, using either a built-in template or a template file
+func createURLTemplateNotifier(templated templatedURLs, level log.Level, legacy bool, data StaticData, stdout bool, delay time.Duration) *shoutrrrTypeNotifier {
+	if _, builtin := commonTemplates[templated.template]; builtin {
+		return createNotifier(templated.urls, level, templated.template, legacy, data, stdout, delay)
+	}
+	n := createNotifier(templated.urls, level, "", legacy, data, stdout, delay)
+	n.templateFile = newTemplateFile(templated.template, legacy)
+	return n
+}
+
+func enableHTML(notifier *shoutrrrTypeNotifier, tpl *htmltemplate.Template, stdout bool) {
+	if err := notifier.enableHTML(tpl, stdout); err != nil {
+		log.Fatalf("Failed to initialize HTML email notifications: %v", err)
+	}
+}
+
+// notifierGroup passes all the notification calls on to each of the notifiers in the group
+type notifierGroup []ty.Notifier
+
+// StartNotification begins queueing up messages in all the notifiers
+func (g notifierGroup) StartNotification() {
+	for _, n := range g {
+		n.StartNotification()
+	}
+}
+
+// SendNotification sends the queued up messages using all the notifiers
+func (g notifierGroup) SendNotification(report ty.Report) {
+	for _, n := range g {
+		n.SendNotification(report)
+	}
+}
+
+// AddLogHook adds all the notifiers as receivers of log messages
+func (g notifierGroup) AddLogHook() {
+	for _, n := range g {
+		n.AddLogHook()
+	}
+}
+
+// GetNames returns the names of the notification services of all the notifiers
+func (g notifierGroup) GetNames() []string {
+	var names []string
+	for _, n := range g {
+		names = append(names, n.GetNames()...)
+	}
+	return names
+}
+
+// GetURLs returns the URLs of the notification services of all the notifiers
+func (g notifierGroup) GetURLs() []string {
+	var urls []string
+	for _, n := range g {
+		urls = append(urls, n.GetURLs()...)
+	}
+	return urls
+}
+
+// Close waits until all the notifiers have sent their queued up messages
+func (g notifierGroup) Close() {
+	for _, n := range g {
+		n.Close()
+	}
+}
+
+func (g notifierGroup) sendNotificationReporting(report ty.Report, delivered func(err error)) {
+	done := joinDeliveries(len(g), delivered)
+	for _, n := range g {
+		sendReporting(n, report, done)
+	}
+}
+
+func (g notifierGroup) discardEntries() {
+	for _, n := range g {
+		if discarder, ok := n.(entryDiscarder); ok {
+			discarder.discardEntries()
+		}
+	}
 }
 
 // AppendLegacyUrls creates shoutrrr equivalent URLs from legacy notification flags
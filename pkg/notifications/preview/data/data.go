@@ -3,10 +3,13 @@ package data
 import (
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"math/rand"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/containrrr/watchtower/pkg/notifications/templates"
 	"github.com/containrrr/watchtower/pkg/types"
 )
 
@@ -26,9 +29,14 @@ type staticData struct {
 
 // New initializes a new preview data struct
 func New() *previewData {
+	return NewAt(time.Now())
+}
+
+// NewAt initializes a new preview data struct, with the log entries starting 30 minutes before the given time
+func NewAt(now time.Time) *previewData {
 	return &previewData{
 		rand:           rand.New(rand.NewSource(1)),
-		lastTime:       time.Now().Add(-30 * time.Minute),
+		lastTime:       now.Add(-30 * time.Minute),
 		report:         nil,
 		containerCount: 0,
 		Entries:        []*logEntry{},
@@ -47,17 +55,32 @@ func (pb *previewData) AddFromState(state State) {
 	name := pb.generateName()
 	image := pb.generateImageName(name)
 	var err error
+	var logs string
+	var pullStats types.PullStats
+	if state == UpdatedState || state == FailedState || state == RejectedState {
+		pullStats = pb.generatePullStats()
+	}
 	if state == FailedState {
 		err = errors.New(pb.randomEntry(errorMessages))
+		logs = pb.generateLogs(name)
 	} else if state == SkippedState {
 		err = errors.New(pb.randomEntry(skippedMessages))
+	} else if state == RejectedState {
+		err = errors.New(pb.randomEntry(rejectedMessages))
+	} else if state == UnknownState {
+		err = errors.New(pb.randomEntry(unknownMessages))
 	}
 	pb.addContainer(containerStatus{
 		containerID:   cid,
 		oldImage:      old,
 		newImage:      new,
+		oldMetadata:   pb.generateMetadata(name, 0),
+		newMetadata:   pb.generateMetadata(name, 1),
+		pullStats:     pullStats,
+		logs:          logs,
 		containerName: name,
 		imageName:     image,
+		labels:        pb.generateLabels(),
 		error:         err,
 		state:         state,
 	})
@@ -80,6 +103,10 @@ func (pb *previewData) addContainer(c containerStatus) {
 		pb.report.stale = append(pb.report.stale, &c)
 	case FreshState:
 		pb.report.fresh = append(pb.report.fresh, &c)
+	case RejectedState:
+		pb.report.rejected = append(pb.report.rejected, &c)
+	case UnknownState:
+		pb.report.unknown = append(pb.report.unknown, &c)
 	default:
 		return
 	}
@@ -137,6 +164,34 @@ func (pb *previewData) generateName() string {
 	return "/" + containerNames[index] + strconv.FormatInt(int64(suffix), 10)
 }
 
+func (pb *previewData) generateMetadata(name string, patch int) types.ImageMetadata {
+	version := fmt.Sprintf("1.%d.%d", pb.containerCount, patch)
+	return types.NewImageMetadata(map[string]string{
+		types.ImageVersionLabel:  version,
+		types.ImageRevisionLabel: fmt.Sprintf("%07x", pb.containerCount*16+patch),
+		types.ImageSourceLabel:   "https://github.com/" + strings.TrimPrefix(name, "/"),
+	})
+}
+
+func (pb *previewData) generatePullStats() types.PullStats {
+	size := int64(pb.containerCount+1) * 23_456_789
+	return types.PullStats{
+		Bytes:    size,
+		Duration: time.Duration(size/20_000) * time.Millisecond,
+	}
+}
+
+func (pb *previewData) generateLogs(name string) string {
+	return fmt.Sprintf("Starting %s...\nLoading configuration from /etc/%s/config.yml\nError: configuration key \"port\" is required\n",
+		strings.TrimPrefix(name, "/"), strings.TrimPrefix(name, "/"))
+}
+
+func (pb *previewData) generateLabels() map[string]string {
+	return map[string]string{
+		templates.ComposeProjectLabel: composeProjects[pb.containerCount%len(composeProjects)],
+	}
+}
+
 func (pb *previewData) generateImageName(name string) string {
 	index := pb.containerCount % len(organizationNames)
 	return organizationNames[index] + name + ":latest"
@@ -153,6 +153,18 @@ var skippedMessages = []string{
 	"Avoiding the hassle of having to update multiple devices",
 }
 
+var rejectedMessages = []string{
+	"image signature verification failed: no signature found for image",
+	"image signature verification failed: no valid signature found for image",
+	"image signature verification failed: no public keys configured for signature verification",
+}
+
+var unknownMessages = []string{
+	"could not compare the image digests: the local image has no repo digests",
+	"could not compare the image digests: container uses a pinned image",
+	"could not compare the image digests: registry responded to head request with \"401 Unauthorized\"",
+}
+
 var logMessages = []string{
 	"Checking for available updates...",
 	"Downloading update package...",
@@ -176,3 +188,9 @@ var logErrors = []string{
 	"Update installation failed. Rolling back to the previous version...",
 	"Your configuration settings may have been reset to defaults.",
 }
+
+var composeProjects = []string{
+	"frontend",
+	"backend",
+	"monitoring",
+}
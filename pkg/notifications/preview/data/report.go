@@ -10,12 +10,14 @@ import (
 type State string
 
 const (
-	ScannedState State = "scanned"
-	UpdatedState State = "updated"
-	FailedState  State = "failed"
-	SkippedState State = "skipped"
-	StaleState   State = "stale"
-	FreshState   State = "fresh"
+	ScannedState  State = "scanned"
+	UpdatedState  State = "updated"
+	FailedState   State = "failed"
+	SkippedState  State = "skipped"
+	StaleState    State = "stale"
+	FreshState    State = "fresh"
+	RejectedState State = "rejected"
+	UnknownState  State = "unknown"
 )
 
 // StatesFromString parses a string of state characters and returns a slice of the corresponding report states
@@ -35,6 +37,10 @@ func StatesFromString(str string) []State {
 			states = append(states, StaleState)
 		case 'f':
 			states = append(states, FreshState)
+		case 'r':
+			states = append(states, RejectedState)
+		case 'n':
+			states = append(states, UnknownState)
 		default:
 			continue
 		}
@@ -43,12 +49,14 @@ func StatesFromString(str string) []State {
 }
 
 type report struct {
-	scanned []types.ContainerReport
-	updated []types.ContainerReport
-	failed  []types.ContainerReport
-	skipped []types.ContainerReport
-	stale   []types.ContainerReport
-	fresh   []types.ContainerReport
+	scanned  []types.ContainerReport
+	updated  []types.ContainerReport
+	failed   []types.ContainerReport
+	skipped  []types.ContainerReport
+	stale    []types.ContainerReport
+	fresh    []types.ContainerReport
+	rejected []types.ContainerReport
+	unknown  []types.ContainerReport
 }
 
 func (r *report) Scanned() []types.ContainerReport {
@@ -69,9 +77,17 @@ func (r *report) Stale() []types.ContainerReport {
 func (r *report) Fresh() []types.ContainerReport {
 	return r.fresh
 }
+func (r *report) Rejected() []types.ContainerReport {
+	return r.rejected
+}
+
+func (r *report) Unknown() []types.ContainerReport {
+	return r.unknown
+}
 
 func (r *report) All() []types.ContainerReport {
-	allLen := len(r.scanned) + len(r.updated) + len(r.failed) + len(r.skipped) + len(r.stale) + len(r.fresh)
+	allLen := len(r.scanned) + len(r.updated) + len(r.failed) + len(r.skipped) + len(r.stale) + len(r.fresh) +
+		len(r.rejected) + len(r.unknown)
 	all := make([]types.ContainerReport, 0, allLen)
 
 	presentIds := map[types.ContainerID][]string{}
@@ -88,7 +104,9 @@ func (r *report) All() []types.ContainerReport {
 
 	appendUnique(r.updated)
 	appendUnique(r.failed)
+	appendUnique(r.rejected)
 	appendUnique(r.skipped)
+	appendUnique(r.unknown)
 	appendUnique(r.stale)
 	appendUnique(r.fresh)
 	appendUnique(r.scanned)
@@ -6,8 +6,13 @@ type containerStatus struct {
 	containerID   wt.ContainerID
 	oldImage      wt.ImageID
 	newImage      wt.ImageID
+	oldMetadata   wt.ImageMetadata
+	newMetadata   wt.ImageMetadata
+	pullStats     wt.PullStats
 	containerName string
 	imageName     string
+	labels        map[string]string
+	logs          string
 	error
 	state State
 }
@@ -28,10 +33,34 @@ func (u *containerStatus) LatestImageID() wt.ImageID {
 	return u.newImage
 }
 
+func (u *containerStatus) LatestDigest() string {
+	return ""
+}
+
+func (u *containerStatus) CurrentMetadata() wt.ImageMetadata {
+	return u.oldMetadata
+}
+
+func (u *containerStatus) LatestMetadata() wt.ImageMetadata {
+	return u.newMetadata
+}
+
+func (u *containerStatus) PullStats() wt.PullStats {
+	return u.pullStats
+}
+
 func (u *containerStatus) ImageName() string {
 	return u.imageName
 }
 
+func (u *containerStatus) Labels() map[string]string {
+	return u.labels
+}
+
+func (u *containerStatus) Logs() string {
+	return u.logs
+}
+
 func (u *containerStatus) Error() string {
 	if u.error == nil {
 		return ""
@@ -0,0 +1,86 @@
+package preview
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/containrrr/watchtower/pkg/notifications/preview/data"
+	"github.com/containrrr/watchtower/pkg/notifications/templates"
+)
+
+// FuncExamples contains an example template for each of the template functions, used for rendering the fixtures
+var FuncExamples = map[string]string{
+	"ToUpper": `{{range .Report.Updated}}{{.Name | ToUpper}}{{"\n"}}{{end}}`,
+	"ToLower": `{{.StaticData.Title | ToLower}} on {{.StaticData.Host | ToLower}}`,
+	"ToJSON":  `{{with index .Report.Updated 0}}{{.PullStats | ToJSON}}{{end}}`,
+	"Title":   `{{range .Report.Failed}}{{.State | Title}}: {{.Name}}{{"\n"}}{{end}}`,
+	"FormatDuration": `{{range .Report.Updated -}}
+{{.Name}} pulled in {{.PullStats.Duration | FormatDuration}}
+{{end}}`,
+	"TimeAgo": `{{range .Entries}}{{.Time | TimeAgo}}: {{.Message}}{{"\n"}}{{end}}`,
+	"ShortID": `{{range .Report.Updated -}}
+{{.Name}}: {{.ID | ShortID}} {{.CurrentImageID | ShortID}} → {{.LatestImageID | ShortID}}
+{{end}}`,
+	"ShortDigest": `{{"ghcr.io/containrrr/watchtower@sha256:6bd4a7a8a3a5d1ae7e4ddf2a0ab84bdc3f2b12dd86bb6b64e6d6c5a1d7a5c3e1" | ShortDigest}}`,
+	"GroupByState": `{{range GroupByState .Report.All -}}
+{{.Key}}:{{range .Containers}} {{.Name}}{{end}}
+{{end}}`,
+	"GroupByImage": `{{range GroupByImage .Report.Stale -}}
+{{.Key}}:{{range .Containers}} {{.Name}}{{end}}
+{{end}}`,
+	"GroupByProject": `{{range GroupByProject .Report.All -}}
+{{.Key}}: {{.Containers | Count "container"}}
+{{end}}`,
+	"Count":    `{{.Report.Updated | Count "container"}} updated, {{.Report.Rejected | Count "container"}} rejected`,
+	"Truncate": `{{range .Report.Failed}}{{.Error | Truncate 20}}{{"\n"}}{{end}}`,
+	"ReplaceRegex": `{{range .Report.Updated -}}
+{{.ImageName | ReplaceRegex ":latest$" ""}}
+{{end}}`,
+	"Default": `{{range .Report.Stale}}{{.Name}}: {{.Error | Default "no error"}}{{"\n"}}{{end}}`,
+	"Env":     `{{Env "WATCHTOWER_TEMPLATE_FIXTURE_UNSET" | Default "unset"}}`,
+}
+
+// fixtureTime is used as the current time when rendering the fixtures, to make them reproducible
+var fixtureTime = time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+
+// FuncNames returns the names of all the template functions in alphabetical order
+func FuncNames() []string {
+	names := make([]string, 0, len(templates.Funcs))
+	for name := range templates.Funcs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RenderFixture renders the example template for the template function with the given name, using a fixed set of
+// preview data and a fixed current time
+func RenderFixture(name string) (string, error) {
+	example, found := FuncExamples[name]
+	if !found {
+		return "", fmt.Errorf("no example template for function %q", name)
+	}
+
+	previewData := data.NewAt(fixtureTime)
+	for _, state := range data.StatesFromString("cccuuueeekkktttfff") {
+		previewData.AddFromState(state)
+	}
+	for _, level := range data.LevelsFromString("ewwiiidddd") {
+		previewData.AddLogEntry(level)
+	}
+
+	funcs := templates.NewFuncs(func() time.Time { return fixtureTime })
+	tpl, err := template.New(name).Funcs(funcs).Parse(example)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %v", err)
+	}
+
+	var buf strings.Builder
+	if err := tpl.Execute(&buf, previewData); err != nil {
+		return "", fmt.Errorf("failed to execute template: %v", err)
+	}
+	return buf.String(), nil
+}
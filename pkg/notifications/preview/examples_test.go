@@ -0,0 +1,27 @@
+package preview_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/containrrr/watchtower/pkg/notifications/preview"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("the template function fixtures", func() {
+	// The fixtures are generated using: go run ./tplprev -fixtures pkg/notifications/preview/testdata/funcs
+	for _, name := range preview.FuncNames() {
+		name := name
+		It("should match the rendered example for "+name, func() {
+			Expect(preview.FuncExamples).To(HaveKey(name), "every template function needs an example")
+
+			expected, err := os.ReadFile(filepath.Join("testdata", "funcs", name+".txt"))
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err := preview.RenderFixture(name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(string(expected)))
+		})
+	}
+})
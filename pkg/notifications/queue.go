@@ -0,0 +1,256 @@
+package notifications
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/containrrr/shoutrrr"
+	"github.com/containrrr/shoutrrr/pkg/types"
+	"github.com/containrrr/watchtower/pkg/metrics"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	queueInitialBackoff = 30 * time.Second
+	queueMaxBackoff     = time.Hour
+	queueMaxAge         = 24 * time.Hour
+	queueRetryInterval  = 10 * time.Second
+)
+
+// queuedMessage is a notification message that failed to be delivered to a service
+type queuedMessage struct {
+	URL         string       `json:"url"`
+	Message     string       `json:"message"`
+	HTML        string       `json:"html,omitempty"`
+	Params      types.Params `json:"params,omitempty"`
+	Attempts    int          `json:"attempts"`
+	QueuedAt    time.Time    `json:"queuedAt"`
+	NextAttempt time.Time    `json:"nextAttempt"`
+}
+
+// DeliveryQueue keeps the notification messages that failed to be delivered in a file, retrying them with an
+// exponential backoff until they are delivered or have been queued for longer than a day
+type DeliveryQueue struct {
+	path  string
+	size  int
+	mutex sync.Mutex
+	send  func(url string, message notificationMessage, params *types.Params) error
+	stop  chan bool
+	done  chan bool
+
+	Messages []*queuedMessage `json:"messages"`
+}
+
+// LoadDeliveryQueue reads the queued messages from path, keeping at most size messages. A missing file results in an
+// empty queue.
+func LoadDeliveryQueue(path string, size int) (*DeliveryQueue, error) {
+	queue := &DeliveryQueue{
+		path:     path,
+		size:     size,
+		send:     sendToURL,
+		Messages: []*queuedMessage{},
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return queue, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read notification queue: %w", err)
+	}
+
+	if err := json.Unmarshal(content, queue); err != nil {
+		return nil, fmt.Errorf("failed to parse notification queue: %w", err)
+	}
+	queue.trim()
+	for _, queued := range queue.Messages {
+		// Messages left over from the last run are retried right away
+		queued.NextAttempt = time.Time{}
+	}
+	metrics.SetNotificationQueueDepth(len(queue.Messages))
+	return queue, nil
+}
+
+func sendToURL(url string, message notificationMessage, params *types.Params) error {
+	if message.html != "" {
+		// Messages with an HTML version failed to be sent as multipart emails, and are retried as such
+		sender, err := newHTMLEmailSender(url, getShoutrrrLogger(false))
+		if err != nil {
+			return err
+		}
+		return sender.Send(message, params)
+	}
+
+	sender, err := shoutrrr.CreateSender(url)
+	if err != nil {
+		return err
+	}
+	for _, err := range sender.Send(message.text, params) {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Enqueue adds a message that failed to be delivered to the service URL to the queue
+func (q *DeliveryQueue) Enqueue(url string, message notificationMessage, params *types.Params, now time.Time) {
+	queued := &queuedMessage{
+		URL:         url,
+		Message:     message.text,
+		HTML:        message.html,
+		QueuedAt:    now,
+		NextAttempt: now.Add(queueInitialBackoff),
+	}
+	if params != nil {
+		queued.Params = types.Params{}
+		for key, value := range *params {
+			queued.Params[key] = value
+		}
+	}
+
+	q.mutex.Lock()
+	q.Messages = append(q.Messages, queued)
+	q.trim()
+	q.mutex.Unlock()
+
+	q.save()
+}
+
+// trim drops the oldest messages when the queue is full, assuming that the mutex is held by the caller
+func (q *DeliveryQueue) trim() {
+	if q.size <= 0 || len(q.Messages) <= q.size {
+		return
+	}
+	dropped := len(q.Messages) - q.size
+	LocalLog.WithField("dropped", dropped).Warn("Notification queue is full, dropping the oldest messages")
+	q.Messages = q.Messages[dropped:]
+}
+
+// Len returns the number of queued messages
+func (q *DeliveryQueue) Len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return len(q.Messages)
+}
+
+// Retry tries to deliver the messages that are due at now, removing the ones that were delivered or expired
+func (q *DeliveryQueue) Retry(now time.Time) {
+	q.mutex.Lock()
+	due := make([]*queuedMessage, 0, len(q.Messages))
+	for _, queued := range q.Messages {
+		if !queued.NextAttempt.After(now) {
+			due = append(due, queued)
+		}
+	}
+	q.mutex.Unlock()
+
+	if len(due) == 0 {
+		return
+	}
+
+	results := make(map[*queuedMessage]error, len(due))
+	for _, queued := range due {
+		results[queued] = q.send(queued.URL, notificationMessage{text: queued.Message, html: queued.HTML}, &queued.Params)
+	}
+
+	q.mutex.Lock()
+	remaining := make([]*queuedMessage, 0, len(q.Messages))
+	for _, queued := range q.Messages {
+		err, attempted := results[queued]
+		if !attempted {
+			remaining = append(remaining, queued)
+			continue
+		}
+
+		queued.Attempts++
+		fields := log.Fields{"service": GetScheme(queued.URL), "attempts": queued.Attempts}
+		if err == nil {
+			LocalLog.WithFields(fields).Info("Delivered queued notification")
+			continue
+		}
+		if now.Sub(queued.QueuedAt) >= queueMaxAge {
+			LocalLog.WithFields(fields).WithError(err).Error("Giving up on delivering queued notification")
+			continue
+		}
+
+		backoff := queueInitialBackoff << queued.Attempts
+		if backoff > queueMaxBackoff || backoff <= 0 {
+			backoff = queueMaxBackoff
+		}
+		queued.NextAttempt = now.Add(backoff)
+		LocalLog.WithFields(fields).WithError(err).Debugf("Failed to deliver queued notification, retrying in %v", backoff)
+		remaining = append(remaining, queued)
+	}
+	q.Messages = remaining
+	q.mutex.Unlock()
+
+	q.save()
+}
+
+// save writes the queue to the queue file and updates the queue depth metric
+func (q *DeliveryQueue) save() {
+	q.mutex.Lock()
+	content, err := json.Marshal(q)
+	depth := len(q.Messages)
+	q.mutex.Unlock()
+
+	metrics.SetNotificationQueueDepth(depth)
+	if err == nil {
+		// Write to a temporary file first, to prevent leaving a partial queue file behind
+		tmpPath := filepath.Join(filepath.Dir(q.path), "."+filepath.Base(q.path)+".tmp")
+		if err = os.WriteFile(tmpPath, content, 0o600); err == nil {
+			err = os.Rename(tmpPath, q.path)
+		}
+	}
+	if err != nil {
+		LocalLog.WithError(err).Warn("Failed to save the notification queue")
+	}
+}
+
+// Start retries the queued messages in the background, starting with the ones left over from the last run
+func (q *DeliveryQueue) Start() {
+	if q.stop != nil {
+		return
+	}
+	stop, done := make(chan bool), make(chan bool)
+	q.stop, q.done = stop, done
+
+	go func() {
+		ticker := time.NewTicker(queueRetryInterval)
+		defer ticker.Stop()
+
+		q.Retry(time.Now())
+		for {
+			select {
+			case <-ticker.C:
+				q.Retry(time.Now())
+			case <-stop:
+				done <- true
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops retrying the queued messages, keeping them in the queue file for the next run
+func (q *DeliveryQueue) Stop() {
+	if q.stop == nil {
+		return
+	}
+	close(q.stop)
+	<-q.done
+	q.stop = nil
+}
+
+func newDeliveryQueue(path string, size int) *DeliveryQueue {
+	queue, err := LoadDeliveryQueue(path, size)
+	if err != nil {
+		log.Fatalf("Invalid notification queue file: %v", err)
+	}
+	return queue
+}
@@ -0,0 +1,138 @@
+package notifications
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/containrrr/shoutrrr/pkg/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+type failingRouter struct {
+	errs []error
+}
+
+func (f failingRouter) Send(_ string, _ *types.Params) []error {
+	return f.errs
+}
+
+var _ = Describe("the notification delivery queue", func() {
+	var dir string
+	var queuePath string
+	var queue *DeliveryQueue
+	var sent []string
+	var sendErr error
+	now := time.Now()
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "watchtower-queue")
+		Expect(err).NotTo(HaveOccurred())
+		queuePath = filepath.Join(dir, "queue.json")
+		queue, err = LoadDeliveryQueue(queuePath, 2)
+		Expect(err).NotTo(HaveOccurred())
+
+		sent = []string{}
+		sendErr = nil
+		queue.send = func(url string, message notificationMessage, _ *types.Params) error {
+			if sendErr != nil {
+				return sendErr
+			}
+			sent = append(sent, url+" "+message.text)
+			return nil
+		}
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	When("retrying messages", func() {
+		It("should not retry messages before they are due", func() {
+			queue.Enqueue("logger://", notificationMessage{text: "first"}, nil, now)
+			queue.Retry(now)
+			Expect(sent).To(BeEmpty())
+			Expect(queue.Len()).To(Equal(1))
+		})
+		It("should remove the messages that were delivered", func() {
+			queue.Enqueue("logger://", notificationMessage{text: "first"}, nil, now)
+			queue.Retry(now.Add(queueInitialBackoff))
+			Expect(sent).To(ConsistOf("logger:// first"))
+			Expect(queue.Len()).To(Equal(0))
+		})
+		It("should back off exponentially when the delivery fails", func() {
+			sendErr = errors.New("service unavailable")
+			queue.Enqueue("logger://", notificationMessage{text: "first"}, nil, now)
+
+			retryAt := now.Add(queueInitialBackoff)
+			queue.Retry(retryAt)
+			Expect(queue.Messages[0].Attempts).To(Equal(1))
+			Expect(queue.Messages[0].NextAttempt).To(Equal(retryAt.Add(2 * queueInitialBackoff)))
+
+			retryAt = retryAt.Add(2 * queueInitialBackoff)
+			queue.Retry(retryAt)
+			Expect(queue.Messages[0].NextAttempt).To(Equal(retryAt.Add(4 * queueInitialBackoff)))
+		})
+		It("should give up on messages that have been queued for too long", func() {
+			sendErr = errors.New("service unavailable")
+			queue.Enqueue("logger://", notificationMessage{text: "first"}, nil, now)
+			queue.Retry(now.Add(queueMaxAge))
+			Expect(queue.Len()).To(Equal(0))
+		})
+	})
+
+	When("the queue is full", func() {
+		It("should drop the oldest messages", func() {
+			queue.Enqueue("logger://", notificationMessage{text: "first"}, nil, now)
+			queue.Enqueue("logger://", notificationMessage{text: "second"}, nil, now)
+			queue.Enqueue("logger://", notificationMessage{text: "third"}, nil, now)
+			queue.Retry(now.Add(queueInitialBackoff))
+			Expect(sent).To(Equal([]string{"logger:// second", "logger:// third"}))
+		})
+	})
+
+	When("the queue is loaded", func() {
+		It("should retry the queued messages right away", func() {
+			queue.Enqueue("logger://", notificationMessage{text: "first"}, &types.Params{"title": "Watchtower updates"}, now)
+
+			loaded, err := LoadDeliveryQueue(queuePath, 2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(loaded.Messages).To(HaveLen(1))
+			Expect(loaded.Messages[0].Params).To(HaveKeyWithValue("title", "Watchtower updates"))
+			Expect(loaded.Messages[0].NextAttempt.IsZero()).To(BeTrue())
+		})
+
+		It("should keep both the text and the HTML part of the messages", func() {
+			queue.Enqueue("smtp://mail.example.com", notificationMessage{text: "first", html: "<p>first</p>"}, nil, now)
+
+			loaded, err := LoadDeliveryQueue(queuePath, 2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(loaded.Messages).To(HaveLen(1))
+			Expect(loaded.Messages[0].Message).To(Equal("first"))
+			Expect(loaded.Messages[0].HTML).To(Equal("<p>first</p>"))
+		})
+	})
+
+	When("a shoutrrr notification fails", func() {
+		It("should queue the message for the failed service", func() {
+			shoutrrr := createNotifier([]string{}, logrus.InfoLevel, "", true, StaticData{}, false, 0)
+			shoutrrr.Urls = []string{"logger://", "generic://failing"}
+			shoutrrr.Router = failingRouter{errs: []error{nil, errors.New("connection refused")}}
+			shoutrrr.queue = queue
+
+			go sendNotifications(shoutrrr)
+			shoutrrr.StartNotification()
+			Expect(shoutrrr.Fire(&logrus.Entry{Message: "Found new image"})).To(Succeed())
+			shoutrrr.SendNotification(nil)
+			shoutrrr.Close()
+
+			Expect(queue.Messages).To(HaveLen(1))
+			Expect(queue.Messages[0].URL).To(Equal("generic://failing"))
+			Expect(queue.Messages[0].Message).To(Equal("Found new image\n"))
+		})
+	})
+})
@@ -0,0 +1,231 @@
+package notifications
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/containrrr/watchtower/pkg/session"
+	ty "github.com/containrrr/watchtower/pkg/types"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// NotifyToLabel is the container label containing the names of the notification routes that the container should
+// be sent to, separated by commas
+const NotifyToLabel = "com.centurylinklabs.watchtower.notify-to"
+
+// Route is a notification routing rule, sending a report containing only the matching containers to its own URLs
+type Route struct {
+	// Name is used for matching containers that reference the route using the notify-to label
+	Name string `mapstructure:"name"`
+	// URLs are the shoutrrr URLs that the route notifications are sent to
+	URLs []string `mapstructure:"urls"`
+	// Containers are the names of the containers matching the route
+	Containers []string `mapstructure:"containers"`
+	// Labels are the label values that containers must all have to match the route
+	Labels map[string]string `mapstructure:"labels"`
+	// States limits the route to containers in any of the states, e.g. "failed" or "updated"
+	States []string `mapstructure:"states"`
+}
+
+// Matches returns whether the container report should be included in the route notifications
+func (r Route) Matches(report ty.ContainerReport) bool {
+	if len(r.States) > 0 && !containsFold(r.States, report.State()) {
+		return false
+	}
+
+	labels := report.Labels()
+	for _, name := range strings.Split(labels[NotifyToLabel], ",") {
+		if r.Name != "" && strings.TrimSpace(name) == r.Name {
+			return true
+		}
+	}
+
+	if containsFold(r.Containers, strings.TrimPrefix(report.Name(), "/")) {
+		return true
+	}
+
+	if len(r.Labels) == 0 {
+		return false
+	}
+	for key, value := range r.Labels {
+		if labelValue, found := labels[key]; !found || labelValue != value {
+			return false
+		}
+	}
+	return true
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimPrefix(v, "/"), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetRoutes reads the notification routes from the routes file and the route flags
+func GetRoutes(c *cobra.Command) ([]Route, error) {
+	f := c.Flags()
+
+	var routes []Route
+
+	if routesFile, _ := f.GetString("notification-routes-file"); routesFile != "" {
+		fileRoutes, err := ReadRoutesFile(routesFile)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, fileRoutes...)
+	}
+
+	routeValues, _ := f.GetStringArray("notification-route")
+	for _, value := range routeValues {
+		name, url, found := strings.Cut(value, "=")
+		if !found || name == "" || url == "" {
+			return nil, fmt.Errorf("notification route %q is not in the format \"name=url\"", value)
+		}
+		routes = addRouteURL(routes, name, url)
+	}
+
+	for _, route := range routes {
+		if len(route.URLs) == 0 {
+			return nil, fmt.Errorf("notification route %q has no URLs", route.Name)
+		}
+	}
+
+	return routes, nil
+}
+
+// addRouteURL adds the url to the route with the supplied name, creating the route if it does not exist
+func addRouteURL(routes []Route, name string, url string) []Route {
+	for i := range routes {
+		if routes[i].Name == name {
+			routes[i].URLs = append(routes[i].URLs, url)
+			return routes
+		}
+	}
+	return append(routes, Route{Name: name, URLs: []string{url}})
+}
+
+// ReadRoutesFile reads the notification routes from a YAML, JSON or TOML file
+func ReadRoutesFile(path string) ([]Route, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read notification routes file: %w", err)
+	}
+
+	var routes []Route
+	if err := v.UnmarshalKey("routes", &routes); err != nil {
+		return nil, fmt.Errorf("failed to parse notification routes file: %w", err)
+	}
+	return routes, nil
+}
+
+type routeNotifier struct {
+	Route
+	notifier *shoutrrrTypeNotifier
+}
+
+// Implements Notifier, passing all calls on to the main notifier and sending filtered reports to the routes
+type routingNotifier struct {
+	ty.Notifier
+	routes []routeNotifier
+}
+
+func newRoutingNotifier(notifier ty.Notifier, routes []Route, level log.Level, tplString string, data StaticData, stdout bool) *routingNotifier {
+	routed := make([]routeNotifier, 0, len(routes))
+	for _, route := range routes {
+		routed = append(routed, routeNotifier{
+			Route:    route,
+			notifier: createNotifier(route.URLs, level, tplString, false, data, stdout, 0),
+		})
+	}
+	return &routingNotifier{
+		Notifier: notifier,
+		routes:   routed,
+	}
+}
+
+// SendNotification sends the queued up messages using the main notifier, and the filtered reports to the routes
+func (n *routingNotifier) SendNotification(report ty.Report) {
+	n.sendNotificationReporting(report, nil)
+}
+
+func (n *routingNotifier) sendNotificationReporting(report ty.Report, delivered func(err error)) {
+	if report == nil {
+		sendReporting(n.Notifier, report, delivered)
+		return
+	}
+
+	filtered := make([]ty.Report, len(n.routes))
+	count := 1
+	for i, route := range n.routes {
+		var routeReport ty.Report
+		if digest, ok := report.(*Digest); ok {
+			routeReport = digest.Filter(route.Matches)
+		} else {
+			routeReport = session.FilterReport(report, route.Matches)
+		}
+		if len(routeReport.All()) > 0 {
+			filtered[i] = routeReport
+			count++
+		}
+	}
+
+	done := joinDeliveries(count, delivered)
+	sendReporting(n.Notifier, report, done)
+	for i, route := range n.routes {
+		if filtered[i] != nil {
+			LocalLog.WithField("route", route.Name).Debug("Sending notification to route")
+			route.notifier.sendEntries(nil, filtered[i], done)
+		}
+	}
+}
+
+func (n *routingNotifier) discardEntries() {
+	if discarder, ok := n.Notifier.(entryDiscarder); ok {
+		discarder.discardEntries()
+	}
+}
+
+// AddLogHook adds the main notifier as a receiver of log messages and starts processing the route notifications
+func (n *routingNotifier) AddLogHook() {
+	n.Notifier.AddLogHook()
+	for _, route := range n.routes {
+		if !route.notifier.receiving {
+			route.notifier.receiving = true
+			go sendNotifications(route.notifier)
+		}
+	}
+}
+
+// GetNames returns the names of the notification services of the main notifier and all the routes
+func (n *routingNotifier) GetNames() []string {
+	names := n.Notifier.GetNames()
+	for _, route := range n.routes {
+		names = append(names, route.notifier.GetNames()...)
+	}
+	return names
+}
+
+// GetURLs returns the URLs of the notification services of the main notifier and all the routes
+func (n *routingNotifier) GetURLs() []string {
+	urls := n.Notifier.GetURLs()
+	for _, route := range n.routes {
+		urls = append(urls, route.notifier.GetURLs()...)
+	}
+	return urls
+}
+
+// Close waits until the main notifier and all the routes have sent their queued up messages
+func (n *routingNotifier) Close() {
+	n.Notifier.Close()
+	for _, route := range n.routes {
+		if route.notifier.receiving {
+			route.notifier.Close()
+		}
+	}
+}
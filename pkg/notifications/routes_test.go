@@ -0,0 +1,109 @@
+package notifications
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/containrrr/watchtower/internal/actions/mocks"
+	s "github.com/containrrr/watchtower/pkg/session"
+	t "github.com/containrrr/watchtower/pkg/types"
+	dockerContainer "github.com/docker/docker/api/types/container"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+func mockRouteReport() t.Report {
+	progress := s.Progress{}
+	for i, labels := range []map[string]string{
+		{NotifyToLabel: "team-payments,team-ops"},
+		{"team": "search"},
+		{},
+	} {
+		c, newImage := mocks.CreateContainerForProgress(i, 51, "rout%d")
+		c = mocks.CreateMockContainerWithConfig(string(c.ID()), c.Name(), string(c.ImageID()), true, false,
+			time.Now(), &dockerContainer.Config{Labels: labels})
+		progress.AddScanned(c, newImage)
+		progress.MarkForUpdate(c.ID())
+	}
+	return progress.Report()
+}
+
+func routeNames(report t.Report) []string {
+	names := []string{}
+	for _, cr := range report.All() {
+		names = append(names, cr.Name())
+	}
+	return names
+}
+
+var _ = Describe("notification routes", func() {
+	report := mockRouteReport()
+
+	When("matching containers", func() {
+		It("should match containers referencing the route using the notify-to label", func() {
+			route := Route{Name: "team-ops"}
+			Expect(routeNames(s.FilterReport(report, route.Matches))).To(ConsistOf("rout1"))
+		})
+		It("should match containers by name", func() {
+			route := Route{Name: "by-name", Containers: []string{"rout2", "/rout3"}}
+			Expect(routeNames(s.FilterReport(report, route.Matches))).To(ConsistOf("rout2", "rout3"))
+		})
+		It("should match containers by label values", func() {
+			route := Route{Name: "by-label", Labels: map[string]string{"team": "search"}}
+			Expect(routeNames(s.FilterReport(report, route.Matches))).To(ConsistOf("rout2"))
+		})
+		It("should only match containers in the route states", func() {
+			route := Route{Name: "team-payments", States: []string{"failed"}}
+			Expect(routeNames(s.FilterReport(report, route.Matches))).To(BeEmpty())
+			route.States = []string{"failed", "updated"}
+			Expect(routeNames(s.FilterReport(report, route.Matches))).To(ConsistOf("rout1"))
+		})
+	})
+
+	When("reading the routes file", func() {
+		It("should parse the routing rules", func() {
+			dir, err := os.MkdirTemp("", "watchtower-routes")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(dir)
+
+			path := filepath.Join(dir, "routes.yaml")
+			Expect(os.WriteFile(path, []byte(`
+routes:
+  - name: team-payments
+    urls:
+      - logger://
+    states: [failed, updated]
+  - name: search
+    urls: ["logger://"]
+    labels:
+      team: search
+`), 0o600)).To(Succeed())
+
+			routes, err := ReadRoutesFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(routes).To(HaveLen(2))
+			Expect(routes[0].Name).To(Equal("team-payments"))
+			Expect(routes[0].States).To(Equal([]string{"failed", "updated"}))
+			Expect(routes[1].Labels).To(HaveKeyWithValue("team", "search"))
+		})
+	})
+
+	When("sending a notification", func() {
+		It("should send the filtered report to the route", func() {
+			notifier := newRoutingNotifier(createNotifier([]string{}, logrus.InfoLevel, "", false, StaticData{}, false, 0),
+				[]Route{{Name: "team-payments", URLs: []string{"logger://"}}, {Name: "unused", URLs: []string{"logger://"}}},
+				logrus.InfoLevel, "", StaticData{}, false)
+
+			notifier.SendNotification(report)
+
+			var message notificationMessage
+			Expect(notifier.routes[0].notifier.messages).To(Receive(&message))
+			Expect(message).To(ContainSubstring("rout1"))
+			Expect(message).NotTo(ContainSubstring("rout2"))
+			Expect(notifier.routes[1].notifier.messages).NotTo(Receive())
+			Expect(notifier.GetNames()).To(Equal([]string{"logger", "logger"}))
+		})
+	})
+})
@@ -2,6 +2,7 @@ package notifications
 
 import (
 	"bytes"
+	htmltemplate "html/template"
 	stdlog "log"
 	"os"
 	"strings"
@@ -33,13 +34,17 @@ type shoutrrrTypeNotifier struct {
 	entries        []*log.Entry
 	logLevel       log.Level
 	template       *template.Template
-	messages       chan string
+	templateFile   *templateFile
+	messages       chan notificationMessage
 	done           chan bool
 	legacyTemplate bool
 	params         *types.Params
 	data           StaticData
 	receiving      bool
 	delay          time.Duration
+	queue          *DeliveryQueue
+	htmlTemplate   *htmltemplate.Template
+	htmlSenders    []*htmlEmailSender
 }
 
 // GetScheme returns the scheme part of a Shoutrrr URL
@@ -53,16 +58,23 @@ func GetScheme(url string) string {
 
 // GetNames returns a list of notification services that has been added
 func (n *shoutrrrTypeNotifier) GetNames() []string {
-	names := make([]string, len(n.Urls))
-	for i, u := range n.Urls {
-		names[i] = GetScheme(u)
+	names := make([]string, 0, len(n.Urls)+len(n.htmlSenders))
+	for _, u := range n.GetURLs() {
+		names = append(names, GetScheme(u))
 	}
 	return names
 }
 
 // GetURLs returns a list of URLs for notification services that has been added
 func (n *shoutrrrTypeNotifier) GetURLs() []string {
-	return n.Urls
+	if len(n.htmlSenders) == 0 {
+		return n.Urls
+	}
+	urls := append([]string{}, n.Urls...)
+	for _, sender := range n.htmlSenders {
+		urls = append(urls, sender.url)
+	}
+	return urls
 }
 
 // AddLogHook adds the notifier as a receiver of log messages and starts a go func for processing them
@@ -75,6 +87,9 @@ func (n *shoutrrrTypeNotifier) AddLogHook() {
 
 	// Do the sending in a separate goroutine, so we don't block the main process.
 	go sendNotifications(n)
+	if n.queue != nil {
+		n.queue.Start()
+	}
 }
 
 func createNotifier(urls []string, level log.Level, tplString string, legacy bool, data StaticData, stdout bool, delay time.Duration) *shoutrrrTypeNotifier {
@@ -83,13 +98,7 @@ func createNotifier(urls []string, level log.Level, tplString string, legacy boo
 		log.Errorf("Could not use configured notification template: %s. Using default template", err)
 	}
 
-	var logger types.StdLogger
-	if stdout {
-		logger = stdlog.New(os.Stdout, ``, 0)
-	} else {
-		logger = stdlog.New(log.StandardLogger().WriterLevel(log.TraceLevel), "Shoutrrr: ", 0)
-	}
-	r, err := shoutrrr.NewSender(logger, urls...)
+	r, err := shoutrrr.NewSender(getShoutrrrLogger(stdout), urls...)
 	if err != nil {
 		log.Fatalf("Failed to initialize Shoutrrr notifications: %s\n", err.Error())
 	}
@@ -102,7 +111,7 @@ func createNotifier(urls []string, level log.Level, tplString string, legacy boo
 	return &shoutrrrTypeNotifier{
 		Urls:           urls,
 		Router:         r,
-		messages:       make(chan string, 1),
+		messages:       make(chan notificationMessage, 1),
 		done:           make(chan bool),
 		logLevel:       level,
 		template:       tpl,
@@ -113,11 +122,19 @@ func createNotifier(urls []string, level log.Level, tplString string, legacy boo
 	}
 }
 
+func getShoutrrrLogger(stdout bool) types.StdLogger {
+	if stdout {
+		return stdlog.New(os.Stdout, ``, 0)
+	}
+	return stdlog.New(log.StandardLogger().WriterLevel(log.TraceLevel), "Shoutrrr: ", 0)
+}
+
 func sendNotifications(n *shoutrrrTypeNotifier) {
 	for msg := range n.messages {
 		time.Sleep(n.delay)
-		errs := n.Router.Send(msg, n.params)
+		errs := n.Router.Send(msg.text, n.params)
 
+		var sendErr error
 		for i, err := range errs {
 			if err != nil {
 				scheme := GetScheme(n.Urls[i])
@@ -126,8 +143,25 @@ func sendNotifications(n *shoutrrrTypeNotifier) {
 					"service": scheme,
 					"index":   i,
 				}).WithError(err).Error("Failed to send shoutrrr notification")
+				if n.queue != nil {
+					n.queue.Enqueue(n.Urls[i], notificationMessage{text: msg.text}, n.params, time.Now())
+				}
+				sendErr = err
 			}
 		}
+
+		for _, sender := range n.htmlSenders {
+			if err := sender.Send(msg, n.params); err != nil {
+				LocalLog.WithField("service", smtpScheme).WithError(err).Error("Failed to send HTML email notification")
+				if n.queue != nil {
+					n.queue.Enqueue(sender.url, msg, n.params, time.Now())
+				}
+				sendErr = err
+			}
+		}
+		if msg.delivered != nil {
+			msg.delivered(sendErr)
+		}
 	}
 
 	n.done <- true
@@ -139,6 +173,18 @@ func (n *shoutrrrTypeNotifier) buildMessage(data Data) (string, error) {
 	if n.legacyTemplate {
 		templateData = data.Entries
 	}
+
+	if n.templateFile != nil {
+		tpl := n.templateFile.Template()
+		if err := tpl.Execute(&body, templateData); err == nil {
+			return body.String(), nil
+		} else {
+			// Template files can be fixed without restarting, so the error is reported and the default template used
+			go LocalLog.WithError(err).WithField("file", n.templateFile.path).Error("Notification template file error. Using default template")
+			body.Reset()
+		}
+	}
+
 	if err := n.template.Execute(&body, templateData); err != nil {
 		return "", err
 	}
@@ -146,8 +192,10 @@ func (n *shoutrrrTypeNotifier) buildMessage(data Data) (string, error) {
 	return body.String(), nil
 }
 
-func (n *shoutrrrTypeNotifier) sendEntries(entries []*log.Entry, report t.Report) {
-	msg, err := n.buildMessage(Data{n.data, entries, report})
+// sendEntries queues up the notification for sending, calling delivered once it has been sent, if it is not nil
+func (n *shoutrrrTypeNotifier) sendEntries(entries []*log.Entry, report t.Report, delivered func(err error)) {
+	data := Data{n.data, entries, report}
+	msg, err := n.buildMessage(data)
 
 	if msg == "" {
 		// Log in go func in case we entered from Fire to avoid stalling
@@ -158,9 +206,20 @@ func (n *shoutrrrTypeNotifier) sendEntries(entries []*log.Entry, report t.Report
 				LocalLog.Info("Skipping notification due to empty message")
 			}
 		}()
+		if delivered != nil {
+			delivered(err)
+		}
 		return
 	}
-	n.messages <- msg
+
+	message := notificationMessage{text: msg, delivered: delivered}
+	if n.htmlTemplate != nil {
+		if message.html, err = n.buildHTMLMessage(data); err != nil {
+			// Fall back to the plain text message, logging in a go func for the same reason as above
+			go LocalLog.WithError(err).Error("HTML notification template error")
+		}
+	}
+	n.messages <- message
 }
 
 // StartNotification begins queueing up messages to send them as a batch
@@ -172,7 +231,15 @@ func (n *shoutrrrTypeNotifier) StartNotification() {
 
 // SendNotification sends the queued up messages as a notification
 func (n *shoutrrrTypeNotifier) SendNotification(report t.Report) {
-	n.sendEntries(n.entries, report)
+	n.sendNotificationReporting(report, nil)
+}
+
+func (n *shoutrrrTypeNotifier) sendNotificationReporting(report t.Report, delivered func(err error)) {
+	n.sendEntries(n.entries, report, delivered)
+	n.entries = nil
+}
+
+func (n *shoutrrrTypeNotifier) discardEntries() {
 	n.entries = nil
 }
 
@@ -184,6 +251,9 @@ func (n *shoutrrrTypeNotifier) Close() {
 	LocalLog.Info("Waiting for the notification goroutine to finish")
 
 	<-n.done
+	if n.queue != nil {
+		n.queue.Stop()
+	}
 }
 
 // Levels return what log levels trigger notifications
@@ -201,7 +271,7 @@ func (n *shoutrrrTypeNotifier) Fire(entry *log.Entry) error {
 		n.entries = append(n.entries, entry)
 	} else {
 		// Log output generated outside a cycle is sent immediately.
-		n.sendEntries([]*log.Entry{entry}, nil)
+		n.sendEntries([]*log.Entry{entry}, nil, nil)
 	}
 	return nil
 }
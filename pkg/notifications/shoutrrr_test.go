@@ -341,7 +341,7 @@ func sendNotificationsWithBlockingRouter(legacy bool) (*shoutrrrTypeNotifier, *b
 
 	shoutrrr := &shoutrrrTypeNotifier{
 		template:       tpl,
-		messages:       make(chan string, 1),
+		messages:       make(chan notificationMessage, 1),
 		done:           make(chan bool),
 		Router:         router,
 		legacyTemplate: legacy,
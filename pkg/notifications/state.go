@@ -0,0 +1,228 @@
+package notifications
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/containrrr/watchtower/pkg/session"
+	ty "github.com/containrrr/watchtower/pkg/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// notifiedContainer is the state of a container when it was last included in a notification
+type notifiedContainer struct {
+	State       string `json:"state"`
+	LatestImage string `json:"latestImage"`
+	// LatestDigest is the digest reported by the registry for containers checked without pulling
+	LatestDigest string    `json:"latestDigest,omitempty"`
+	NotifiedAt   time.Time `json:"notifiedAt"`
+}
+
+// NotificationState keeps track of which container states have already been notified, persisting them to a file
+type NotificationState struct {
+	path     string
+	reminder time.Duration
+	mutex    sync.Mutex
+
+	Containers map[string]notifiedContainer `json:"containers"`
+}
+
+// LoadNotificationState reads the notification state from path. A missing file results in an empty state.
+func LoadNotificationState(path string, reminder time.Duration) (*NotificationState, error) {
+	state := &NotificationState{
+		path:       path,
+		reminder:   reminder,
+		Containers: map[string]notifiedContainer{},
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read notification state: %w", err)
+	}
+
+	if err := json.Unmarshal(content, state); err != nil {
+		return nil, fmt.Errorf("failed to parse notification state: %w", err)
+	}
+	if state.Containers == nil {
+		state.Containers = map[string]notifiedContainer{}
+	}
+	return state, nil
+}
+
+// IsNew returns whether the container report contains anything that has not already been notified. Failures are
+// always considered new, while stale containers are only new when the latest image changes or a reminder is due.
+func (s *NotificationState) IsNew(report ty.ContainerReport, now time.Time) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	previous, found := s.Containers[report.Name()]
+	state := report.State()
+
+	switch state {
+	case "Failed":
+		return true
+	case "Fresh", "Scanned":
+		// Containers becoming fresh are only noteworthy when they were not updated by watchtower
+		return found && previous.State != "Fresh" && previous.State != "Scanned" && previous.State != "Updated"
+	}
+
+	if !found || previous.State != state || previous.LatestImage != string(report.LatestImageID()) ||
+		previous.LatestDigest != report.LatestDigest() {
+		return true
+	}
+
+	return s.reminder > 0 && now.Sub(previous.NotifiedAt) >= s.reminder
+}
+
+// NewSinceLastNotification returns a view of the report only containing the containers that are new according to IsNew
+func (s *NotificationState) NewSinceLastNotification(report ty.Report, now time.Time) ty.Report {
+	return session.FilterReport(report, func(cr ty.ContainerReport) bool {
+		return s.IsNew(cr, now)
+	})
+}
+
+// Record updates the state using the containers of the report, marking the notified containers as notified at now
+func (s *NotificationState) Record(report ty.Report, notified ty.Report, now time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	notifiedNames := map[string]bool{}
+	if notified != nil {
+		for _, cr := range notified.All() {
+			notifiedNames[cr.Name()] = true
+		}
+	}
+
+	for _, cr := range report.All() {
+		previous := s.Containers[cr.Name()]
+		current := notifiedContainer{
+			State:        cr.State(),
+			LatestImage:  string(cr.LatestImageID()),
+			LatestDigest: cr.LatestDigest(),
+			NotifiedAt:   previous.NotifiedAt,
+		}
+		if notifiedNames[cr.Name()] {
+			current.NotifiedAt = now
+		}
+		s.Containers[cr.Name()] = current
+	}
+}
+
+// Save writes the state to the state file
+func (s *NotificationState) Save() error {
+	s.mutex.Lock()
+	content, err := json.Marshal(s)
+	s.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first, to prevent leaving a partial state file behind
+	tmpPath := filepath.Join(filepath.Dir(s.path), "."+filepath.Base(s.path)+".tmp")
+	if err := os.WriteFile(tmpPath, content, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, s.path)
+}
+
+// entryDiscarder is implemented by notifiers that can drop the log entries queued up during a session
+type entryDiscarder interface {
+	discardEntries()
+}
+
+// deliveryReporter is implemented by notifiers that can report whether the notification of a report was delivered
+type deliveryReporter interface {
+	sendNotificationReporting(report ty.Report, delivered func(err error))
+}
+
+// sendReporting sends the report using the notifier, calling delivered once it has been sent. Notifiers that cannot
+// report their deliveries are assumed to have delivered the report once they have been handed it.
+func sendReporting(notifier ty.Notifier, report ty.Report, delivered func(err error)) {
+	if reporter, ok := notifier.(deliveryReporter); ok {
+		reporter.sendNotificationReporting(report, delivered)
+		return
+	}
+	notifier.SendNotification(report)
+	if delivered != nil {
+		delivered(nil)
+	}
+}
+
+// joinDeliveries returns the function reporting each of the count deliveries, which calls delivered with the first
+// error once all of them have been reported
+func joinDeliveries(count int, delivered func(err error)) func(err error) {
+	if delivered == nil {
+		return nil
+	}
+	if count == 0 {
+		delivered(nil)
+	}
+
+	var mutex sync.Mutex
+	var firstErr error
+	return func(err error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if count--; count == 0 {
+			delivered(firstErr)
+		}
+	}
+}
+
+// Implements Notifier, only passing on reports with containers that are new since the last notification
+type dedupNotifier struct {
+	ty.Notifier
+	state *NotificationState
+}
+
+// SendNotification sends the new since last notification view of the report. If nothing is new, the queued up log
+// entries are discarded instead of being sent.
+func (n *dedupNotifier) SendNotification(report ty.Report) {
+	if report == nil {
+		n.Notifier.SendNotification(report)
+		return
+	}
+
+	now := time.Now()
+	view := n.state.NewSinceLastNotification(report, now)
+	if len(view.All()) == 0 {
+		LocalLog.Debug("Skipping notification as nothing changed since the last notification")
+		if discarder, ok := n.Notifier.(entryDiscarder); ok {
+			discarder.discardEntries()
+		}
+		return
+	}
+
+	// The state is only recorded once the notification has been delivered, so that failed notifications are repeated
+	sendReporting(n.Notifier, view, func(err error) {
+		if err != nil {
+			LocalLog.WithError(err).Debug("Not recording the notification state, as the notification was not delivered")
+			return
+		}
+		n.state.Record(report, view, now)
+		if err := n.state.Save(); err != nil {
+			LocalLog.WithError(err).Warn("Failed to save the notification state")
+		}
+	})
+}
+
+func newDedupNotifier(notifier ty.Notifier, stateFile string, reminder time.Duration) *dedupNotifier {
+	state, err := LoadNotificationState(stateFile, reminder)
+	if err != nil {
+		log.Fatalf("Invalid notification state file: %v", err)
+	}
+	return &dedupNotifier{
+		Notifier: notifier,
+		state:    state,
+	}
+}
@@ -0,0 +1,164 @@
+package notifications
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/containrrr/watchtower/internal/actions/mocks"
+	s "github.com/containrrr/watchtower/pkg/session"
+	t "github.com/containrrr/watchtower/pkg/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+func mockStaleReport(newImage t.ImageID) t.Report {
+	progress := s.Progress{}
+	c, _ := mocks.CreateContainerForProgress(0, 61, "stal%d")
+	progress.AddScanned(c, newImage)
+	return progress.Report()
+}
+
+func mockCheckedReport(latestDigest string) t.Report {
+	progress := s.Progress{}
+	c, _ := mocks.CreateContainerForProgress(0, 61, "stal%d")
+	progress.AddScanned(c, c.SafeImageID())
+	progress.SetLatestDigest(c.ID(), latestDigest)
+	return progress.Report()
+}
+
+var _ = Describe("the notification state", func() {
+	var dir string
+	var statePath string
+	var state *NotificationState
+	now := time.Now()
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "watchtower-state")
+		Expect(err).NotTo(HaveOccurred())
+		statePath = filepath.Join(dir, "state.json")
+		state, err = LoadNotificationState(statePath, 0)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	notify := func(report t.Report, at time.Time) []string {
+		view := state.NewSinceLastNotification(report, at)
+		state.Record(report, view, at)
+		return routeNames(view)
+	}
+
+	When("a stale container was already notified", func() {
+		It("should not be included again until the latest image changes", func() {
+			Expect(notify(mockStaleReport("sha256:aaaa"), now)).To(ConsistOf("stal1"))
+			Expect(notify(mockStaleReport("sha256:aaaa"), now.Add(time.Hour))).To(BeEmpty())
+			Expect(notify(mockStaleReport("sha256:bbbb"), now.Add(2*time.Hour))).To(ConsistOf("stal1"))
+		})
+		It("should be included again when the registry reports a newer digest", func() {
+			Expect(notify(mockCheckedReport("sha256:aaaa"), now)).To(ConsistOf("stal1"))
+			Expect(notify(mockCheckedReport("sha256:aaaa"), now.Add(time.Hour))).To(BeEmpty())
+			Expect(notify(mockCheckedReport("sha256:bbbb"), now.Add(2*time.Hour))).To(ConsistOf("stal1"))
+		})
+		It("should be included again when the reminder interval has passed", func() {
+			state.reminder = 24 * time.Hour
+			Expect(notify(mockStaleReport("sha256:aaaa"), now)).To(ConsistOf("stal1"))
+			Expect(notify(mockStaleReport("sha256:aaaa"), now.Add(23*time.Hour))).To(BeEmpty())
+			Expect(notify(mockStaleReport("sha256:aaaa"), now.Add(25*time.Hour))).To(ConsistOf("stal1"))
+			Expect(notify(mockStaleReport("sha256:aaaa"), now.Add(26*time.Hour))).To(BeEmpty())
+		})
+	})
+
+	When("a container failed to update", func() {
+		It("should always be included", func() {
+			report := mocks.CreateMockProgressReport(s.FailedState)
+			Expect(notify(report, now)).To(ConsistOf("fail1"))
+			Expect(notify(report, now)).To(ConsistOf("fail1"))
+		})
+	})
+
+	When("the state is saved", func() {
+		It("should be restored when loaded", func() {
+			Expect(notify(mockStaleReport("sha256:aaaa"), now)).To(ConsistOf("stal1"))
+			Expect(state.Save()).To(Succeed())
+
+			loaded, err := LoadNotificationState(statePath, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(routeNames(loaded.NewSinceLastNotification(mockStaleReport("sha256:aaaa"), now))).To(BeEmpty())
+		})
+	})
+
+	When("nothing is new since the last notification", func() {
+		It("should discard the queued up log entries", func() {
+			shoutrrr := createNotifier([]string{}, logrus.InfoLevel, "", true, StaticData{}, false, 0)
+			notifier := &dedupNotifier{Notifier: shoutrrr, state: state}
+
+			notifier.StartNotification()
+			Expect(shoutrrr.Fire(&logrus.Entry{Message: "Found new image"})).To(Succeed())
+			notifier.SendNotification(mockStaleReport("sha256:aaaa"))
+
+			var message notificationMessage
+			Expect(shoutrrr.messages).To(Receive(&message))
+			Expect(message).To(ContainSubstring("Found new image"))
+			message.delivered(nil)
+
+			notifier.StartNotification()
+			Expect(shoutrrr.Fire(&logrus.Entry{Message: "Found new image"})).To(Succeed())
+			notifier.SendNotification(mockStaleReport("sha256:aaaa"))
+
+			Expect(shoutrrr.messages).NotTo(Receive())
+			Expect(shoutrrr.entries).To(BeNil())
+		})
+
+		It("should not pass on the empty report to notifiers that cannot discard the log entries", func() {
+			sender := &sendingNotifier{}
+			notifier := &dedupNotifier{Notifier: sender, state: state}
+
+			notifier.SendNotification(mockStaleReport("sha256:aaaa"))
+			notifier.SendNotification(mockStaleReport("sha256:aaaa"))
+			Expect(sender.sent).To(HaveLen(1))
+		})
+	})
+
+	When("the notification could not be delivered", func() {
+		It("should not record the state of the containers", func() {
+			sender := &reportingNotifier{err: errors.New("service unavailable")}
+			notifier := &dedupNotifier{Notifier: sender, state: state}
+
+			notifier.SendNotification(mockStaleReport("sha256:aaaa"))
+			Expect(statePath).NotTo(BeAnExistingFile())
+
+			sender.err = nil
+			notifier.SendNotification(mockStaleReport("sha256:aaaa"))
+			notifier.SendNotification(mockStaleReport("sha256:aaaa"))
+			Expect(sender.sent).To(HaveLen(2))
+			Expect(statePath).To(BeAnExistingFile())
+		})
+	})
+})
+
+// sendingNotifier records the reports that it was sent
+type sendingNotifier struct {
+	t.Notifier
+	sent []t.Report
+}
+
+func (n *sendingNotifier) SendNotification(report t.Report) {
+	n.sent = append(n.sent, report)
+}
+
+// reportingNotifier records the reports that it was sent, reporting their deliveries as failed with err
+type reportingNotifier struct {
+	sendingNotifier
+	err error
+}
+
+func (n *reportingNotifier) sendNotificationReporting(report t.Report, delivered func(err error)) {
+	n.SendNotification(report)
+	delivered(n.err)
+}
@@ -0,0 +1,142 @@
+package notifications
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// templateFile is a notification template read from a file, that is reloaded whenever the file changes
+type templateFile struct {
+	path    string
+	legacy  bool
+	mutex   sync.Mutex
+	modTime time.Time
+	size    int64
+	tpl     *template.Template
+}
+
+func newTemplateFile(path string, legacy bool) *templateFile {
+	f := &templateFile{path: path, legacy: legacy}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.reload(); err != nil {
+		LocalLog.WithError(err).WithField("file", path).Error("Could not use the notification template file. Using default template")
+	}
+	return f
+}
+
+// Template returns the current template, reloading it first if the file has changed. If the file can not be read or
+// parsed, the error is logged and the previous template is kept.
+func (f *templateFile) Template() *template.Template {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.reload(); err != nil {
+		// Log in go func in case we entered from Fire to avoid stalling
+		go LocalLog.WithError(err).WithField("file", f.path).Error("Failed to reload the notification template file")
+	}
+	return f.tpl
+}
+
+// reload reads and parses the file if it has been modified since it was last read, assuming that the mutex is held
+// by the caller
+func (f *templateFile) reload() error {
+	if f.tpl == nil {
+		// Make sure that there always is a template to fall back to
+		f.tpl, _ = getShoutrrrTemplate("", f.legacy)
+	}
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return err
+	}
+	if info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return nil
+	}
+	f.modTime = info.ModTime()
+	f.size = info.Size()
+
+	content, err := os.ReadFile(f.path)
+	if err != nil {
+		return err
+	}
+	tpl, err := getShoutrrrTemplate(string(content), f.legacy)
+	if err != nil {
+		return err
+	}
+
+	f.tpl = tpl
+	go LocalLog.WithField("file", f.path).Debug("Loaded notification template file")
+	return nil
+}
+
+// URLTemplate is a notification template used for the services matching the target, instead of the default template
+type URLTemplate struct {
+	// Target is either a full service URL or a service scheme, like "smtp"
+	Target string
+	// Template is either the name of a built-in template or the path to a template file
+	Template string
+}
+
+// Matches returns whether the template should be used for the service URL
+func (t URLTemplate) Matches(url string) bool {
+	if strings.Contains(t.Target, "://") {
+		return t.Target == url
+	}
+	return GetScheme(url) == t.Target
+}
+
+// GetURLTemplates reads the per service templates from the URL template flags
+func GetURLTemplates(c *cobra.Command) ([]URLTemplate, error) {
+	values, _ := c.Flags().GetStringArray("notification-url-template")
+
+	urlTemplates := make([]URLTemplate, 0, len(values))
+	for _, value := range values {
+		// URLs can contain '=' in their query, but the template names and paths are not expected to
+		sep := strings.LastIndex(value, "=")
+		if sep <= 0 || sep == len(value)-1 {
+			return nil, fmt.Errorf("notification URL template %q is not in the format \"url=template\"", value)
+		}
+		urlTemplates = append(urlTemplates, URLTemplate{Target: value[:sep], Template: value[sep+1:]})
+	}
+	return urlTemplates, nil
+}
+
+// templatedURLs are the service URLs that use the same URL template
+type templatedURLs struct {
+	template string
+	urls     []string
+}
+
+// groupURLsByTemplate returns the URLs that use the default template, and the URLs grouped by the URL template that
+// matches them. If multiple templates match a URL, the first one is used.
+func groupURLsByTemplate(urls []string, urlTemplates []URLTemplate) ([]string, []templatedURLs) {
+	var defaultURLs []string
+	var groups []templatedURLs
+
+urls:
+	for _, url := range urls {
+		for _, urlTemplate := range urlTemplates {
+			if !urlTemplate.Matches(url) {
+				continue
+			}
+			for i := range groups {
+				if groups[i].template == urlTemplate.Template {
+					groups[i].urls = append(groups[i].urls, url)
+					continue urls
+				}
+			}
+			groups = append(groups, templatedURLs{template: urlTemplate.Template, urls: []string{url}})
+			continue urls
+		}
+		defaultURLs = append(defaultURLs, url)
+	}
+	return defaultURLs, groups
+}
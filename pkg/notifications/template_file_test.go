@@ -0,0 +1,128 @@
+package notifications
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/containrrr/watchtower/internal/actions/mocks"
+	"github.com/containrrr/watchtower/internal/flags"
+	s "github.com/containrrr/watchtower/pkg/session"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var _ = Describe("notification template files", func() {
+	var dir string
+	var path string
+	report := mocks.CreateMockProgressReport(s.UpdatedState)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "watchtower-templates")
+		Expect(err).NotTo(HaveOccurred())
+		path = filepath.Join(dir, "notification.tmpl")
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	// writeTemplate writes the template file, making sure that the modification time changes between writes
+	writeTemplate := func(content string, age time.Duration) {
+		Expect(os.WriteFile(path, []byte(content), 0o644)).To(Succeed())
+		modTime := time.Now().Add(-age)
+		Expect(os.Chtimes(path, modTime, modTime)).To(Succeed())
+	}
+
+	render := func(f *templateFile) string {
+		var body bytes.Buffer
+		Expect(f.Template().Execute(&body, Data{Report: report})).To(Succeed())
+		return body.String()
+	}
+
+	When("the template file changes", func() {
+		It("should reload the template", func() {
+			writeTemplate(`{{len .Report.Updated}} updated`, time.Minute)
+			f := newTemplateFile(path, false)
+			Expect(render(f)).To(Equal("1 updated"))
+
+			writeTemplate(`{{len .Report.Scanned}} scanned`, 0)
+			Expect(render(f)).To(Equal("1 scanned"))
+		})
+		It("should keep the previous template if the new one is invalid", func() {
+			writeTemplate(`{{len .Report.Updated}} updated`, time.Minute)
+			f := newTemplateFile(path, false)
+
+			writeTemplate(`{{len .Report.Updated`, 0)
+			Expect(render(f)).To(Equal("1 updated"))
+		})
+	})
+
+	When("the template file does not exist", func() {
+		It("should use the default template until the file is created", func() {
+			f := newTemplateFile(path, false)
+			Expect(render(f)).To(HavePrefix("1 Scanned, 1 Updated, 0 Failed"))
+
+			writeTemplate(`{{len .Report.Updated}} updated`, 0)
+			Expect(render(f)).To(Equal("1 updated"))
+		})
+	})
+
+	When("the template file fails to render", func() {
+		It("should render the message using the default template", func() {
+			writeTemplate(`{{.Report.Missing}}`, 0)
+			shoutrrr := createNotifier([]string{"logger://"}, logrus.InfoLevel, "", false, StaticData{}, false, 0)
+			shoutrrr.templateFile = newTemplateFile(path, false)
+
+			message, err := shoutrrr.buildMessage(Data{Report: report})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(message).To(HavePrefix("1 Scanned, 1 Updated, 0 Failed"))
+		})
+	})
+})
+
+var _ = Describe("notification URL templates", func() {
+	parseURLTemplates := func(values ...string) ([]URLTemplate, error) {
+		cmd := new(cobra.Command)
+		flags.RegisterNotificationFlags(cmd)
+		for _, value := range values {
+			Expect(cmd.ParseFlags([]string{"--notification-url-template", value})).To(Succeed())
+		}
+		return GetURLTemplates(cmd)
+	}
+
+	It("should parse the URL or scheme and the template", func() {
+		urlTemplates, err := parseURLTemplates(
+			"pushover=/templates/short.tmpl",
+			"smtp://host:25/?fromaddress=a@example.com&toaddresses=b@example.com=porcelain.v1.summary-no-log",
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(urlTemplates).To(Equal([]URLTemplate{
+			{Target: "pushover", Template: "/templates/short.tmpl"},
+			{Target: "smtp://host:25/?fromaddress=a@example.com&toaddresses=b@example.com", Template: "porcelain.v1.summary-no-log"},
+		}))
+	})
+
+	It("should return an error for values without a template", func() {
+		_, err := parseURLTemplates("pushover=")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should group the URLs by the first matching template", func() {
+		urlTemplates := []URLTemplate{
+			{Target: "logger://second", Template: "detailed.tmpl"},
+			{Target: "logger", Template: "short.tmpl"},
+			{Target: "smtp", Template: "detailed.tmpl"},
+		}
+		defaultURLs, groups := groupURLsByTemplate([]string{"logger://first", "logger://second", "smtp://host", "teams://hook"}, urlTemplates)
+		Expect(defaultURLs).To(Equal([]string{"teams://hook"}))
+		Expect(groups).To(Equal([]templatedURLs{
+			{template: "short.tmpl", urls: []string{"logger://first"}},
+			{template: "detailed.tmpl", urls: []string{"logger://second", "smtp://host"}},
+		}))
+	})
+})
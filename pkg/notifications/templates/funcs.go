@@ -3,18 +3,49 @@ package templates
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"reflect"
+	"regexp"
 	"strings"
 	"text/template"
+	"time"
 
+	"github.com/containrrr/watchtower/pkg/types"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
 
-var Funcs = template.FuncMap{
-	"ToUpper": strings.ToUpper,
-	"ToLower": strings.ToLower,
-	"ToJSON":  toJSON,
-	"Title":   cases.Title(language.AmericanEnglish).String,
+// ComposeProjectLabel is the label that Docker Compose uses for the name of the project a container belongs to
+const ComposeProjectLabel = "com.docker.compose.project"
+
+// EnvPrefix is the prefix of the environment variables that the templates can read using Env
+const EnvPrefix = "WATCHTOWER_TEMPLATE_"
+
+// Funcs are the functions available in the notification templates
+var Funcs = NewFuncs(time.Now)
+
+// NewFuncs returns the template functions, using now to get the current time for the relative time formatting
+func NewFuncs(now func() time.Time) template.FuncMap {
+	return template.FuncMap{
+		"ToUpper":        strings.ToUpper,
+		"ToLower":        strings.ToLower,
+		"ToJSON":         toJSON,
+		"Title":          cases.Title(language.AmericanEnglish).String,
+		"FormatDuration": formatDuration,
+		"TimeAgo": func(t time.Time) string {
+			return timeAgo(t, now())
+		},
+		"ShortID":        shortID,
+		"ShortDigest":    shortDigest,
+		"GroupByState":   groupByState,
+		"GroupByImage":   groupByImage,
+		"GroupByProject": groupByProject,
+		"Count":          count,
+		"Truncate":       truncate,
+		"ReplaceRegex":   replaceRegex,
+		"Default":        defaultValue,
+		"Env":            templateEnv,
+	}
 }
 
 func toJSON(v interface{}) string {
@@ -25,3 +56,186 @@ func toJSON(v interface{}) string {
 	}
 	return string(bytes)
 }
+
+// formatDuration formats the duration using its two most significant units, like "1h 5m" or "4m 30s"
+func formatDuration(d time.Duration) string {
+	if d < 0 {
+		return "-" + formatDuration(-d)
+	}
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+
+	units := []struct {
+		suffix string
+		size   time.Duration
+	}{
+		{"d", 24 * time.Hour},
+		{"h", time.Hour},
+		{"m", time.Minute},
+		{"s", time.Second},
+	}
+
+	d = d.Round(time.Second)
+	parts := make([]string, 0, 2)
+	for _, unit := range units {
+		if d < unit.size && len(parts) == 0 {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%d%s", d/unit.size, unit.suffix))
+		d %= unit.size
+		if len(parts) == 2 || d == 0 {
+			break
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// timeAgo formats the time relative to now, like "5 minutes ago" or "in 2 hours"
+func timeAgo(t time.Time, now time.Time) string {
+	d := now.Sub(t)
+	format := "%s ago"
+	if d < 0 {
+		d = -d
+		format = "in %s"
+	}
+
+	var amount string
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		amount = count("minute", int(d/time.Minute))
+	case d < 24*time.Hour:
+		amount = count("hour", int(d/time.Hour))
+	default:
+		amount = count("day", int(d/(24*time.Hour)))
+	}
+	return fmt.Sprintf(format, amount)
+}
+
+// shortID returns the 12-character short version of an ID, removing any "sha256:" prefix
+func shortID(id interface{}) string {
+	return types.ImageID(fmt.Sprint(id)).ShortID()
+}
+
+// shortDigest shortens the hash of a digest, or of an image reference with a digest, to 12 characters while keeping the
+// algorithm, like "sha256:0123456789ab"
+func shortDigest(digest interface{}) string {
+	s := fmt.Sprint(digest)
+	sep := strings.LastIndex(s, ":")
+	if sep < 0 || len(s)-sep-1 <= 12 {
+		return s
+	}
+	return s[:sep+13]
+}
+
+// Group is a set of containers sharing the same key, like a state, an image or a Compose project
+type Group struct {
+	Key        string
+	Containers []types.ContainerReport
+}
+
+// groupBy groups the containers by the key, keeping the order in which the keys first appear
+func groupBy(containers []types.ContainerReport, key func(types.ContainerReport) string) []Group {
+	groups := make([]Group, 0)
+	indices := make(map[string]int)
+	for _, c := range containers {
+		k := key(c)
+		index, found := indices[k]
+		if !found {
+			index = len(groups)
+			indices[k] = index
+			groups = append(groups, Group{Key: k})
+		}
+		groups[index].Containers = append(groups[index].Containers, c)
+	}
+	return groups
+}
+
+func groupByState(containers []types.ContainerReport) []Group {
+	return groupBy(containers, func(c types.ContainerReport) string {
+		return c.State()
+	})
+}
+
+func groupByImage(containers []types.ContainerReport) []Group {
+	return groupBy(containers, func(c types.ContainerReport) string {
+		return c.ImageName()
+	})
+}
+
+// groupByProject groups the containers by their Compose project, using an empty key for the other containers
+func groupByProject(containers []types.ContainerReport) []Group {
+	return groupBy(containers, func(c types.ContainerReport) string {
+		return c.Labels()[ComposeProjectLabel]
+	})
+}
+
+// count returns the number, or the length of a list, followed by the noun, pluralized unless the number is one
+func count(noun string, v interface{}) string {
+	n := 0
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = int(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n = int(value.Uint())
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.String:
+		n = value.Len()
+	}
+
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// truncate shortens the string to at most length characters, replacing the end with an ellipsis if it is too long
+func truncate(length int, s string) string {
+	runes := []rune(s)
+	if len(runes) <= length {
+		return s
+	}
+	if length < 1 {
+		return ""
+	}
+	return string(runes[:length-1]) + "…"
+}
+
+// replaceRegex replaces all the matches of the regular expression in the string, expanding any $ references
+func replaceRegex(pattern string, replacement string, s string) (string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", err
+	}
+	return re.ReplaceAllString(s, replacement), nil
+}
+
+// templateEnv returns the value of the environment variable, if its name has the EnvPrefix. Other variables are not
+// readable, as they may contain credentials, such as the API token or the notification URLs.
+func templateEnv(name string) string {
+	if !strings.HasPrefix(name, EnvPrefix) {
+		return ""
+	}
+	return os.Getenv(name)
+}
+
+// defaultValue returns the value, or the fallback if the value is empty
+func defaultValue(fallback interface{}, value interface{}) interface{} {
+	if value == nil {
+		return fallback
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.String:
+		if v.Len() == 0 {
+			return fallback
+		}
+	default:
+		if v.IsZero() {
+			return fallback
+		}
+	}
+	return value
+}
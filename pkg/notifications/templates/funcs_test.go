@@ -0,0 +1,71 @@
+package templates
+
+import (
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("the template functions", func() {
+	now := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+
+	It("should format durations using the two most significant units", func() {
+		Expect(formatDuration(350 * time.Millisecond)).To(Equal("350ms"))
+		Expect(formatDuration(45 * time.Second)).To(Equal("45s"))
+		Expect(formatDuration(4*time.Minute + 30*time.Second)).To(Equal("4m 30s"))
+		Expect(formatDuration(2 * time.Hour)).To(Equal("2h"))
+		Expect(formatDuration(50*time.Hour + 5*time.Minute)).To(Equal("2d 2h"))
+		Expect(formatDuration(-90 * time.Second)).To(Equal("-1m 30s"))
+	})
+
+	It("should format times relative to the current time", func() {
+		Expect(timeAgo(now.Add(-20*time.Second), now)).To(Equal("just now"))
+		Expect(timeAgo(now.Add(-time.Minute), now)).To(Equal("1 minute ago"))
+		Expect(timeAgo(now.Add(-5*time.Hour), now)).To(Equal("5 hours ago"))
+		Expect(timeAgo(now.Add(-72*time.Hour), now)).To(Equal("3 days ago"))
+		Expect(timeAgo(now.Add(2*time.Hour), now)).To(Equal("in 2 hours"))
+	})
+
+	It("should shorten IDs and digests", func() {
+		Expect(shortID("sha256:0123456789abcdef0123")).To(Equal("0123456789ab"))
+		Expect(shortDigest("sha256:0123456789abcdef0123")).To(Equal("sha256:0123456789ab"))
+		Expect(shortDigest("latest")).To(Equal("latest"))
+	})
+
+	It("should truncate strings to the given number of characters", func() {
+		Expect(truncate(5, "watchtower")).To(Equal("watc…"))
+		Expect(truncate(10, "watchtower")).To(Equal("watchtower"))
+		Expect(truncate(0, "watchtower")).To(Equal(""))
+	})
+
+	It("should return an error for invalid regular expressions", func() {
+		_, err := replaceRegex("(", "", "watchtower")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should only read the environment variables meant for the templates", func() {
+		Expect(os.Setenv("WATCHTOWER_TEMPLATE_SITE", "eu-west")).To(Succeed())
+		Expect(os.Setenv("WATCHTOWER_HTTP_API_TOKEN", "s3cr3t")).To(Succeed())
+		defer os.Unsetenv("WATCHTOWER_TEMPLATE_SITE")
+		defer os.Unsetenv("WATCHTOWER_HTTP_API_TOKEN")
+
+		Expect(templateEnv("WATCHTOWER_TEMPLATE_SITE")).To(Equal("eu-west"))
+		Expect(templateEnv("WATCHTOWER_HTTP_API_TOKEN")).To(BeEmpty())
+	})
+
+	It("should use the fallback for empty values only", func() {
+		Expect(defaultValue("none", "")).To(Equal("none"))
+		Expect(defaultValue("none", nil)).To(Equal("none"))
+		Expect(defaultValue("none", []string{})).To(Equal("none"))
+		Expect(defaultValue("none", "value")).To(Equal("value"))
+		Expect(defaultValue(1, 0)).To(Equal(1))
+	})
+
+	It("should pluralize counts", func() {
+		Expect(count("container", 1)).To(Equal("1 container"))
+		Expect(count("container", []int{1, 2})).To(Equal("2 containers"))
+		Expect(count("container", 0)).To(Equal("0 containers"))
+	})
+})
@@ -0,0 +1,259 @@
+package notifications
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/containrrr/watchtower/internal/meta"
+	t "github.com/containrrr/watchtower/pkg/types"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+const (
+	webhookType = "webhook"
+
+	// WebhookSignatureHeader is the header containing the HMAC-SHA256 signature of the webhook payload
+	WebhookSignatureHeader = "X-Watchtower-Signature"
+	// WebhookTimestampHeader is the header containing the unix timestamp that was used for signing the payload
+	WebhookTimestampHeader = "X-Watchtower-Timestamp"
+
+	defaultWebhookBackoff = time.Second
+)
+
+// Implements Notifier, logrus.Hook
+type webhookNotifier struct {
+	URL       string
+	secret    string
+	headers   http.Header
+	retries   int
+	backoff   time.Duration
+	client    *http.Client
+	entries   []*log.Entry
+	logLevel  log.Level
+	data      StaticData
+	payloads  chan webhookPayload
+	done      chan bool
+	receiving bool
+}
+
+// webhookPayload is a notification to post, with an optional function that is called once it has been posted
+type webhookPayload struct {
+	body      []byte
+	delivered func(err error)
+}
+
+func newWebhookNotifier(c *cobra.Command, level log.Level, data StaticData) *webhookNotifier {
+	f := c.Flags()
+
+	url, _ := f.GetString("notification-webhook-url")
+	secret, _ := f.GetString("notification-webhook-secret")
+	retries, _ := f.GetInt("notification-webhook-retries")
+	headerValues, _ := f.GetStringArray("notification-webhook-header")
+
+	headers, err := parseWebhookHeaders(headerValues)
+	if err != nil {
+		log.Fatalf("Invalid webhook notification header: %v", err)
+	}
+
+	return createWebhookNotifier(url, secret, headers, retries, level, data)
+}
+
+func createWebhookNotifier(url string, secret string, headers http.Header, retries int, level log.Level, data StaticData) *webhookNotifier {
+	if retries < 0 {
+		retries = 0
+	}
+	return &webhookNotifier{
+		URL:      url,
+		secret:   secret,
+		headers:  headers,
+		retries:  retries,
+		backoff:  defaultWebhookBackoff,
+		client:   &http.Client{Timeout: 30 * time.Second},
+		logLevel: level,
+		data:     data,
+		payloads: make(chan webhookPayload, 10),
+		done:     make(chan bool),
+	}
+}
+
+// parseWebhookHeaders parses headers in the format "Name: Value"
+func parseWebhookHeaders(values []string) (http.Header, error) {
+	headers := http.Header{}
+	for _, value := range values {
+		name, content, found := strings.Cut(value, ":")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			return nil, fmt.Errorf("%q is not in the format \"Name: Value\"", value)
+		}
+		headers.Add(name, strings.TrimSpace(content))
+	}
+	return headers, nil
+}
+
+// SignWebhookPayload returns the hex encoded HMAC-SHA256 signature of the timestamp and payload, using secret as the key
+func SignWebhookPayload(secret string, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// GetNames returns the name of the webhook notification service
+func (n *webhookNotifier) GetNames() []string {
+	return []string{webhookType}
+}
+
+// GetURLs returns the URL that the webhook notifications are posted to
+func (n *webhookNotifier) GetURLs() []string {
+	return []string{n.URL}
+}
+
+// AddLogHook adds the notifier as a receiver of log messages and starts a go func for posting them
+func (n *webhookNotifier) AddLogHook() {
+	if n.receiving {
+		return
+	}
+	n.receiving = true
+	log.AddHook(n)
+
+	go sendWebhooks(n)
+}
+
+func sendWebhooks(n *webhookNotifier) {
+	for payload := range n.payloads {
+		err := n.post(payload.body)
+		if err != nil {
+			LocalLog.WithField("service", webhookType).WithError(err).Error("Failed to send webhook notification")
+		}
+		if payload.delivered != nil {
+			payload.delivered(err)
+		}
+	}
+
+	n.done <- true
+}
+
+// post sends the payload to the webhook URL, retrying with an exponential backoff on failures
+func (n *webhookNotifier) post(payload []byte) error {
+	var err error
+	backoff := n.backoff
+	for attempt := 0; attempt <= n.retries; attempt++ {
+		if attempt > 0 {
+			LocalLog.WithField("service", webhookType).WithError(err).Debugf("Retrying webhook notification in %v", backoff)
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		var retry bool
+		if retry, err = n.tryPost(payload); err == nil || !retry {
+			return err
+		}
+	}
+	return err
+}
+
+// tryPost does a single attempt at posting the payload, returning whether a failed attempt should be retried
+func (n *webhookNotifier) tryPost(payload []byte) (retry bool, err error) {
+	req, err := http.NewRequest(http.MethodPost, n.URL, bytes.NewReader(payload))
+	if err != nil {
+		return false, err
+	}
+
+	for name, values := range n.headers {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", meta.UserAgent)
+
+	if n.secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(WebhookTimestampHeader, timestamp)
+		req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhookPayload(n.secret, timestamp, payload))
+	}
+
+	res, err := n.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return false, nil
+	}
+
+	retry = res.StatusCode >= 500 || res.StatusCode == http.StatusTooManyRequests || res.StatusCode == http.StatusRequestTimeout
+	return retry, fmt.Errorf("webhook responded with %q", res.Status)
+}
+
+// sendEntries queues up the payload for posting, calling delivered once it has been posted, if it is not nil
+func (n *webhookNotifier) sendEntries(entries []*log.Entry, report t.Report, delivered func(err error)) {
+	payload, err := Data{n.data, entries, report}.MarshalJSON()
+	if err != nil {
+		// Log in go func in case we entered from Fire to avoid stalling
+		go LocalLog.WithError(err).Error("Failed to create webhook notification payload")
+		if delivered != nil {
+			delivered(err)
+		}
+		return
+	}
+	n.payloads <- webhookPayload{body: payload, delivered: delivered}
+}
+
+// StartNotification begins queueing up messages to send them as a batch
+func (n *webhookNotifier) StartNotification() {
+	if n.entries == nil {
+		n.entries = make([]*log.Entry, 0, 10)
+	}
+}
+
+// SendNotification posts the session report and the queued up messages to the webhook
+func (n *webhookNotifier) SendNotification(report t.Report) {
+	n.sendNotificationReporting(report, nil)
+}
+
+func (n *webhookNotifier) sendNotificationReporting(report t.Report, delivered func(err error)) {
+	n.sendEntries(n.entries, report, delivered)
+	n.entries = nil
+}
+
+func (n *webhookNotifier) discardEntries() {
+	n.entries = nil
+}
+
+// Close prevents further messages from being queued and waits until all the currently queued up messages have been sent
+func (n *webhookNotifier) Close() {
+	close(n.payloads)
+
+	LocalLog.Info("Waiting for the webhook notifications to be sent")
+
+	<-n.done
+}
+
+// Levels return what log levels trigger notifications
+func (n *webhookNotifier) Levels() []log.Level {
+	return log.AllLevels[:n.logLevel+1]
+}
+
+// Fire is the hook that logrus calls on a new log message
+func (n *webhookNotifier) Fire(entry *log.Entry) error {
+	if entry.Data["notify"] == "no" {
+		// Skip logging if explicitly tagged as non-notify
+		return nil
+	}
+	if n.entries != nil {
+		n.entries = append(n.entries, entry)
+	} else {
+		// Log output generated outside a cycle is sent immediately.
+		n.sendEntries([]*log.Entry{entry}, nil, nil)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package notifications
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/containrrr/watchtower/internal/actions/mocks"
+	s "github.com/containrrr/watchtower/pkg/session"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("the webhook notifier", func() {
+	var server *ghttp.Server
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	sendReport := func(n *webhookNotifier) {
+		go sendWebhooks(n)
+		n.StartNotification()
+		n.SendNotification(mocks.CreateMockProgressReport(s.UpdatedState, s.FailedState))
+		n.Close()
+	}
+
+	verifySignature := func(secret string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			Expect(err).NotTo(HaveOccurred())
+			timestamp := r.Header.Get(WebhookTimestampHeader)
+			Expect(timestamp).NotTo(BeEmpty())
+			Expect(r.Header.Get(WebhookSignatureHeader)).To(Equal("sha256=" + SignWebhookPayload(secret, timestamp, body)))
+			Expect(body).To(ContainSubstring(`"report":`))
+		}
+	}
+
+	When("posting a session report", func() {
+		It("should sign the JSON payload and add the custom headers", func() {
+			headers, err := parseWebhookHeaders([]string{"X-Custom: custom value", "Authorization: Bearer token"})
+			Expect(err).NotTo(HaveOccurred())
+			n := createWebhookNotifier(server.URL()+"/hook", "s3cr3t", headers, 0, logrus.InfoLevel, StaticData{Host: "Mock"})
+
+			server.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyRequest("POST", "/hook"),
+				ghttp.VerifyContentType("application/json"),
+				ghttp.VerifyHeaderKV("X-Custom", "custom value"),
+				ghttp.VerifyHeaderKV("Authorization", "Bearer token"),
+				verifySignature("s3cr3t"),
+			))
+
+			sendReport(n)
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+		})
+	})
+
+	When("the webhook responds with a server error", func() {
+		It("should retry sending the notification", func() {
+			n := createWebhookNotifier(server.URL(), "s3cr3t", http.Header{}, 2, logrus.InfoLevel, StaticData{})
+			n.backoff = time.Millisecond
+
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusBadGateway, nil),
+				ghttp.CombineHandlers(verifySignature("s3cr3t"), ghttp.RespondWith(http.StatusNoContent, nil)),
+			)
+
+			sendReport(n)
+			Expect(server.ReceivedRequests()).To(HaveLen(2))
+		})
+	})
+
+	When("the webhook responds with a client error", func() {
+		It("should not retry sending the notification", func() {
+			n := createWebhookNotifier(server.URL(), "", http.Header{}, 3, logrus.InfoLevel, StaticData{})
+			n.backoff = time.Millisecond
+
+			server.AppendHandlers(ghttp.RespondWith(http.StatusUnauthorized, nil))
+
+			sendReport(n)
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+		})
+	})
+
+	When("parsing custom headers", func() {
+		It("should return an error for headers without a name", func() {
+			_, err := parseWebhookHeaders([]string{"no separator"})
+			Expect(err).To(HaveOccurred())
+			_, err = parseWebhookHeaders([]string{": value"})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,142 @@
+// Package pause keeps track of whether the scheduled updates have been paused, persisting the state in a file so that
+// it is kept across restarts, and can be changed by other watchtower processes using the same file.
+package pause
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// State describes whether the scheduled updates are paused, and why
+type State struct {
+	Paused bool      `json:"paused"`
+	Reason string    `json:"reason,omitempty"`
+	Since  time.Time `json:"since,omitempty"`
+	// Until is when the pause expires, or the zero time if it lasts until it is resumed
+	Until time.Time `json:"until,omitempty"`
+}
+
+// Active returns whether the state pauses the scheduled updates at the given time
+func (s State) Active(now time.Time) bool {
+	return s.Paused && (s.Until.IsZero() || now.Before(s.Until))
+}
+
+// MarshalJSON implements json.Marshaler, leaving out the zero times
+func (s State) MarshalJSON() ([]byte, error) {
+	data := map[string]interface{}{
+		`paused`: s.Paused,
+	}
+	if s.Reason != "" {
+		data[`reason`] = s.Reason
+	}
+	if !s.Since.IsZero() {
+		data[`since`] = s.Since
+	}
+	if !s.Until.IsZero() {
+		data[`until`] = s.Until
+	}
+	return json.Marshal(data)
+}
+
+// Store holds the pause state, persisting it in a file if a path is given
+type Store struct {
+	path  string
+	now   func() time.Time
+	mutex sync.Mutex
+	state State
+}
+
+// NewStore creates a new Store, persisting the state in the file at path, or only in memory if path is empty
+func NewStore(path string) *Store {
+	return &Store{path: path, now: time.Now}
+}
+
+// Status returns the current state, where expired pauses are reported as not paused
+func (store *Store) Status() (State, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	state, err := store.load()
+	if !state.Active(store.now()) {
+		state = State{}
+	}
+	return state, err
+}
+
+// Pause pauses the scheduled updates until they are resumed, or until the given time if it is not zero
+func (store *Store) Pause(reason string, until time.Time) (State, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	now := store.now()
+	if !until.IsZero() && !until.After(now) {
+		return State{}, errors.New("the pause expiry must be in the future")
+	}
+
+	state := State{
+		Paused: true,
+		Reason: reason,
+		Since:  now,
+		Until:  until,
+	}
+	return state, store.save(state)
+}
+
+// Resume resumes the scheduled updates
+func (store *Store) Resume() (State, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	return State{}, store.save(State{})
+}
+
+// load reads the state from the file, which is the source of truth when persisting, as it may be changed by others
+func (store *Store) load() (State, error) {
+	if store.path == "" {
+		return store.state, nil
+	}
+
+	content, err := os.ReadFile(store.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return State{}, nil
+	}
+	if err != nil {
+		return store.state, fmt.Errorf("failed to read the pause state: %w", err)
+	}
+
+	var state State
+	if err := json.Unmarshal(content, &state); err != nil {
+		return store.state, fmt.Errorf("failed to parse the pause state: %w", err)
+	}
+	store.state = state
+	return state, nil
+}
+
+// save stores the state, and writes it to the file, replacing it atomically
+func (store *Store) save(state State) error {
+	store.state = state
+	if store.path == "" {
+		return nil
+	}
+
+	content, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(store.path), 0o755); err != nil {
+		return fmt.Errorf("failed to save the pause state: %w", err)
+	}
+	temp := store.path + ".tmp"
+	if err := os.WriteFile(temp, content, 0o644); err != nil {
+		return fmt.Errorf("failed to save the pause state: %w", err)
+	}
+	if err := os.Rename(temp, store.path); err != nil {
+		return fmt.Errorf("failed to save the pause state: %w", err)
+	}
+	return nil
+}
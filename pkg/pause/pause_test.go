@@ -0,0 +1,100 @@
+package pause_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/containrrr/watchtower/pkg/pause"
+)
+
+func TestPause(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Pause Suite")
+}
+
+var _ = Describe("the pause store", func() {
+	var dir string
+	var path string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "watchtower-pause")
+		Expect(err).NotTo(HaveOccurred())
+		path = filepath.Join(dir, "state", "scheduler.json")
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("should not be paused initially", func() {
+		state, err := pause.NewStore(path).Status()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state.Paused).To(BeFalse())
+	})
+
+	It("should keep the pause across restarts", func() {
+		_, err := pause.NewStore(path).Pause("release freeze", time.Time{})
+		Expect(err).NotTo(HaveOccurred())
+
+		state, err := pause.NewStore(path).Status()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state.Paused).To(BeTrue())
+		Expect(state.Reason).To(Equal("release freeze"))
+		Expect(state.Since).NotTo(BeZero())
+	})
+
+	It("should pick up changes made by other processes", func() {
+		store := pause.NewStore(path)
+		_, err := store.Pause("incident", time.Time{})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = pause.NewStore(path).Resume()
+		Expect(err).NotTo(HaveOccurred())
+
+		state, err := store.Status()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state.Paused).To(BeFalse())
+	})
+
+	It("should resume once the pause has expired", func() {
+		store := pause.NewStore(path)
+		_, err := store.Pause("", time.Now().Add(50*time.Millisecond))
+		Expect(err).NotTo(HaveOccurred())
+
+		state, err := store.Status()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state.Paused).To(BeTrue())
+
+		Eventually(func() bool {
+			state, _ := store.Status()
+			return state.Paused
+		}).Should(BeFalse())
+	})
+
+	It("should reject expiry times in the past", func() {
+		_, err := pause.NewStore(path).Pause("", time.Now().Add(-time.Minute))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return an error if the state cannot be parsed", func() {
+		Expect(os.MkdirAll(filepath.Dir(path), 0o755)).To(Succeed())
+		Expect(os.WriteFile(path, []byte("{"), 0o644)).To(Succeed())
+		_, err := pause.NewStore(path).Status()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should only keep the state in memory without a path", func() {
+		store := pause.NewStore("")
+		_, err := store.Pause("", time.Time{})
+		Expect(err).NotTo(HaveOccurred())
+		state, err := store.Status()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state.Paused).To(BeTrue())
+	})
+})
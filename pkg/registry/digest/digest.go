@@ -27,23 +27,11 @@ func CompareDigest(container types.Container, registryAuth string) (bool, error)
 		return false, errors.New("container image info missing")
 	}
 
-	var digest string
-
-	registryAuth = TransformAuth(registryAuth)
-	token, err := auth.GetToken(container, registryAuth)
-	if err != nil {
-		return false, err
-	}
-
-	digestURL, err := manifest.BuildManifestURL(container)
+	digest, err := GetRemoteDigest(container, registryAuth)
 	if err != nil {
 		return false, err
 	}
 
-	if digest, err = GetDigest(digestURL, token); err != nil {
-		return false, err
-	}
-
 	logrus.WithField("remote", digest).Debug("Found a remote digest to compare with")
 
 	for _, dig := range container.ImageInfo().RepoDigests {
@@ -60,6 +48,22 @@ func CompareDigest(container types.Container, registryAuth string) (bool, error)
 	return false, nil
 }
 
+// GetRemoteDigest returns the digest of the image that the container references, as reported by the registry
+func GetRemoteDigest(container types.Container, registryAuth string) (string, error) {
+	registryAuth = TransformAuth(registryAuth)
+	token, err := auth.GetToken(container, registryAuth)
+	if err != nil {
+		return "", err
+	}
+
+	digestURL, err := manifest.BuildManifestURL(container)
+	if err != nil {
+		return "", err
+	}
+
+	return GetDigest(digestURL, token)
+}
+
 // TransformAuth from a base64 encoded json object to base64 encoded string
 func TransformAuth(registryAuth string) string {
 	b, _ := base64.StdEncoding.DecodeString(registryAuth)
@@ -74,8 +78,11 @@ func TransformAuth(registryAuth string) string {
 	return registryAuth
 }
 
-// GetDigest from registry using a HEAD request to prevent rate limiting
-func GetDigest(url string, token string) (string, error) {
+// requestTimeout is the time limit for the requests to the registries, including reading the response
+const requestTimeout = 30 * time.Second
+
+// NewClient returns an HTTP client for the requests to the registries, using the proxy of the environment
+func NewClient() *http.Client {
 	tr := &http.Transport{
 		Proxy: http.ProxyFromEnvironment,
 		DialContext: (&net.Dialer{
@@ -89,7 +96,12 @@ func GetDigest(url string, token string) (string, error) {
 		ExpectContinueTimeout: 1 * time.Second,
 		TLSClientConfig:       &tls.Config{InsecureSkipVerify: true},
 	}
-	client := &http.Client{Transport: tr}
+	return &http.Client{Transport: tr, Timeout: requestTimeout}
+}
+
+// GetDigest from registry using a HEAD request to prevent rate limiting
+func GetDigest(url string, token string) (string, error) {
+	client := NewClient()
 
 	req, _ := http.NewRequest("HEAD", url, nil)
 	req.Header.Set("User-Agent", meta.UserAgent)
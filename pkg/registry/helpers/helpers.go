@@ -1,6 +1,9 @@
 package helpers
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/distribution/reference"
 )
 
@@ -26,3 +29,58 @@ func GetRegistryAddress(imageRef string) (string, error) {
 	}
 	return address, nil
 }
+
+// ParseRegistryMirrors parses a list of `registry=mirror` pairs
+// and returns a map of registry addresses to the address of their mirror
+func ParseRegistryMirrors(pairs []string) (map[string]string, error) {
+	mirrors := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		if pair == "" {
+			continue
+		}
+		registry, mirror, found := strings.Cut(pair, "=")
+		registry, mirror = strings.TrimSpace(registry), strings.TrimSpace(mirror)
+		if !found || registry == "" || mirror == "" {
+			return nil, fmt.Errorf("invalid registry mirror %q, expected format is registry=mirror", pair)
+		}
+		if registry == DefaultRegistryDomain {
+			registry = DefaultRegistryHost
+		}
+		mirrors[registry] = strings.TrimSuffix(mirror, "/")
+	}
+	return mirrors, nil
+}
+
+// GetMirroredImageName returns the image reference rewritten to point at the mirror configured
+// for its registry, and whether a mirror was found
+func GetMirroredImageName(imageRef string, mirrors map[string]string) (string, bool) {
+	if len(mirrors) == 0 {
+		return imageRef, false
+	}
+
+	normalizedRef, err := reference.ParseNormalizedNamed(imageRef)
+	if err != nil {
+		return imageRef, false
+	}
+
+	address, _ := GetRegistryAddress(normalizedRef.Name())
+	mirror, found := mirrors[address]
+	if !found {
+		return imageRef, false
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString(mirror)
+	sb.WriteRune('/')
+	sb.WriteString(reference.Path(normalizedRef))
+	if tagged, isTagged := normalizedRef.(reference.Tagged); isTagged {
+		sb.WriteRune(':')
+		sb.WriteString(tagged.Tag())
+	}
+	if digested, isDigested := normalizedRef.(reference.Digested); isDigested {
+		sb.WriteRune('@')
+		sb.WriteString(digested.Digest().String())
+	}
+
+	return sb.String(), true
+}
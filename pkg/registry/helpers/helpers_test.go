@@ -34,4 +34,50 @@ var _ = Describe("the helpers", func() {
 			Expect(GetRegistryAddress("github.com/containrrr/config")).To(Equal("github.com"))
 		})
 	})
+	Describe("ParseRegistryMirrors", func() {
+		It("should map the registry address to the mirror address", func() {
+			Expect(ParseRegistryMirrors([]string{"ghcr.io=mirror.local:5000/"})).To(Equal(map[string]string{
+				"ghcr.io": "mirror.local:5000",
+			}))
+		})
+		It("should normalize docker.io to the default registry host", func() {
+			Expect(ParseRegistryMirrors([]string{"docker.io=mirror.local:5000"})).To(Equal(map[string]string{
+				"index.docker.io": "mirror.local:5000",
+			}))
+		})
+		It("should ignore empty values", func() {
+			Expect(ParseRegistryMirrors([]string{""})).To(BeEmpty())
+		})
+		It("should return an error for values without a mirror", func() {
+			_, err := ParseRegistryMirrors([]string{"docker.io"})
+			Expect(err).To(HaveOccurred())
+			_, err = ParseRegistryMirrors([]string{"docker.io="})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+	Describe("GetMirroredImageName", func() {
+		mirrors := map[string]string{
+			"index.docker.io": "mirror.local:5000",
+			"ghcr.io":         "ghcr-mirror.local",
+		}
+		It("should rewrite images from Docker Hub to the mirror", func() {
+			name, found := GetMirroredImageName("nginx:1.25", mirrors)
+			Expect(found).To(BeTrue())
+			Expect(name).To(Equal("mirror.local:5000/library/nginx:1.25"))
+
+			name, found = GetMirroredImageName("docker.io/containrrr/watchtower:latest", mirrors)
+			Expect(found).To(BeTrue())
+			Expect(name).To(Equal("mirror.local:5000/containrrr/watchtower:latest"))
+		})
+		It("should rewrite images from other registries to their mirror", func() {
+			name, found := GetMirroredImageName("ghcr.io/containrrr/watchtower:latest", mirrors)
+			Expect(found).To(BeTrue())
+			Expect(name).To(Equal("ghcr-mirror.local/containrrr/watchtower:latest"))
+		})
+		It("should keep the image ref when no mirror is configured for the registry", func() {
+			name, found := GetMirroredImageName("quay.io/containrrr/watchtower:latest", mirrors)
+			Expect(found).To(BeFalse())
+			Expect(name).To(Equal("quay.io/containrrr/watchtower:latest"))
+		})
+	})
 })
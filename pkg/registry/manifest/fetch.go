@@ -0,0 +1,122 @@
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/containrrr/watchtower/internal/meta"
+	"github.com/sirupsen/logrus"
+)
+
+// Media types used when fetching manifests from the registry
+const (
+	OCIManifestMediaType    = "application/vnd.oci.image.manifest.v1+json"
+	OCIIndexMediaType       = "application/vnd.oci.image.index.v1+json"
+	DockerManifestMediaType = "application/vnd.docker.distribution.manifest.v2+json"
+	DockerListMediaType     = "application/vnd.docker.distribution.manifest.list.v2+json"
+)
+
+// maxFetchSize is the maximum number of bytes read from a manifest or blob response
+const maxFetchSize = 4 << 20
+
+// ErrNotFound is returned when the registry responds that the requested manifest or blob does not exist
+var ErrNotFound = errors.New("not found in registry")
+
+// Platform describes the platform an image manifest in an index is built for
+type Platform struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+// Descriptor references content in the registry
+type Descriptor struct {
+	MediaType    string            `json:"mediaType"`
+	ArtifactType string            `json:"artifactType,omitempty"`
+	Digest       string            `json:"digest"`
+	Size         int64             `json:"size"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	Platform     *Platform         `json:"platform,omitempty"`
+}
+
+// Manifest is an image manifest or an image index, as returned by the registry
+type Manifest struct {
+	MediaType string       `json:"mediaType"`
+	Config    Descriptor   `json:"config"`
+	Layers    []Descriptor `json:"layers"`
+	Manifests []Descriptor `json:"manifests"`
+}
+
+// IsIndex returns whether the manifest is an index referencing other manifests
+func (m *Manifest) IsIndex() bool {
+	return m.MediaType == OCIIndexMediaType || m.MediaType == DockerListMediaType || len(m.Manifests) > 0
+}
+
+// GetManifest fetches and parses the manifest at url
+func GetManifest(client *http.Client, url string, token string) (*Manifest, error) {
+	body, err := Fetch(client, url, token, OCIManifestMediaType, DockerManifestMediaType, OCIIndexMediaType, DockerListMediaType)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Manifest{}
+	if err := json.Unmarshal(body, result); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	return result, nil
+}
+
+// GetBlob fetches the blob with the supplied digest from the repository at repoURL, and verifies its content
+func GetBlob(client *http.Client, repoURL string, blobDigest string, token string) ([]byte, error) {
+	body, err := Fetch(client, repoURL+"/blobs/"+blobDigest, token)
+	if err != nil {
+		return nil, err
+	}
+
+	algorithm, expected, _ := strings.Cut(blobDigest, ":")
+	if algorithm == "sha256" {
+		actual := sha256.Sum256(body)
+		if hex.EncodeToString(actual[:]) != expected {
+			return nil, fmt.Errorf("blob content does not match digest %q", blobDigest)
+		}
+	}
+	return body, nil
+}
+
+// Fetch does an authenticated GET request against the registry, returning the response body
+func Fetch(client *http.Client, url string, token string, accept ...string) ([]byte, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", meta.UserAgent)
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	for _, mediaType := range accept {
+		req.Header.Add("Accept", mediaType)
+	}
+
+	logrus.WithField("url", url).Debug("Doing a GET request to the registry")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("registry responded to request with %q", res.Status)
+	}
+
+	return io.ReadAll(io.LimitReader(res.Body, maxFetchSize))
+}
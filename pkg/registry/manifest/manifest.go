@@ -43,3 +43,20 @@ func BuildManifestURL(container types.Container) (string, error) {
 	}
 	return url.String(), nil
 }
+
+// BuildRepositoryURL from raw image data, returning the base URL of the registry API endpoints for the image repository
+func BuildRepositoryURL(container types.Container) (string, error) {
+	normalizedRef, err := ref.ParseNormalizedNamed(container.ImageName())
+	if err != nil {
+		return "", err
+	}
+
+	host, _ := helpers.GetRegistryAddress(normalizedRef.Name())
+
+	url := url2.URL{
+		Scheme: "https",
+		Host:   host,
+		Path:   fmt.Sprintf("/v2/%s", ref.Path(normalizedRef)),
+	}
+	return url.String(), nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/containrrr/watchtower/internal/actions/mocks"
 	"github.com/containrrr/watchtower/pkg/registry/manifest"
+	"github.com/containrrr/watchtower/pkg/types"
 	apiTypes "github.com/docker/docker/api/types"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -57,9 +58,25 @@ var _ = Describe("the manifest module", func() {
 			Expect(URL).To(BeEmpty())
 		})
 	})
+	Describe("BuildRepositoryURL", func() {
+		It("should return the repository url without a tag", func() {
+			URL, err := manifest.BuildRepositoryURL(createMockContainer("ghcr.io/containrrr/watchtower:mytag"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(URL).To(Equal("https://ghcr.io/v2/containrrr/watchtower"))
+		})
+		It("should prepend library/ for single-part image names on Docker Hub", func() {
+			URL, err := manifest.BuildRepositoryURL(createMockContainer("nginx:latest"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(URL).To(Equal("https://index.docker.io/v2/library/nginx"))
+		})
+	})
 })
 
 func buildMockContainerManifestURL(imageRef string) (string, error) {
+	return manifest.BuildManifestURL(createMockContainer(imageRef))
+}
+
+func createMockContainer(imageRef string) types.Container {
 	imageInfo := apiTypes.ImageInspect{
 		RepoTags: []string{
 			imageRef,
@@ -68,7 +85,5 @@ func buildMockContainerManifestURL(imageRef string) (string, error) {
 	mockID := "mock-id"
 	mockName := "mock-container"
 	mockCreated := time.Now()
-	mock := mocks.CreateMockContainerWithImageInfo(mockID, mockName, imageRef, mockCreated, imageInfo)
-
-	return manifest.BuildManifestURL(mock)
+	return mocks.CreateMockContainerWithImageInfo(mockID, mockName, imageRef, mockCreated, imageInfo)
 }
@@ -0,0 +1,95 @@
+// Package metadata contains code for reading the metadata of images stored in the image registry
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/containrrr/watchtower/pkg/registry/auth"
+	"github.com/containrrr/watchtower/pkg/registry/digest"
+	"github.com/containrrr/watchtower/pkg/registry/manifest"
+	"github.com/containrrr/watchtower/pkg/types"
+	"github.com/sirupsen/logrus"
+)
+
+// imageConfig is the subset of the image config blob that contains the image labels
+type imageConfig struct {
+	Config struct {
+		Labels map[string]string `json:"Labels"`
+	} `json:"config"`
+}
+
+// GetLabels fetches the config blob of the image that the container references from the registry and returns
+// the labels that it contains. If the image reference points to an index, the manifest matching platform is used.
+func GetLabels(container types.Container, registryAuth string, platform manifest.Platform) (map[string]string, error) {
+	repoURL, err := manifest.BuildRepositoryURL(container)
+	if err != nil {
+		return nil, err
+	}
+
+	manifestURL, err := manifest.BuildManifestURL(container)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := auth.GetToken(container, digest.TransformAuth(registryAuth))
+	if err != nil {
+		return nil, err
+	}
+
+	client := digest.NewClient()
+
+	imageManifest, err := manifest.GetManifest(client, manifestURL, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if imageManifest.IsIndex() {
+		desc, err := SelectPlatform(imageManifest.Manifests, platform)
+		if err != nil {
+			return nil, err
+		}
+		logrus.WithFields(logrus.Fields{
+			"image":  container.ImageName(),
+			"digest": desc.Digest,
+		}).Debug("Using platform manifest from image index")
+		if imageManifest, err = manifest.GetManifest(client, repoURL+"/manifests/"+desc.Digest, token); err != nil {
+			return nil, err
+		}
+	}
+
+	if imageManifest.Config.Digest == "" {
+		return nil, fmt.Errorf("manifest for %s does not reference an image config", container.ImageName())
+	}
+
+	blob, err := manifest.GetBlob(client, repoURL, imageManifest.Config.Digest, token)
+	if err != nil {
+		return nil, err
+	}
+
+	config := imageConfig{}
+	if err := json.Unmarshal(blob, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse image config: %w", err)
+	}
+	return config.Config.Labels, nil
+}
+
+// SelectPlatform returns the descriptor from an image index that best matches the supplied platform
+func SelectPlatform(manifests []manifest.Descriptor, platform manifest.Platform) (manifest.Descriptor, error) {
+	var fallback *manifest.Descriptor
+	for i, desc := range manifests {
+		if desc.Platform == nil || desc.Platform.OS != platform.OS || desc.Platform.Architecture != platform.Architecture {
+			continue
+		}
+		if desc.Platform.Variant == platform.Variant {
+			return desc, nil
+		}
+		if fallback == nil {
+			fallback = &manifests[i]
+		}
+	}
+	if fallback != nil {
+		return *fallback, nil
+	}
+	return manifest.Descriptor{}, fmt.Errorf("no manifest found for platform %s/%s", platform.OS, platform.Architecture)
+}
@@ -0,0 +1,41 @@
+package metadata_test
+
+import (
+	"testing"
+
+	"github.com/containrrr/watchtower/pkg/registry/manifest"
+	"github.com/containrrr/watchtower/pkg/registry/metadata"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestMetadata(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Metadata Suite")
+}
+
+var _ = Describe("the image metadata", func() {
+	Describe("SelectPlatform", func() {
+		manifests := []manifest.Descriptor{
+			{Digest: "sha256:amd64", Platform: &manifest.Platform{OS: "linux", Architecture: "amd64"}},
+			{Digest: "sha256:armv6", Platform: &manifest.Platform{OS: "linux", Architecture: "arm", Variant: "v6"}},
+			{Digest: "sha256:armv7", Platform: &manifest.Platform{OS: "linux", Architecture: "arm", Variant: "v7"}},
+			{Digest: "sha256:attestation"},
+		}
+
+		It("should return the manifest matching the platform", func() {
+			desc, err := metadata.SelectPlatform(manifests, manifest.Platform{OS: "linux", Architecture: "arm", Variant: "v7"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(desc.Digest).To(Equal("sha256:armv7"))
+		})
+		It("should fall back to the first manifest with a matching architecture", func() {
+			desc, err := metadata.SelectPlatform(manifests, manifest.Platform{OS: "linux", Architecture: "arm"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(desc.Digest).To(Equal("sha256:armv6"))
+		})
+		It("should return an error when no manifest matches the platform", func() {
+			_, err := metadata.SelectPlatform(manifests, manifest.Platform{OS: "windows", Architecture: "amd64"})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,254 @@
+// Package signature contains code for verifying cosign-style image signatures stored in the image registry
+package signature
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/distribution/reference"
+
+	"github.com/containrrr/watchtower/pkg/registry/auth"
+	"github.com/containrrr/watchtower/pkg/registry/digest"
+	"github.com/containrrr/watchtower/pkg/registry/manifest"
+	"github.com/containrrr/watchtower/pkg/types"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// AnnotationKey is the layer annotation containing the base64 encoded signature
+	AnnotationKey = "dev.cosignproject.cosign/signature"
+	// PayloadMediaType is the media type of the layers containing the signed payload
+	PayloadMediaType = "application/vnd.dev.cosign.simplesigning.v1+json"
+	// ArtifactType is the artifact type used for signatures in the referrers API
+	ArtifactType = "application/vnd.dev.cosign.artifact.sig.v1+json"
+	// PayloadType is the type of the simple signing payloads of container image signatures
+	PayloadType = "cosign container image signature"
+)
+
+var (
+	// ErrNoSignature is returned when no signature could be found for the image digest
+	ErrNoSignature = errors.New("no signature found for image")
+	// ErrInvalidSignature is returned when none of the signatures could be verified using the public keys
+	ErrInvalidSignature = errors.New("no valid signature found for image")
+	// ErrNoPublicKeys is returned when signature verification is requested without any public keys
+	ErrNoPublicKeys = errors.New("no public keys configured for signature verification")
+)
+
+// Payload is the simple signing payload that is signed by cosign
+type Payload struct {
+	Critical struct {
+		Identity struct {
+			DockerReference string `json:"docker-reference"`
+		} `json:"identity"`
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+}
+
+// LoadPublicKeys reads PEM encoded public keys from the supplied files
+func LoadPublicKeys(paths []string) ([]crypto.PublicKey, error) {
+	keys := make([]crypto.PublicKey, 0, len(paths))
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read public key: %w", err)
+		}
+		parsed, err := ParsePublicKeys(content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse public key %q: %w", path, err)
+		}
+		keys = append(keys, parsed...)
+	}
+	return keys, nil
+}
+
+// ParsePublicKeys parses all the PEM encoded public keys contained in data
+func ParsePublicKeys(data []byte) ([]crypto.PublicKey, error) {
+	var keys []crypto.PublicKey
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "PUBLIC KEY" {
+			continue
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no PEM encoded public keys found")
+	}
+	return keys, nil
+}
+
+// Verify checks that the image digest has a valid signature for the image name made by one of the supplied keys. The
+// signatures are looked up in the registry of the container image using the cosign tag scheme (sha256-<digest>.sig),
+// falling back to the OCI referrers API. The image name differs from the container image when it is pulled from a
+// registry mirror.
+func Verify(container types.Container, imageName string, imageDigest string, registryAuth string, keys []crypto.PublicKey) error {
+	if len(keys) == 0 {
+		return ErrNoPublicKeys
+	}
+
+	repoURL, err := manifest.BuildRepositoryURL(container)
+	if err != nil {
+		return err
+	}
+
+	token, err := auth.GetToken(container, digest.TransformAuth(registryAuth))
+	if err != nil {
+		return err
+	}
+
+	fields := logrus.Fields{"image": container.ImageName(), "digest": imageDigest}
+	client := digest.NewClient()
+
+	manifests, err := getSignatureManifests(client, repoURL, imageDigest, token)
+	if err != nil {
+		return err
+	}
+	if len(manifests) == 0 {
+		return ErrNoSignature
+	}
+
+	for _, sigManifest := range manifests {
+		for _, layer := range sigManifest.Layers {
+			if layer.MediaType != PayloadMediaType {
+				continue
+			}
+			encodedSig, found := layer.Annotations[AnnotationKey]
+			if !found {
+				continue
+			}
+			sig, err := base64.StdEncoding.DecodeString(encodedSig)
+			if err != nil {
+				logrus.WithFields(fields).Debugf("Ignoring malformed signature: %v", err)
+				continue
+			}
+			payload, err := manifest.GetBlob(client, repoURL, layer.Digest, token)
+			if err != nil {
+				return err
+			}
+			if err := VerifyPayload(payload, sig, imageName, imageDigest, keys); err != nil {
+				logrus.WithFields(fields).Debugf("Ignoring signature: %v", err)
+				continue
+			}
+			logrus.WithFields(fields).Debug("Found a valid image signature")
+			return nil
+		}
+	}
+
+	return ErrInvalidSignature
+}
+
+// VerifyPayload checks that payload is a container image signature for the repository of the image name and the image
+// digest, and that sig is a valid signature of it made by one of the supplied keys
+func VerifyPayload(payload []byte, sig []byte, imageName string, imageDigest string, keys []crypto.PublicKey) error {
+	signed := Payload{}
+	if err := json.Unmarshal(payload, &signed); err != nil {
+		return fmt.Errorf("failed to parse signature payload: %w", err)
+	}
+	if signed.Critical.Type != PayloadType {
+		return fmt.Errorf("signature payload has type %q", signed.Critical.Type)
+	}
+	if signed.Critical.Image.DockerManifestDigest != imageDigest {
+		return fmt.Errorf("signature payload is for digest %q", signed.Critical.Image.DockerManifestDigest)
+	}
+	repository, err := normalizedRepository(imageName)
+	if err != nil {
+		return err
+	}
+	signedReference := signed.Critical.Identity.DockerReference
+	if signedRepository, err := normalizedRepository(signedReference); err != nil || signedRepository != repository {
+		return fmt.Errorf("signature payload is for image %q", signedReference)
+	}
+
+	hash := sha256.Sum256(payload)
+	for _, key := range keys {
+		switch pub := key.(type) {
+		case *ecdsa.PublicKey:
+			if ecdsa.VerifyASN1(pub, hash[:], sig) {
+				return nil
+			}
+		case *rsa.PublicKey:
+			if rsa.VerifyPKCS1v15(pub, crypto.SHA256, hash[:], sig) == nil {
+				return nil
+			}
+			if rsa.VerifyPSS(pub, crypto.SHA256, hash[:], sig, nil) == nil {
+				return nil
+			}
+		case ed25519.PublicKey:
+			if ed25519.Verify(pub, payload, sig) {
+				return nil
+			}
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// normalizedRepository returns the fully qualified repository of the image, without the tag or digest
+func normalizedRepository(image string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", err
+	}
+	return reference.TrimNamed(named).String(), nil
+}
+
+// SignatureTag returns the tag that cosign uses for storing the signatures of the image digest
+func SignatureTag(imageDigest string) string {
+	return strings.Replace(imageDigest, ":", "-", 1) + ".sig"
+}
+
+func getSignatureManifests(client *http.Client, repoURL string, imageDigest string, token string) ([]manifest.Manifest, error) {
+	tagManifest, err := manifest.GetManifest(client, repoURL+"/manifests/"+SignatureTag(imageDigest), token)
+	if err == nil {
+		return []manifest.Manifest{*tagManifest}, nil
+	}
+	if !errors.Is(err, manifest.ErrNotFound) {
+		return nil, err
+	}
+
+	logrus.WithField("digest", imageDigest).Debug("No signature tag found, trying the referrers API")
+	index, err := manifest.GetManifest(client, repoURL+"/referrers/"+imageDigest+"?artifactType="+ArtifactType, token)
+	if errors.Is(err, manifest.ErrNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	manifests := make([]manifest.Manifest, 0, len(index.Manifests))
+	for _, desc := range index.Manifests {
+		if desc.ArtifactType != "" && desc.ArtifactType != ArtifactType {
+			continue
+		}
+		referrer, err := manifest.GetManifest(client, repoURL+"/manifests/"+desc.Digest, token)
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, *referrer)
+	}
+	return manifests, nil
+}
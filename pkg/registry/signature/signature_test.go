@@ -0,0 +1,122 @@
+package signature_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"testing"
+
+	"github.com/containrrr/watchtower/pkg/registry/signature"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestSignature(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Signature Suite")
+}
+
+const (
+	mockDigest    = "sha256:d68e1e532088964195ad3a0a71526bc2f11a78de0def85629beb75e2265f0547"
+	mockImageName = "ghcr.io/containrrr/watchtower:latest"
+)
+
+func mockPayload(digest string) []byte {
+	return mockPayloadFor("ghcr.io/containrrr/watchtower", digest, signature.PayloadType)
+}
+
+func mockPayloadFor(dockerReference string, digest string, payloadType string) []byte {
+	return []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":%q},`+
+		`"image":{"docker-manifest-digest":%q},"type":%q},"optional":null}`, dockerReference, digest, payloadType))
+}
+
+func signPayload(key *ecdsa.PrivateKey, payload []byte) []byte {
+	hash := sha256.Sum256(payload)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, hash[:])
+	Expect(err).NotTo(HaveOccurred())
+	return sig
+}
+
+var _ = Describe("the signature verification", func() {
+	var key *ecdsa.PrivateKey
+	var otherKey *ecdsa.PrivateKey
+
+	BeforeEach(func() {
+		var err error
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+		otherKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Describe("VerifyPayload", func() {
+		It("should accept a payload signed by one of the keys", func() {
+			payload := mockPayload(mockDigest)
+			sig := signPayload(key, payload)
+			keys := []crypto.PublicKey{otherKey.Public(), key.Public()}
+			Expect(signature.VerifyPayload(payload, sig, mockImageName, mockDigest, keys)).To(Succeed())
+		})
+		It("should reject a payload signed by an unknown key", func() {
+			payload := mockPayload(mockDigest)
+			sig := signPayload(otherKey, payload)
+			keys := []crypto.PublicKey{key.Public()}
+			Expect(signature.VerifyPayload(payload, sig, mockImageName, mockDigest, keys)).To(MatchError(signature.ErrInvalidSignature))
+		})
+		It("should reject a payload for another digest", func() {
+			payload := mockPayload("sha256:0000000000000000000000000000000000000000000000000000000000000000")
+			sig := signPayload(key, payload)
+			keys := []crypto.PublicKey{key.Public()}
+			Expect(signature.VerifyPayload(payload, sig, mockImageName, mockDigest, keys)).NotTo(Succeed())
+		})
+		It("should reject a payload for another repository", func() {
+			payload := mockPayloadFor("ghcr.io/containrrr/other", mockDigest, signature.PayloadType)
+			sig := signPayload(key, payload)
+			keys := []crypto.PublicKey{key.Public()}
+			Expect(signature.VerifyPayload(payload, sig, mockImageName, mockDigest, keys)).
+				To(MatchError(ContainSubstring("ghcr.io/containrrr/other")))
+		})
+		It("should reject a payload of another type", func() {
+			payload := mockPayloadFor("ghcr.io/containrrr/watchtower", mockDigest, "cosign attestation")
+			sig := signPayload(key, payload)
+			keys := []crypto.PublicKey{key.Public()}
+			Expect(signature.VerifyPayload(payload, sig, mockImageName, mockDigest, keys)).
+				To(MatchError(ContainSubstring("cosign attestation")))
+		})
+		It("should compare the normalized repositories", func() {
+			payload := mockPayloadFor("index.docker.io/library/nginx", mockDigest, signature.PayloadType)
+			sig := signPayload(key, payload)
+			keys := []crypto.PublicKey{key.Public()}
+			Expect(signature.VerifyPayload(payload, sig, "nginx:1.25", mockDigest, keys)).To(Succeed())
+		})
+	})
+
+	Describe("ParsePublicKeys", func() {
+		It("should parse all the PEM encoded public keys", func() {
+			var data []byte
+			for _, k := range []*ecdsa.PrivateKey{key, otherKey} {
+				der, err := x509.MarshalPKIXPublicKey(k.Public())
+				Expect(err).NotTo(HaveOccurred())
+				data = append(data, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})...)
+			}
+			keys, err := signature.ParsePublicKeys(data)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(keys).To(HaveLen(2))
+		})
+		It("should return an error when no keys are found", func() {
+			_, err := signature.ParsePublicKeys([]byte("not a key"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("SignatureTag", func() {
+		It("should return the cosign tag for the digest", func() {
+			Expect(signature.SignatureTag(mockDigest)).To(Equal(
+				"sha256-d68e1e532088964195ad3a0a71526bc2f11a78de0def85629beb75e2265f0547.sig"))
+		})
+	})
+})
@@ -15,6 +15,8 @@ const (
 	FailedState
 	FreshState
 	StaleState
+	RejectedState
+	UndeterminedState
 )
 
 // ContainerStatus contains the container state during a session
@@ -22,8 +24,14 @@ type ContainerStatus struct {
 	containerID   wt.ContainerID
 	oldImage      wt.ImageID
 	newImage      wt.ImageID
+	latestDigest  string
+	oldMetadata   wt.ImageMetadata
+	newMetadata   wt.ImageMetadata
+	pullStats     wt.PullStats
 	containerName string
 	imageName     string
+	labels        map[string]string
+	logs          string
 	error
 	state State
 }
@@ -48,11 +56,42 @@ func (u *ContainerStatus) LatestImageID() wt.ImageID {
 	return u.newImage
 }
 
+// LatestDigest returns the digest of the newest image reported by the registry, when the container was checked
+// without pulling the image
+func (u *ContainerStatus) LatestDigest() string {
+	return u.latestDigest
+}
+
+// CurrentMetadata returns the metadata of the image that the container used when the session started
+func (u *ContainerStatus) CurrentMetadata() wt.ImageMetadata {
+	return u.oldMetadata
+}
+
+// LatestMetadata returns the metadata of the newest image found during the session
+func (u *ContainerStatus) LatestMetadata() wt.ImageMetadata {
+	return u.newMetadata
+}
+
+// PullStats returns the statistics of the image pull done for the container during the session
+func (u *ContainerStatus) PullStats() wt.PullStats {
+	return u.pullStats
+}
+
 // ImageName returns the name:tag that the container uses
 func (u *ContainerStatus) ImageName() string {
 	return u.imageName
 }
 
+// Labels returns the labels of the container
+func (u *ContainerStatus) Labels() map[string]string {
+	return u.labels
+}
+
+// Logs returns the tail of the logs of the container, if it failed to start
+func (u *ContainerStatus) Logs() string {
+	return u.logs
+}
+
 // Error returns the error (if any) that was encountered for the container during a session
 func (u *ContainerStatus) Error() string {
 	if u.error == nil {
@@ -63,7 +102,12 @@ func (u *ContainerStatus) Error() string {
 
 // State returns the current State that the container is in
 func (u *ContainerStatus) State() string {
-	switch u.state {
+	return u.state.String()
+}
+
+// String returns the name of the State, as used in the reports
+func (s State) String() string {
+	switch s {
 	case SkippedState:
 		return "Skipped"
 	case ScannedState:
@@ -76,6 +120,10 @@ func (u *ContainerStatus) State() string {
 		return "Fresh"
 	case StaleState:
 		return "Stale"
+	case RejectedState:
+		return "Rejected"
+	case UndeterminedState:
+		return "Unknown"
 	default:
 		return "Unknown"
 	}
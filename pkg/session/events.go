@@ -0,0 +1,141 @@
+package session
+
+import (
+	"sync"
+	"time"
+
+	"github.com/containrrr/watchtower/pkg/types"
+)
+
+// EventType is the kind of change that an Event describes
+type EventType string
+
+// The types of the events published during a session
+const (
+	SessionStartedEvent    EventType = "session-started"
+	ContainerCheckedEvent  EventType = "container-checked"
+	PullStartedEvent       EventType = "pull-started"
+	PullFinishedEvent      EventType = "pull-finished"
+	ContainerStoppingEvent EventType = "container-stopping"
+	ContainerCreatedEvent  EventType = "container-created"
+	ContainerStartedEvent  EventType = "container-started"
+	HookOutputEvent        EventType = "hook-output"
+	ContainerFailedEvent   EventType = "container-failed"
+	SessionFinishedEvent   EventType = "session-finished"
+)
+
+// eventBuffer is the number of events that are buffered for each subscriber, before events are dropped for it
+const eventBuffer = 64
+
+// Event is a structured description of a change during a session
+type Event struct {
+	Type        EventType              `json:"type"`
+	Time        time.Time              `json:"time"`
+	Container   string                 `json:"container,omitempty"`
+	ContainerID types.ContainerID      `json:"containerId,omitempty"`
+	Image       string                 `json:"image,omitempty"`
+	Error       string                 `json:"error,omitempty"`
+	Data        map[string]interface{} `json:"data,omitempty"`
+	// Report is the report of a finished session, used to count the containers visible to each subscriber
+	Report types.Report `json:"-"`
+}
+
+// ContainerEvent creates an event of the given type for the container
+func ContainerEvent(eventType EventType, c types.Container) Event {
+	return Event{
+		Type:        eventType,
+		Container:   c.Name(),
+		ContainerID: c.ID(),
+		Image:       c.ImageName(),
+	}
+}
+
+// WithError returns the event including the error, if it is not nil
+func (e Event) WithError(err error) Event {
+	if err != nil {
+		e.Error = err.Error()
+	}
+	return e
+}
+
+// ReportCounts returns the number of containers in each state of the report, only counting the containers that
+// are included by the filter. All containers are counted if the filter is nil.
+func ReportCounts(report types.Report, include func(types.ContainerReport) bool) map[string]interface{} {
+	count := func(containers []types.ContainerReport) int {
+		if include == nil {
+			return len(containers)
+		}
+		n := 0
+		for _, c := range containers {
+			if include(c) {
+				n++
+			}
+		}
+		return n
+	}
+	return map[string]interface{}{
+		"scanned":  count(report.Scanned()),
+		"updated":  count(report.Updated()),
+		"failed":   count(report.Failed()),
+		"skipped":  count(report.Skipped()),
+		"stale":    count(report.Stale()),
+		"fresh":    count(report.Fresh()),
+		"rejected": count(report.Rejected()),
+		"unknown":  count(report.Unknown()),
+	}
+}
+
+// EventBus distributes the published events to all of its subscribers
+type EventBus struct {
+	mutex       sync.Mutex
+	subscribers map[chan Event]bool
+}
+
+var events = &EventBus{subscribers: make(map[chan Event]bool)}
+
+// Events returns the event bus that the session events are published on
+func Events() *EventBus {
+	return events
+}
+
+// PublishEvent publishes the event on the session event bus
+func PublishEvent(event Event) {
+	events.Publish(event)
+}
+
+// Publish sends the event to all the subscribers. The event is dropped for subscribers that are not keeping up, as
+// publishing must never hold up the session.
+func (bus *EventBus) Publish(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+
+	for subscriber := range bus.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel receiving the published events, and a function that ends the subscription
+func (bus *EventBus) Subscribe() (<-chan Event, func()) {
+	subscriber := make(chan Event, eventBuffer)
+
+	bus.mutex.Lock()
+	bus.subscribers[subscriber] = true
+	bus.mutex.Unlock()
+
+	var once sync.Once
+	return subscriber, func() {
+		once.Do(func() {
+			bus.mutex.Lock()
+			delete(bus.subscribers, subscriber)
+			bus.mutex.Unlock()
+			close(subscriber)
+		})
+	}
+}
@@ -9,7 +9,7 @@ type Progress map[types.ContainerID]*ContainerStatus
 
 // UpdateFromContainer sets various status fields from their corresponding container equivalents
 func UpdateFromContainer(cont types.Container, newImage types.ImageID, state State) *ContainerStatus {
-	return &ContainerStatus{
+	status := &ContainerStatus{
 		containerID:   cont.ID(),
 		containerName: cont.Name(),
 		imageName:     cont.ImageName(),
@@ -17,6 +17,13 @@ func UpdateFromContainer(cont types.Container, newImage types.ImageID, state Sta
 		newImage:      newImage,
 		state:         state,
 	}
+	if imageInfo := cont.ImageInfo(); imageInfo != nil && imageInfo.Config != nil {
+		status.oldMetadata = types.NewImageMetadata(imageInfo.Config.Labels)
+	}
+	if containerInfo := cont.ContainerInfo(); containerInfo != nil && containerInfo.Config != nil {
+		status.labels = containerInfo.Config.Labels
+	}
+	return status
 }
 
 // AddSkipped adds a container to the Progress with the state set as skipped
@@ -31,6 +38,51 @@ func (m Progress) AddScanned(cont types.Container, newImage types.ImageID) {
 	m.Add(UpdateFromContainer(cont, newImage, ScannedState))
 }
 
+// AddRejected adds a container to the Progress with the state set as rejected, meaning that the latest image
+// was found but not accepted for updating the container
+func (m Progress) AddRejected(cont types.Container, newImage types.ImageID, err error) {
+	update := UpdateFromContainer(cont, newImage, RejectedState)
+	update.error = err
+	m.Add(update)
+}
+
+// SetLatestMetadata sets the metadata of the newest image found for the container identified by containerID
+func (m Progress) SetLatestMetadata(containerID types.ContainerID, metadata types.ImageMetadata) {
+	if update, found := m[containerID]; found {
+		update.newMetadata = metadata
+	}
+}
+
+// AddUnknown adds a container to the Progress with the state set as undetermined, meaning that it could not
+// be determined whether a newer image is available
+func (m Progress) AddUnknown(cont types.Container, err error) {
+	update := UpdateFromContainer(cont, cont.SafeImageID(), UndeterminedState)
+	update.error = err
+	m.Add(update)
+}
+
+// SetLatestDigest sets the digest of the newest image reported by the registry for the container identified by
+// containerID
+func (m Progress) SetLatestDigest(containerID types.ContainerID, digest string) {
+	if update, found := m[containerID]; found {
+		update.latestDigest = digest
+	}
+}
+
+// SetPullStats sets the statistics of the image pull for the container identified by containerID
+func (m Progress) SetPullStats(containerID types.ContainerID, stats types.PullStats) {
+	if update, found := m[containerID]; found {
+		update.pullStats = stats
+	}
+}
+
+// SetLogs sets the tail of the logs for the container identified by containerID
+func (m Progress) SetLogs(containerID types.ContainerID, logs string) {
+	if update, found := m[containerID]; found {
+		update.logs = logs
+	}
+}
+
 // UpdateFailed updates the containers passed, setting their state as failed with the supplied error
 func (m Progress) UpdateFailed(failures map[types.ContainerID]error) {
 	for id, err := range failures {
@@ -47,6 +99,9 @@ func (m Progress) Add(update *ContainerStatus) {
 
 // MarkForUpdate marks the container identified by containerID for update
 func (m Progress) MarkForUpdate(containerID types.ContainerID) {
+	if m[containerID].state == RejectedState {
+		return
+	}
 	m[containerID].state = UpdatedState
 }
 
@@ -7,12 +7,14 @@ import (
 )
 
 type report struct {
-	scanned []types.ContainerReport
-	updated []types.ContainerReport
-	failed  []types.ContainerReport
-	skipped []types.ContainerReport
-	stale   []types.ContainerReport
-	fresh   []types.ContainerReport
+	scanned  []types.ContainerReport
+	updated  []types.ContainerReport
+	failed   []types.ContainerReport
+	skipped  []types.ContainerReport
+	stale    []types.ContainerReport
+	fresh    []types.ContainerReport
+	rejected []types.ContainerReport
+	unknown  []types.ContainerReport
 }
 
 func (r *report) Scanned() []types.ContainerReport {
@@ -33,8 +35,15 @@ func (r *report) Stale() []types.ContainerReport {
 func (r *report) Fresh() []types.ContainerReport {
 	return r.fresh
 }
+func (r *report) Rejected() []types.ContainerReport {
+	return r.rejected
+}
+func (r *report) Unknown() []types.ContainerReport {
+	return r.unknown
+}
 func (r *report) All() []types.ContainerReport {
-	allLen := len(r.scanned) + len(r.updated) + len(r.failed) + len(r.skipped) + len(r.stale) + len(r.fresh)
+	allLen := len(r.scanned) + len(r.updated) + len(r.failed) + len(r.skipped) + len(r.stale) + len(r.fresh) +
+		len(r.rejected) + len(r.unknown)
 	all := make([]types.ContainerReport, 0, allLen)
 
 	presentIds := map[types.ContainerID][]string{}
@@ -51,7 +60,9 @@ func (r *report) All() []types.ContainerReport {
 
 	appendUnique(r.updated)
 	appendUnique(r.failed)
+	appendUnique(r.rejected)
 	appendUnique(r.skipped)
+	appendUnique(r.unknown)
 	appendUnique(r.stale)
 	appendUnique(r.fresh)
 	appendUnique(r.scanned)
@@ -64,12 +75,14 @@ func (r *report) All() []types.ContainerReport {
 // NewReport creates a types.Report from the supplied Progress
 func NewReport(progress Progress) types.Report {
 	report := &report{
-		scanned: []types.ContainerReport{},
-		updated: []types.ContainerReport{},
-		failed:  []types.ContainerReport{},
-		skipped: []types.ContainerReport{},
-		stale:   []types.ContainerReport{},
-		fresh:   []types.ContainerReport{},
+		scanned:  []types.ContainerReport{},
+		updated:  []types.ContainerReport{},
+		failed:   []types.ContainerReport{},
+		skipped:  []types.ContainerReport{},
+		stale:    []types.ContainerReport{},
+		fresh:    []types.ContainerReport{},
+		rejected: []types.ContainerReport{},
+		unknown:  []types.ContainerReport{},
 	}
 
 	for _, update := range progress {
@@ -79,7 +92,12 @@ func NewReport(progress Progress) types.Report {
 		}
 
 		report.scanned = append(report.scanned, update)
-		if update.newImage == update.oldImage {
+		if update.state == UndeterminedState {
+			report.unknown = append(report.unknown, update)
+			continue
+		}
+
+		if update.newImage == update.oldImage && update.latestDigest == "" {
 			update.state = FreshState
 			report.fresh = append(report.fresh, update)
 			continue
@@ -90,6 +108,8 @@ func NewReport(progress Progress) types.Report {
 			report.updated = append(report.updated, update)
 		case FailedState:
 			report.failed = append(report.failed, update)
+		case RejectedState:
+			report.rejected = append(report.rejected, update)
 		default:
 			update.state = StaleState
 			report.stale = append(report.stale, update)
@@ -102,10 +122,36 @@ func NewReport(progress Progress) types.Report {
 	sort.Sort(sortableContainers(report.skipped))
 	sort.Sort(sortableContainers(report.stale))
 	sort.Sort(sortableContainers(report.fresh))
+	sort.Sort(sortableContainers(report.rejected))
+	sort.Sort(sortableContainers(report.unknown))
 
 	return report
 }
 
+// FilterReport creates a types.Report containing only the containers from the supplied report that match filter
+func FilterReport(source types.Report, filter func(types.ContainerReport) bool) types.Report {
+	filtered := func(reports []types.ContainerReport) []types.ContainerReport {
+		matching := []types.ContainerReport{}
+		for _, cr := range reports {
+			if filter(cr) {
+				matching = append(matching, cr)
+			}
+		}
+		return matching
+	}
+
+	return &report{
+		scanned:  filtered(source.Scanned()),
+		updated:  filtered(source.Updated()),
+		failed:   filtered(source.Failed()),
+		skipped:  filtered(source.Skipped()),
+		stale:    filtered(source.Stale()),
+		fresh:    filtered(source.Fresh()),
+		rejected: filtered(source.Rejected()),
+		unknown:  filtered(source.Unknown()),
+	}
+}
+
 type sortableContainers []types.ContainerReport
 
 // Len implements sort.Interface.Len
@@ -0,0 +1,9 @@
+package types
+
+// CommandOutput is the outcome of a command that was executed in a container
+type CommandOutput struct {
+	// Output is the combined output of the command, trimmed of surrounding whitespace
+	Output string
+	// ExitCode is the exit code of the command
+	ExitCode int
+}
@@ -68,6 +68,8 @@ type Container interface {
 	SetStale(bool)
 	IsStale() bool
 	IsNoPull(UpdateParams) bool
+	IsVerifySignature(UpdateParams) bool
+	GetSignatureKeys() []string
 	SetLinkedToRestarting(bool)
 	IsLinkedToRestarting() bool
 	PreUpdateTimeout() int
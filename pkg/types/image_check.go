@@ -0,0 +1,13 @@
+package types
+
+// ImageCheck is the result of checking whether a newer image is available for a container
+type ImageCheck struct {
+	// Stale is true if a newer image is available for the container
+	Stale bool
+	// LatestImage is the ID of the newest local image for the container
+	LatestImage ImageID
+	// LatestDigest is the digest of the newer image reported by the registry when checking without pulling
+	LatestDigest string
+	// Pull contains the statistics of the image pull, and is empty if no image was pulled
+	Pull PullStats
+}
@@ -0,0 +1,31 @@
+package types
+
+// OCI image annotation keys used for the image metadata
+const (
+	ImageVersionLabel  = "org.opencontainers.image.version"
+	ImageRevisionLabel = "org.opencontainers.image.revision"
+	ImageSourceLabel   = "org.opencontainers.image.source"
+)
+
+// ImageMetadata contains the descriptive metadata of an image, as read from its OCI labels
+type ImageMetadata struct {
+	Version  string
+	Revision string
+	Source   string
+	Labels   map[string]string
+}
+
+// NewImageMetadata creates ImageMetadata from the supplied image labels
+func NewImageMetadata(labels map[string]string) ImageMetadata {
+	return ImageMetadata{
+		Version:  labels[ImageVersionLabel],
+		Revision: labels[ImageRevisionLabel],
+		Source:   labels[ImageSourceLabel],
+		Labels:   labels,
+	}
+}
+
+// IsEmpty returns whether none of the well-known metadata fields are set
+func (m ImageMetadata) IsEmpty() bool {
+	return m.Version == "" && m.Revision == "" && m.Source == ""
+}
@@ -0,0 +1,16 @@
+package types
+
+import "time"
+
+// PullStats contains statistics about an image pull
+type PullStats struct {
+	// Bytes is the total size of the layers that were downloaded
+	Bytes int64
+	// Duration is the time it took to pull the image
+	Duration time.Duration
+}
+
+// IsEmpty returns whether the stats are unset, meaning that no image was pulled
+func (s PullStats) IsEmpty() bool {
+	return s.Bytes == 0 && s.Duration == 0
+}
@@ -8,6 +8,8 @@ type Report interface {
 	Skipped() []ContainerReport
 	Stale() []ContainerReport
 	Fresh() []ContainerReport
+	Rejected() []ContainerReport
+	Unknown() []ContainerReport
 	All() []ContainerReport
 }
 
@@ -17,7 +19,13 @@ type ContainerReport interface {
 	Name() string
 	CurrentImageID() ImageID
 	LatestImageID() ImageID
+	LatestDigest() string
+	CurrentMetadata() ImageMetadata
+	LatestMetadata() ImageMetadata
+	PullStats() PullStats
 	ImageName() string
+	Labels() map[string]string
+	Logs() string
 	Error() string
 	State() string
 }
@@ -1,18 +1,25 @@
 package types
 
 import (
+	"crypto"
 	"time"
 )
 
 // UpdateParams contains all different options available to alter the behavior of the Update func
 type UpdateParams struct {
-	Filter          Filter
-	Cleanup         bool
-	NoRestart       bool
-	Timeout         time.Duration
-	MonitorOnly     bool
-	NoPull			bool
-	LifecycleHooks  bool
-	RollingRestart  bool
-	LabelPrecedence bool
+	Filter           Filter
+	Cleanup          bool
+	NoRestart        bool
+	Timeout          time.Duration
+	MonitorOnly      bool
+	CheckOnly        bool
+	NoPull           bool
+	LifecycleHooks   bool
+	RollingRestart   bool
+	LabelPrecedence  bool
+	VerifySignatures bool
+	SignatureKeys    []crypto.PublicKey
+	FailureLogLines  int
+	ExitCheckDelay   time.Duration
+	ReviveStopped    bool
 }
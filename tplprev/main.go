@@ -6,6 +6,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/containrrr/watchtower/internal/meta"
 	"github.com/containrrr/watchtower/pkg/notifications/preview"
@@ -17,12 +18,22 @@ func main() {
 
 	var states string
 	var entries string
+	var fixtures string
 
-	flag.StringVar(&states, "states", "cccuuueeekkktttfff", "sCanned, Updated, failEd, sKipped, sTale, Fresh")
+	flag.StringVar(&states, "states", "cccuuueeekkktttfff", "sCanned, Updated, failEd, sKipped, sTale, Fresh, Rejected, uNknown")
 	flag.StringVar(&entries, "entries", "ewwiiidddd", "Fatal,Error,Warn,Info,Debug,Trace")
+	flag.StringVar(&fixtures, "fixtures", "", "Write the template function fixtures to this directory instead of rendering a template")
 
 	flag.Parse()
 
+	if fixtures != "" {
+		if err := writeFixtures(fixtures); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write fixtures: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	if len(flag.Args()) < 1 {
 		fmt.Fprintln(os.Stderr, "Missing required argument TEMPLATE")
 		flag.Usage()
@@ -47,3 +58,21 @@ func main() {
 
 	fmt.Println(result)
 }
+
+func writeFixtures(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, name := range preview.FuncNames() {
+		result, err := preview.RenderFixture(name)
+		if err != nil {
+			return fmt.Errorf("%v: %v", name, err)
+		}
+		path := filepath.Join(dir, name+".txt")
+		if err := os.WriteFile(path, []byte(result), 0o644); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %v\n", path)
+	}
+	return nil
+}